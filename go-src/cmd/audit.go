@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tackeyy/slamy/internal/output"
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+	"github.com/tackeyy/slamy/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+// auditSource identifies who is performing writes in this process: the CLI,
+// or the MCP server and the client that connected to it.
+var auditSource = struct {
+	sync.Mutex
+	origin string
+	client string
+}{origin: state.OriginCLI}
+
+// setAuditSource records the origin and client name for later writes.
+func setAuditSource(origin, client string) {
+	auditSource.Lock()
+	defer auditSource.Unlock()
+	auditSource.origin = origin
+	auditSource.client = client
+}
+
+// auditFullText reports whether the audit log keeps message text rather
+// than only its hash.
+func auditFullText() bool {
+	v, _ := strconv.ParseBool(os.Getenv("SLAMY_AUDIT_FULL_TEXT"))
+	return v
+}
+
+// recordWrite appends e to the audit log, with the hash of text (or text
+// itself with SLAMY_AUDIT_FULL_TEXT) when text is set. The write has already
+// happened, so a failure is reported on stderr rather than returned.
+func recordWrite(e state.AuditEntry, text string) {
+	auditSource.Lock()
+	e.Origin, e.Client = auditSource.origin, auditSource.client
+	auditSource.Unlock()
+	if text != "" {
+		e.SetText(text, auditFullText())
+	}
+
+	auditLog, err := state.OpenAuditLog()
+	if err == nil {
+		_, err = auditLog.Append(e)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to write audit log: %v\n", err)
+	}
+}
+
+// recordPost audits the messages PostText posted, if any.
+func recordPost(channelID, text string, opts slackutil.PostOptions, result *slackutil.PostResult) {
+	if result == nil || (result.TS == "" && result.FileID == "") {
+		return
+	}
+	action := state.AuditPost
+	if opts.ThreadTS != "" {
+		action = state.AuditReply
+	}
+	recordWrite(state.AuditEntry{
+		Action:     action,
+		Channel:    channelID,
+		TS:         result.TS,
+		ThreadTS:   result.ThreadTS,
+		Timestamps: result.Timestamps,
+		FileID:     result.FileID,
+	}, text)
+}
+
+// auditEntriesView renders audit log entries.
+func auditEntriesView(entries []state.AuditEntry) output.View {
+	return output.View{
+		Data:    entries,
+		Columns: []string{"seq", "time", "origin", "client", "action", "channel", "ts", "reaction"},
+		Text: func(w io.Writer) error {
+			if len(entries) == 0 {
+				fmt.Fprintln(w, "Audit log is empty")
+				return nil
+			}
+			for _, e := range entries {
+				origin := e.Origin
+				if e.Client != "" {
+					origin += "/" + e.Client
+				}
+				target := e.TS
+				if e.FileID != "" {
+					target = "file " + e.FileID
+				}
+				if e.Reaction != "" {
+					target += " :" + e.Reaction + ":"
+				}
+				if e.Name != "" {
+					target += " #" + e.Name
+				}
+				if len(e.Users) > 0 {
+					target += " " + strings.Join(e.Users, ",")
+				}
+				fmt.Fprintf(w, "%5d  %s  %-12s %-12s %s %s\n",
+					e.Seq, e.Time.Local().Format("2006-01-02 15:04:05"), origin, e.Action, e.Channel, target)
+			}
+			return nil
+		},
+	}
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the local log of writes made by slamy",
+}
+
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List audit log entries",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			return fmt.Errorf("failed to get limit flag: %w", err)
+		}
+		channel, err := cmd.Flags().GetString("channel")
+		if err != nil {
+			return fmt.Errorf("failed to get channel flag: %w", err)
+		}
+
+		auditLog, err := state.OpenAuditLog()
+		if err != nil {
+			return err
+		}
+		entries, err := auditLog.Entries()
+		if err != nil {
+			return err
+		}
+
+		out := []state.AuditEntry{}
+		for _, e := range entries {
+			if channel == "" || e.Channel == channel {
+				out = append(out, e)
+			}
+		}
+		if limit > 0 && len(out) > limit {
+			out = out[len(out)-limit:]
+		}
+
+		return render(auditEntriesView(out))
+	},
+}
+
+var auditShowCmd = &cobra.Command{
+	Use:   "show <seq>",
+	Short: "Show an audit log entry",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		seq, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid sequence number %q", args[0])
+		}
+
+		auditLog, err := state.OpenAuditLog()
+		if err != nil {
+			return err
+		}
+		e, err := auditLog.Get(seq)
+		if err != nil {
+			return err
+		}
+
+		return render(output.View{
+			Data:    e,
+			Columns: []string{"seq", "time", "origin", "client", "action", "channel", "ts", "thread_ts", "reaction", "text_sha256"},
+			Text: func(w io.Writer) error {
+				fmt.Fprintf(w, "Seq:       %d\n", e.Seq)
+				fmt.Fprintf(w, "Time:      %s\n", e.Time.Local().Format("2006-01-02 15:04:05"))
+				fmt.Fprintf(w, "Origin:    %s\n", e.Origin)
+				if e.Client != "" {
+					fmt.Fprintf(w, "Client:    %s\n", e.Client)
+				}
+				fmt.Fprintf(w, "Action:    %s\n", e.Action)
+				fmt.Fprintf(w, "Channel:   %s\n", e.Channel)
+				if e.TS != "" {
+					fmt.Fprintf(w, "TS:        %s\n", e.TS)
+				}
+				if e.ThreadTS != "" {
+					fmt.Fprintf(w, "Thread:    %s\n", e.ThreadTS)
+				}
+				if len(e.Timestamps) > 0 {
+					fmt.Fprintf(w, "Messages:  %s\n", postedTimestamps(&slackutil.PostResult{Timestamps: e.Timestamps}))
+				}
+				if e.FileID != "" {
+					fmt.Fprintf(w, "File:      %s\n", e.FileID)
+				}
+				if e.Reaction != "" {
+					fmt.Fprintf(w, "Reaction:  :%s:\n", e.Reaction)
+				}
+				if e.Name != "" {
+					fmt.Fprintf(w, "Name:      #%s\n", e.Name)
+				}
+				if len(e.Users) > 0 {
+					fmt.Fprintf(w, "Users:     %s\n", strings.Join(e.Users, ", "))
+				}
+				if e.TextSHA256 != "" {
+					fmt.Fprintf(w, "SHA-256:   %s\n", e.TextSHA256)
+				}
+				fmt.Fprintf(w, "Hash:      %s\n", e.Hash)
+				if e.Text != "" {
+					fmt.Fprintf(w, "\n%s\n", e.Text)
+				}
+				return nil
+			},
+		})
+	},
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the audit log for altered or removed entries",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		auditLog, err := state.OpenAuditLog()
+		if err != nil {
+			return err
+		}
+		n, err := auditLog.Verify()
+		if err != nil {
+			return fmt.Errorf("audit log verification failed after %d good entries: %w", n, err)
+		}
+
+		return render(output.View{
+			Data:    map[string]any{"entries": n, "ok": true},
+			Columns: []string{"entries", "ok"},
+			Text: func(w io.Writer) error {
+				_, err := fmt.Fprintf(w, "Audit log OK (%d entries)\n", n)
+				return err
+			},
+		})
+	},
+}
+
+func init() {
+	auditListCmd.Flags().Int("limit", 50, "Show only the most recent entries (0 for all)")
+	auditListCmd.Flags().String("channel", "", "Only show writes to this channel")
+
+	auditCmd.AddCommand(auditListCmd)
+	auditCmd.AddCommand(auditShowCmd)
+	auditCmd.AddCommand(auditVerifyCmd)
+	rootCmd.AddCommand(auditCmd)
+}
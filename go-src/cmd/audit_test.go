@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	slackapi "github.com/slack-go/slack"
+
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+	"github.com/tackeyy/slamy/internal/state"
+)
+
+func auditEntries(t *testing.T) []state.AuditEntry {
+	t.Helper()
+	auditLog, err := state.OpenAuditLog()
+	if err != nil {
+		t.Fatalf("OpenAuditLog: %v", err)
+	}
+	entries, err := auditLog.Entries()
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	return entries
+}
+
+func TestAudit_PostRecorded(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	setAuditSource(state.OriginMCP, "test-client 1.0")
+	defer setAuditSource(state.OriginCLI, "")
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			return channelID, "1675382400.000000", nil
+		},
+	})
+	defer cleanup()
+
+	req := makeRequest(map[string]any{"channel_id": "C001", "text": "hello"})
+	if _, err := handlePostMessage(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := auditEntries(t)
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	e := entries[0]
+	if e.Action != state.AuditPost || e.Origin != state.OriginMCP || e.Client != "test-client 1.0" ||
+		e.Channel != "C001" || e.TS != "1675382400.000000" || e.TextSHA256 == "" || e.Text != "" {
+		t.Errorf("entry = %+v", e)
+	}
+}
+
+func TestAudit_ReplyWithFullText(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	t.Setenv("SLAMY_AUDIT_FULL_TEXT", "1")
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			return channelID, "1675382500.000000", nil
+		},
+	})
+	defer cleanup()
+
+	req := makeRequest(map[string]any{"channel_id": "C001", "thread_ts": "1675382400.000000", "text": "**done**"})
+	if _, err := handleReplyToThread(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := auditEntries(t)
+	if len(entries) != 1 || entries[0].Action != state.AuditReply || entries[0].ThreadTS != "1675382400.000000" || entries[0].Text != "**done**" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestAudit_PartialPostRecorded(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	calls := 0
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			calls++
+			if calls > 1 {
+				return "", "", errors.New("rate_limited")
+			}
+			return channelID, "1675382400.000000", nil
+		},
+	})
+	defer cleanup()
+
+	req := makeRequest(map[string]any{"channel_id": "C001", "text": strings.Repeat("a", 3000) + "\n\n" + strings.Repeat("b", 3000)})
+	if _, err := handlePostMessage(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := auditEntries(t)
+	if len(entries) != 1 || entries[0].TS != "1675382400.000000" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestAudit_FailedPostNotRecorded(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			return "", "", errors.New("channel_not_found")
+		},
+	})
+	defer cleanup()
+
+	req := makeRequest(map[string]any{"channel_id": "C001", "text": "hello"})
+	if _, err := handlePostMessage(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	if entries := auditEntries(t); len(entries) != 0 {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestAudit_ReactionRecorded(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		AddReactionFunc: func(name string, ref slackapi.ItemRef) error { return nil },
+	})
+	defer cleanup()
+
+	req := makeRequest(map[string]any{"channel_id": "C001", "timestamp": "1.0", "reaction": "eyes"})
+	if _, err := handleAddReaction(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := auditEntries(t)
+	if len(entries) != 1 || entries[0].Action != state.AuditReactionAdd || entries[0].Reaction != "eyes" || entries[0].TS != "1.0" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
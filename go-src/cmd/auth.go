@@ -1,10 +1,10 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
+	"io"
 
+	"github.com/tackeyy/slamy/internal/output"
 	slackutil "github.com/tackeyy/slamy/internal/slack"
 
 	"github.com/spf13/cobra"
@@ -29,29 +29,30 @@ var authTestCmd = &cobra.Command{
 			return fmt.Errorf("auth test failed: %w", err)
 		}
 
-		if outputJSON {
-			out := map[string]string{
-				"user_id": resp.UserID,
-				"user":    resp.User,
-				"team_id": resp.TeamID,
-				"team":    resp.Team,
-				"url":     resp.URL,
-			}
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(out)
+		out := struct {
+			UserID string `json:"user_id"`
+			User   string `json:"user"`
+			TeamID string `json:"team_id"`
+			Team   string `json:"team"`
+			URL    string `json:"url"`
+		}{
+			UserID: resp.UserID,
+			User:   resp.User,
+			TeamID: resp.TeamID,
+			Team:   resp.Team,
+			URL:    resp.URL,
 		}
 
-		if outputPlain {
-			fmt.Printf("%s\t%s\t%s\t%s\n", resp.UserID, resp.User, resp.TeamID, resp.Team)
-			return nil
-		}
-
-		fmt.Printf("Authenticated as: %s (%s)\n", resp.User, resp.UserID)
-		fmt.Printf("Team: %s (%s)\n", resp.Team, resp.TeamID)
-		fmt.Printf("URL: %s\n", resp.URL)
-
-		return nil
+		return render(output.View{
+			Data:    out,
+			Columns: []string{"user_id", "user", "team_id", "team"},
+			Text: func(w io.Writer) error {
+				fmt.Fprintf(w, "Authenticated as: %s (%s)\n", resp.User, resp.UserID)
+				fmt.Fprintf(w, "Team: %s (%s)\n", resp.Team, resp.TeamID)
+				fmt.Fprintf(w, "URL: %s\n", resp.URL)
+				return nil
+			},
+		})
 	},
 }
 
@@ -1,13 +1,14 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
+	"io"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/tackeyy/slamy/internal/output"
 	slackutil "github.com/tackeyy/slamy/internal/slack"
 
 	"github.com/slack-go/slack"
@@ -28,12 +29,6 @@ var channelsListCmd = &cobra.Command{
 			return err
 		}
 
-		// Get authenticated user's ID to filter to member channels only
-		authResp, err := client.User.AuthTest()
-		if err != nil {
-			return fmt.Errorf("failed to get auth info: %w", err)
-		}
-
 		limit, err := cmd.Flags().GetInt("limit")
 		if err != nil {
 			return fmt.Errorf("failed to get limit flag: %w", err)
@@ -47,133 +42,121 @@ var channelsListCmd = &cobra.Command{
 			return fmt.Errorf("failed to get unread flag: %w", err)
 		}
 
-		params := &slack.GetConversationsForUserParameters{
-			UserID:          authResp.UserID,
-			Types:           []string{"public_channel", "private_channel"},
-			Limit:           limit,
-			ExcludeArchived: !includeArchived,
+		// Get authenticated user's ID to filter to member channels only
+		authResp, err := client.User.AuthTest()
+		if err != nil {
+			return fmt.Errorf("failed to get auth info: %w", err)
 		}
 
-		var allChannels []slack.Channel
-		for {
-			channels, nextCursor, err := client.User.GetConversationsForUser(params)
+		// Detect unread conversations, including direct messages
+		if unreadOnly {
+			allChannels, err := memberChannels(client.User, authResp.UserID, unreadTypes, limit, includeArchived)
 			if err != nil {
-				return fmt.Errorf("failed to list channels: %w", err)
-			}
-			allChannels = append(allChannels, channels...)
-			if nextCursor == "" || (limit > 0 && len(allChannels) >= limit) {
-				break
+				return err
 			}
-			params.Cursor = nextCursor
+			return render(unreadView(detectUnreadChannels(client, authResp.UserID, allChannels)))
 		}
 
-		if limit > 0 && len(allChannels) > limit {
-			allChannels = allChannels[:limit]
+		allChannels, err := memberChannels(client.User, authResp.UserID, channelTypes, limit, includeArchived)
+		if err != nil {
+			return err
 		}
 
-		// Detect unread channels using last_read vs latest message comparison
-		if unreadOnly {
-			unreadChannels := detectUnreadChannels(client, allChannels)
-
-			if outputJSON {
-				type channelOut struct {
-					ID          string `json:"id"`
-					Name        string `json:"name"`
-					Topic       string `json:"topic"`
-					Purpose     string `json:"purpose"`
-					NumMembers  int    `json:"num_members"`
-					IsPrivate   bool   `json:"is_private"`
-					UnreadCount int    `json:"unread_count"`
-				}
-				out := make([]channelOut, len(unreadChannels))
-				for i, ch := range unreadChannels {
-					out[i] = channelOut{
-						ID:          ch.ID,
-						Name:        ch.Name,
-						Topic:       ch.Topic.Value,
-						Purpose:     ch.Purpose.Value,
-						NumMembers:  ch.NumMembers,
-						IsPrivate:   ch.IsPrivate,
-						UnreadCount: ch.UnreadMsgs,
-					}
-				}
-				enc := json.NewEncoder(os.Stdout)
-				enc.SetIndent("", "  ")
-				return enc.Encode(out)
-			}
+		out := slackutil.NewChannels(allChannels)
 
-			if outputPlain {
-				for _, ch := range unreadChannels {
+		return render(output.View{
+			Data:    out,
+			Columns: []string{"id", "name", "num_members", "is_private", "topic"},
+			Text: func(w io.Writer) error {
+				for _, ch := range out {
 					private := ""
 					if ch.IsPrivate {
-						private = "private"
+						private = " (private)"
 					}
-					fmt.Printf("%s\t%s\t%d\t%s\t%d\n", ch.ID, ch.Name, ch.NumMembers, private, ch.UnreadMsgs)
+					fmt.Fprintf(w, "#%-30s %s%s  [%d members]\n", ch.Name, ch.ID, private, ch.NumMembers)
 				}
 				return nil
-			}
+			},
+		})
+	},
+}
 
-			if len(unreadChannels) == 0 {
-				fmt.Println("No unread channels")
-				return nil
-			}
-			for _, ch := range unreadChannels {
-				private := ""
-				if ch.IsPrivate {
-					private = " (private)"
-				}
-				fmt.Printf("#%-30s %s%s  [%d unread]\n", ch.Name, ch.ID, private, ch.UnreadMsgs)
-			}
-			return nil
+// memberChannels pages through the conversations of the given types that
+// userID is a member of, returning at most limit of them (0 for all).
+func memberChannels(api slackutil.SlackAPI, userID string, types []string, limit int, includeArchived bool) ([]slack.Channel, error) {
+	params := &slack.GetConversationsForUserParameters{
+		UserID:          userID,
+		Types:           types,
+		Limit:           limit,
+		ExcludeArchived: !includeArchived,
+	}
+
+	var allChannels []slack.Channel
+	for {
+		channels, nextCursor, err := api.GetConversationsForUser(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list channels: %w", err)
+		}
+		allChannels = append(allChannels, channels...)
+		if nextCursor == "" || (limit > 0 && len(allChannels) >= limit) {
+			break
 		}
+		params.Cursor = nextCursor
+	}
+
+	if limit > 0 && len(allChannels) > limit {
+		allChannels = allChannels[:limit]
+	}
+	return allChannels, nil
+}
+
+// Conversation types searched for unread messages.
+var (
+	channelTypes = []string{"public_channel", "private_channel"}
+	unreadTypes  = []string{"public_channel", "private_channel", "mpim", "im"}
+)
 
-		if outputJSON {
-			type channelOut struct {
-				ID         string `json:"id"`
-				Name       string `json:"name"`
-				Topic      string `json:"topic"`
-				Purpose    string `json:"purpose"`
-				NumMembers int    `json:"num_members"`
-				IsPrivate  bool   `json:"is_private"`
-				IsArchived bool   `json:"is_archived"`
+const (
+	// unreadPageSize is the page size used when counting unread messages.
+	unreadPageSize = 200
+	// unreadScanLimit caps the unread messages read per conversation, so a
+	// never-read channel does not page through its whole history.
+	unreadScanLimit = 1000
+)
+
+// unreadView renders the result of detectUnreadChannels.
+func unreadView(channels []channelWithUnread) output.View {
+	out := make([]slackutil.UnreadChannel, len(channels))
+	for i, ch := range channels {
+		out[i] = ch.view()
+	}
+
+	return output.View{
+		Data:    out,
+		Columns: []string{"id", "name", "user", "unread_count", "mention_count", "error"},
+		Text: func(w io.Writer) error {
+			if len(out) == 0 {
+				fmt.Fprintln(w, "No unread channels")
+				return nil
 			}
-			out := make([]channelOut, len(allChannels))
-			for i, ch := range allChannels {
-				out[i] = channelOut{
-					ID:         ch.ID,
-					Name:       ch.Name,
-					Topic:      ch.Topic.Value,
-					Purpose:    ch.Purpose.Value,
-					NumMembers: ch.NumMembers,
-					IsPrivate:  ch.IsPrivate,
-					IsArchived: ch.IsArchived,
+			for _, ch := range out {
+				if ch.Error != "" {
+					fmt.Fprintf(w, "%-31s %s  [error: %s]\n", ch.Label(), ch.ID, ch.Error)
+					continue
 				}
-			}
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(out)
-		}
-
-		if outputPlain {
-			for _, ch := range allChannels {
 				private := ""
-				if ch.IsPrivate {
-					private = "private"
+				if ch.IsPrivate && !ch.IsIM && !ch.IsMpIM {
+					private = " (private)"
+				}
+				more := ""
+				if ch.More {
+					more = "+"
 				}
-				fmt.Printf("%s\t%s\t%d\t%s\t%s\n", ch.ID, ch.Name, ch.NumMembers, private, ch.Topic.Value)
+				fmt.Fprintf(w, "%-31s %s%s  [%d%s unread, %d mentions]\n", ch.Label(), ch.ID, private, ch.UnreadCount, more, ch.MentionCount)
 			}
 			return nil
-		}
-
-		for _, ch := range allChannels {
-			private := ""
-			if ch.IsPrivate {
-				private = " (private)"
-			}
-			fmt.Printf("#%-30s %s%s  [%d members]\n", ch.Name, ch.ID, private, ch.NumMembers)
-		}
-		return nil
-	},
+		},
+	}
 }
 
 // channelWithUnread holds a channel enriched with unread info.
@@ -181,18 +164,39 @@ type channelWithUnread struct {
 	slack.Channel
 	HasUnread  bool
 	UnreadMsgs int
+	// Mentions counts unread messages that mention the user, @here,
+	// @channel or @everyone, and every unread message in a direct message.
+	Mentions int
+	// More is set when more unread messages remain than were counted.
+	More bool
+	// LatestTS is the ts of the newest message in the channel.
+	LatestTS string
+	// Messages holds the first unreadPageSize unread messages from other
+	// users, newest first.
+	Messages []slack.Message
+	// Err reports why the unread state could not be determined.
+	Err error
 }
 
-// detectUnreadChannels compares last_read (from conversations.info) with
-// the latest message ts (from conversations.history) for each channel.
-func detectUnreadChannels(client *slackutil.Client, channels []slack.Channel) []channelWithUnread {
-	type result struct {
-		err   error
-		ch    channelWithUnread
-		index int
+// view returns the channel's unread state as a view model.
+func (c channelWithUnread) view() slackutil.UnreadChannel {
+	out := slackutil.UnreadChannel{Channel: slackutil.NewChannel(c.Channel), MentionCount: c.Mentions, More: c.More}
+	out.UnreadCount = c.UnreadMsgs
+	if c.Err != nil {
+		out.Error = c.Err.Error()
 	}
+	return out
+}
 
-	results := make([]result, len(channels))
+// detectUnreadChannels reads conversations.info for each channel and, when
+// its latest message is newer than last_read or cannot be told from the
+// info, pages through conversations.history after last_read to count the
+// unread messages and mentions of userID. Slack's own unread_count_display is
+// used as the count where it is available. Channels with unread messages and
+// channels whose state could not be read (with Err set) are returned, in
+// their original order.
+func detectUnreadChannels(client *slackutil.Client, userID string, channels []slack.Channel) []channelWithUnread {
+	results := make([]channelWithUnread, len(channels))
 	var wg sync.WaitGroup
 	sem := make(chan struct{}, 10) // concurrency limit
 
@@ -202,76 +206,124 @@ func detectUnreadChannels(client *slackutil.Client, channels []slack.Channel) []
 			defer wg.Done()
 			sem <- struct{}{}
 			defer func() { <-sem }()
+			results[idx] = channelUnread(client.User, userID, c)
+		}(i, ch)
+	}
+	wg.Wait()
 
-			// Get last_read from conversations.info
-			info, err := client.User.GetConversationInfo(&slack.GetConversationInfoInput{
-				ChannelID: c.ID,
-			})
-			if err != nil {
-				results[idx] = result{index: idx, ch: channelWithUnread{Channel: c}, err: err}
-				return
-			}
-
-			// Skip channels where user is not a member
-			if !info.IsMember {
-				results[idx] = result{index: idx, ch: channelWithUnread{Channel: *info}, err: fmt.Errorf("not a member")}
-				return
-			}
+	var out []channelWithUnread
+	for _, r := range results {
+		if r.HasUnread || r.Err != nil {
+			out = append(out, r)
+		}
+	}
+	return out
+}
 
-			lastRead := info.LastRead
+// channelUnread determines the unread state of one conversation.
+func channelUnread(api slackutil.SlackAPI, userID string, c slack.Channel) channelWithUnread {
+	info, err := api.GetConversationInfo(&slack.GetConversationInfoInput{ChannelID: c.ID})
+	if err != nil {
+		return channelWithUnread{Channel: c, Err: fmt.Errorf("failed to get channel info: %w", err)}
+	}
+	// Direct messages have no is_member flag.
+	if !info.IsMember && !info.IsIM && !info.IsMpIM {
+		return channelWithUnread{Channel: *info}
+	}
+	out := channelWithUnread{Channel: *info}
+	if info.Latest != nil {
+		if slackutil.CompareTS(info.Latest.Timestamp, info.LastRead) <= 0 {
+			return out
+		}
+		out.LatestTS = info.Latest.Timestamp
+	}
 
-			// Get latest message from conversations.history
-			histResp, err := client.User.GetConversationHistory(&slack.GetConversationHistoryParameters{
-				ChannelID: c.ID,
-				Limit:     1,
-			})
-			if err != nil {
-				results[idx] = result{index: idx, ch: channelWithUnread{Channel: *info}, err: err}
-				return
+	params := &slack.GetConversationHistoryParameters{
+		ChannelID: c.ID,
+		Oldest:    info.LastRead,
+		Limit:     unreadPageSize,
+	}
+	scanned := 0
+	for {
+		resp, err := api.GetConversationHistory(params)
+		if err != nil {
+			out.Err = fmt.Errorf("failed to get history: %w", err)
+			return out
+		}
+		for _, msg := range resp.Messages {
+			// Messages are newest first.
+			if slackutil.CompareTS(msg.Timestamp, info.LastRead) <= 0 {
+				continue
 			}
-
-			hasUnread := false
-			unreadCount := 0
-			if len(histResp.Messages) > 0 {
-				latestTs := histResp.Messages[0].Timestamp
-				if latestTs > lastRead {
-					hasUnread = true
-					// Count unread messages by fetching history after last_read
-					countResp, err := client.User.GetConversationHistory(&slack.GetConversationHistoryParameters{
-						ChannelID: c.ID,
-						Oldest:    lastRead,
-						Limit:     100,
-					})
-					if err == nil {
-						unreadCount = len(countResp.Messages)
-					} else {
-						unreadCount = 1 // at least 1
-					}
-				}
+			scanned++
+			if out.LatestTS == "" || slackutil.CompareTS(msg.Timestamp, out.LatestTS) > 0 {
+				out.LatestTS = msg.Timestamp
 			}
-
-			results[idx] = result{
-				index: idx,
-				ch: channelWithUnread{
-					Channel:    *info,
-					HasUnread:  hasUnread,
-					UnreadMsgs: unreadCount,
-				},
+			if userID != "" && msg.User == userID {
+				continue
 			}
-		}(i, ch)
+			out.UnreadMsgs++
+			if len(out.Messages) < unreadPageSize {
+				out.Messages = append(out.Messages, msg)
+			}
+			if info.IsIM || mentions(msg.Text, userID) {
+				out.Mentions++
+			}
+		}
+		if resp.ResponseMetaData.NextCursor == "" {
+			break
+		}
+		if scanned >= unreadScanLimit {
+			out.More = true
+			break
+		}
+		params.Cursor = resp.ResponseMetaData.NextCursor
 	}
-	wg.Wait()
 
-	var out []channelWithUnread
-	for _, r := range results {
-		if r.err != nil {
-			continue
+	if info.UnreadCountDisplay > 0 {
+		out.UnreadMsgs = info.UnreadCountDisplay
+		out.More = false
+	}
+	out.HasUnread = out.UnreadMsgs > 0 || out.More
+	return out
+}
+
+// sortUnread orders channels by priority: direct messages, then channels
+// with mentions, then other channels and finally channels that could not be
+// read. Within each group the most recently active come first.
+func sortUnread(channels []channelWithUnread) {
+	tier := func(c channelWithUnread) int {
+		switch {
+		case c.Err != nil:
+			return 3
+		case c.IsIM || c.IsMpIM:
+			return 0
+		case c.Mentions > 0:
+			return 1
 		}
-		if r.ch.HasUnread {
-			out = append(out, r.ch)
+		return 2
+	}
+	sort.SliceStable(channels, func(i, j int) bool {
+		ti, tj := tier(channels[i]), tier(channels[j])
+		if ti != tj {
+			return ti < tj
 		}
+		return slackutil.CompareTS(channels[i].LatestTS, channels[j].LatestTS) > 0
+	})
+}
+
+// mentions reports whether text mentions userID, @here, @channel or
+// @everyone.
+func mentions(text, userID string) bool {
+	if userID != "" && (strings.Contains(text, "<@"+userID+">") || strings.Contains(text, "<@"+userID+"|")) {
+		return true
 	}
-	return out
+	for _, special := range []string{"<!here", "<!channel", "<!everyone"} {
+		if strings.Contains(text, special) {
+			return true
+		}
+	}
+	return false
 }
 
 var channelsHistoryCmd = &cobra.Command{
@@ -301,47 +353,48 @@ var channelsHistoryCmd = &cobra.Command{
 			return fmt.Errorf("failed to get history: %w", err)
 		}
 
-		if outputJSON {
-			type msgOut struct {
-				Ts         string `json:"ts"`
-				User       string `json:"user"`
-				Text       string `json:"text"`
-				ThreadTs   string `json:"thread_ts,omitempty"`
-				ReplyCount int    `json:"reply_count,omitempty"`
-			}
-			out := make([]msgOut, len(resp.Messages))
-			for i, msg := range resp.Messages {
-				out[i] = msgOut{
-					Ts:         msg.Timestamp,
-					User:       msg.User,
-					Text:       msg.Text,
-					ThreadTs:   msg.ThreadTimestamp,
-					ReplyCount: msg.ReplyCount,
-				}
-			}
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(out)
-		}
+		msgs := slackutil.NewMessages(resp.Messages)
 
-		if outputPlain {
-			for _, msg := range resp.Messages {
-				text := strings.ReplaceAll(msg.Text, "\n", "\\n")
-				fmt.Printf("%s\t%s\t%s\n", msg.Timestamp, msg.User, text)
+		return render(messagesView(msgs))
+	},
+}
+
+// messagesView builds the output view shared by commands that list messages.
+func messagesView(msgs []slackutil.Message) output.View {
+	return output.View{
+		Data:    msgs,
+		Columns: []string{"ts", "user", "text"},
+		Text: func(w io.Writer) error {
+			for _, msg := range msgs {
+				fmt.Fprintln(w, formatMessageLine(msg))
 			}
 			return nil
-		}
+		},
+	}
+}
 
-		for _, msg := range resp.Messages {
-			ts := formatTimestamp(msg.Timestamp)
-			thread := ""
-			if msg.ReplyCount > 0 {
-				thread = fmt.Sprintf(" [%d replies]", msg.ReplyCount)
-			}
-			fmt.Printf("[%s] %s: %s%s\n", ts, msg.User, msg.Text, thread)
-		}
-		return nil
-	},
+// formatMessageLine renders a message view model as a single human-readable
+// line, annotated with subtype, edit marker, files, reactions and replies.
+func formatMessageLine(msg slackutil.Message) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[%s] %s", formatTimestamp(msg.Ts), msg.Author())
+	if msg.Subtype != "" {
+		fmt.Fprintf(&sb, " (%s)", msg.Subtype)
+	}
+	fmt.Fprintf(&sb, ": %s", msg.Text)
+	if msg.Edited != nil {
+		sb.WriteString(" (edited)")
+	}
+	for _, f := range msg.Files {
+		fmt.Fprintf(&sb, " [file: %s]", f.Name)
+	}
+	for _, r := range msg.Reactions {
+		fmt.Fprintf(&sb, " :%s:×%d", r.Name, r.Count)
+	}
+	if msg.ReplyCount > 0 {
+		fmt.Fprintf(&sb, " [%d replies]", msg.ReplyCount)
+	}
+	return sb.String()
 }
 
 func formatTimestamp(ts string) string {
@@ -0,0 +1,440 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tackeyy/slamy/internal/output"
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+	"github.com/tackeyy/slamy/internal/state"
+
+	"github.com/slack-go/slack"
+	"github.com/spf13/cobra"
+)
+
+// Channel management actions, named after their subcommands.
+const (
+	channelCreate     = "create"
+	channelArchive    = "archive"
+	channelUnarchive  = "unarchive"
+	channelRename     = "rename"
+	channelSetTopic   = "set-topic"
+	channelSetPurpose = "set-purpose"
+	channelInvite     = "invite"
+	channelKick       = "kick"
+	channelJoin       = "join"
+	channelLeave      = "leave"
+)
+
+// channelAuditActions maps channel management actions to audit log actions.
+var channelAuditActions = map[string]string{
+	channelCreate:     state.AuditChannelCreate,
+	channelArchive:    state.AuditChannelArchive,
+	channelUnarchive:  state.AuditChannelUnarchive,
+	channelRename:     state.AuditChannelRename,
+	channelSetTopic:   state.AuditChannelTopic,
+	channelSetPurpose: state.AuditChannelPurpose,
+	channelInvite:     state.AuditChannelInvite,
+	channelKick:       state.AuditChannelKick,
+	channelJoin:       state.AuditChannelJoin,
+	channelLeave:      state.AuditChannelLeave,
+}
+
+// channelChange is a channel management write and, once applied, its
+// result.
+type channelChange struct {
+	Action  string `json:"action"`
+	Channel string `json:"channel,omitempty"`
+	// Name is the name of a new channel or the new name of a renamed one.
+	Name    string `json:"name,omitempty"`
+	Private bool   `json:"is_private,omitempty"`
+	// Text is the new topic or purpose.
+	Text  string   `json:"text,omitempty"`
+	Users []string `json:"users,omitempty"`
+	// Warnings reports what the outbound safeguards found in Text.
+	Warnings []string `json:"warnings,omitempty"`
+	DryRun   bool     `json:"dry_run,omitempty"`
+}
+
+// describe returns the change as a verb phrase in the present ("archive
+// C001") and past ("Archived C001") tense.
+func (c channelChange) describe() (string, string) {
+	users := strings.Join(c.Users, ", ")
+	switch c.Action {
+	case channelCreate:
+		kind := "channel"
+		if c.Private {
+			kind = "private channel"
+		}
+		created := "#" + c.Name
+		if c.Channel != "" {
+			created += " (" + c.Channel + ")"
+		}
+		return "create " + kind + " #" + c.Name, "Created " + kind + " " + created
+	case channelArchive:
+		return "archive " + c.Channel, "Archived " + c.Channel
+	case channelUnarchive:
+		return "unarchive " + c.Channel, "Unarchived " + c.Channel
+	case channelRename:
+		return "rename " + c.Channel + " to #" + c.Name, "Renamed " + c.Channel + " to #" + c.Name
+	case channelSetTopic:
+		return "set the topic of " + c.Channel, "Set the topic of " + c.Channel
+	case channelSetPurpose:
+		return "set the purpose of " + c.Channel, "Set the purpose of " + c.Channel
+	case channelInvite:
+		return "invite " + users + " to " + c.Channel, "Invited " + users + " to " + c.Channel
+	case channelKick:
+		return "remove " + users + " from " + c.Channel, "Removed " + users + " from " + c.Channel
+	case channelJoin:
+		return "join " + c.Channel, "Joined " + c.Channel
+	case channelLeave:
+		return "leave " + c.Channel, "Left " + c.Channel
+	}
+	return c.Action + " " + c.Channel, c.Action + " " + c.Channel
+}
+
+// applyChannelChange performs c and records it in the audit log. With dry
+// set it only returns c marked as a dry run. A new topic or purpose passes
+// through the outbound safeguards first.
+func applyChannelChange(api slackutil.SlackAPI, c channelChange, dry bool) (channelChange, error) {
+	if c.Action == channelSetTopic || c.Action == channelSetPurpose {
+		var err error
+		if c.Text, c.Warnings, err = guardText(c.Channel, c.Text); err != nil {
+			return c, err
+		}
+	}
+	if dry {
+		c.DryRun = true
+		return c, nil
+	}
+
+	var err error
+	switch c.Action {
+	case channelCreate:
+		var ch *slack.Channel
+		ch, err = api.CreateConversation(slack.CreateConversationParams{ChannelName: c.Name, IsPrivate: c.Private})
+		if err == nil {
+			c.Channel, c.Name = ch.ID, ch.Name
+		}
+	case channelArchive:
+		err = api.ArchiveConversation(c.Channel)
+	case channelUnarchive:
+		err = api.UnArchiveConversation(c.Channel)
+	case channelRename:
+		_, err = api.RenameConversation(c.Channel, c.Name)
+	case channelSetTopic:
+		_, err = api.SetTopicOfConversation(c.Channel, c.Text)
+	case channelSetPurpose:
+		_, err = api.SetPurposeOfConversation(c.Channel, c.Text)
+	case channelInvite:
+		_, err = api.InviteUsersToConversation(c.Channel, c.Users...)
+	case channelKick:
+		// conversations.kick takes one user; record the ones removed before
+		// a failure.
+		for i, user := range c.Users {
+			if err = api.KickUserFromConversation(c.Channel, user); err != nil {
+				if i > 0 {
+					recordChannelChange(channelChange{Action: c.Action, Channel: c.Channel, Users: c.Users[:i]})
+				}
+				break
+			}
+		}
+	case channelJoin:
+		_, _, _, err = api.JoinConversation(c.Channel)
+	case channelLeave:
+		_, err = api.LeaveConversation(c.Channel)
+	default:
+		return c, fmt.Errorf("unknown channel action %q", c.Action)
+	}
+	if err != nil {
+		present, _ := c.describe()
+		return c, fmt.Errorf("failed to %s: %w", present, err)
+	}
+	recordChannelChange(c)
+	return c, nil
+}
+
+// recordChannelChange adds an applied channel change to the audit log.
+func recordChannelChange(c channelChange) {
+	e := state.AuditEntry{Action: channelAuditActions[c.Action], Channel: c.Channel, Users: c.Users}
+	if c.Action == channelCreate || c.Action == channelRename {
+		e.Name = c.Name
+	}
+	recordWrite(e, c.Text)
+}
+
+// channelChangeView renders the result of a channel management write.
+func channelChangeView(c channelChange) output.View {
+	return output.View{
+		Data:    c,
+		Columns: []string{"action", "channel", "name", "users"},
+		Text: func(w io.Writer) error {
+			present, past := c.describe()
+			if c.DryRun {
+				fmt.Fprintf(w, "Dry run: would %s\n", present)
+			} else {
+				fmt.Fprintln(w, past)
+			}
+			for _, warning := range c.Warnings {
+				fmt.Fprintf(w, "Warning: %s\n", warning)
+			}
+			return nil
+		},
+	}
+}
+
+// channelInfo returns the details of a channel, including its member count.
+func channelInfo(api slackutil.SlackAPI, channelID string) (slackutil.ChannelInfo, error) {
+	ch, err := api.GetConversationInfo(&slack.GetConversationInfoInput{ChannelID: channelID, IncludeNumMembers: true})
+	if err != nil {
+		return slackutil.ChannelInfo{}, fmt.Errorf("failed to get channel info: %w", err)
+	}
+	return slackutil.NewChannelInfo(*ch), nil
+}
+
+// channelMembersPageSize is the page size used for conversations.members.
+const channelMembersPageSize = 200
+
+// channelMembers pages through the members of a channel, returning at most
+// limit of them (0 for all). With resolve set, their names are looked up in
+// the workspace user list.
+func channelMembers(api slackutil.SlackAPI, channelID string, limit int, resolve bool) ([]slackutil.ChannelMember, error) {
+	params := &slack.GetUsersInConversationParameters{ChannelID: channelID, Limit: channelMembersPageSize}
+	var ids []string
+	for {
+		page, cursor, err := api.GetUsersInConversation(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list channel members: %w", err)
+		}
+		ids = append(ids, page...)
+		if cursor == "" || (limit > 0 && len(ids) >= limit) {
+			break
+		}
+		params.Cursor = cursor
+	}
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	users := map[string]slack.User{}
+	if resolve {
+		all, err := api.GetUsers()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
+		for _, u := range all {
+			users[u.ID] = u
+		}
+	}
+
+	members := make([]slackutil.ChannelMember, len(ids))
+	for i, id := range ids {
+		members[i] = slackutil.ChannelMember{ID: id}
+		if u, ok := users[id]; ok {
+			members[i].Name = u.Name
+			members[i].RealName = u.RealName
+			members[i].DisplayName = u.Profile.DisplayName
+		}
+	}
+	return members, nil
+}
+
+var channelsInfoCmd = &cobra.Command{
+	Use:   "info <channel_id>",
+	Short: "Show channel details",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := slackutil.NewClient()
+		if err != nil {
+			return err
+		}
+		info, err := channelInfo(client.User, args[0])
+		if err != nil {
+			return err
+		}
+
+		return render(output.View{
+			Data:    info,
+			Columns: []string{"id", "name", "num_members", "is_private", "is_archived", "is_member", "creator", "created", "topic", "purpose"},
+			Text: func(w io.Writer) error {
+				fmt.Fprintf(w, "Channel:  #%s (%s)\n", info.Name, info.ID)
+				var flags []string
+				if info.IsPrivate {
+					flags = append(flags, "private")
+				}
+				if info.IsArchived {
+					flags = append(flags, "archived")
+				}
+				if info.IsGeneral {
+					flags = append(flags, "general")
+				}
+				if info.IsMember {
+					flags = append(flags, "member")
+				}
+				if len(flags) > 0 {
+					fmt.Fprintf(w, "Flags:    %s\n", strings.Join(flags, ", "))
+				}
+				fmt.Fprintf(w, "Members:  %d\n", info.NumMembers)
+				if info.Creator != "" {
+					fmt.Fprintf(w, "Created:  %s by %s\n", info.Created, info.Creator)
+				}
+				if info.Topic != "" {
+					fmt.Fprintf(w, "Topic:    %s\n", info.Topic)
+				}
+				if info.Purpose != "" {
+					fmt.Fprintf(w, "Purpose:  %s\n", info.Purpose)
+				}
+				return nil
+			},
+		})
+	},
+}
+
+var channelsMembersCmd = &cobra.Command{
+	Use:   "members <channel_id>",
+	Short: "List channel members",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			return fmt.Errorf("failed to get limit flag: %w", err)
+		}
+		resolve, err := cmd.Flags().GetBool("resolve")
+		if err != nil {
+			return fmt.Errorf("failed to get resolve flag: %w", err)
+		}
+
+		client, err := slackutil.NewClient()
+		if err != nil {
+			return err
+		}
+		members, err := channelMembers(client.User, args[0], limit, resolve)
+		if err != nil {
+			return err
+		}
+
+		return render(output.View{
+			Data:    members,
+			Columns: []string{"id", "name", "real_name", "display_name"},
+			Text: func(w io.Writer) error {
+				fmt.Fprintf(w, "%d members:\n", len(members))
+				for _, m := range members {
+					if m.Name == "" {
+						fmt.Fprintf(w, "  %s\n", m.ID)
+						continue
+					}
+					fmt.Fprintf(w, "  %-12s @%-20s %s\n", m.ID, m.Name, m.RealName)
+				}
+				return nil
+			},
+		})
+	},
+}
+
+// channelChangeCmd builds a channel management subcommand. build turns the
+// arguments and flags into the change to apply.
+func channelChangeCmd(use, short string, args cobra.PositionalArgs, build func(cmd *cobra.Command, args []string) (channelChange, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  args,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := build(cmd, args)
+			if err != nil {
+				return err
+			}
+
+			var api slackutil.SlackAPI
+			if !dryRun {
+				client, err := slackutil.NewClient()
+				if err != nil {
+					return err
+				}
+				api = client.User
+			}
+
+			c, err = applyChannelChange(api, c, dryRun)
+			if err != nil {
+				return err
+			}
+			return render(channelChangeView(c))
+		},
+	}
+}
+
+var channelsCreateCmd = channelChangeCmd("create <name>", "Create a channel", cobra.ExactArgs(1),
+	func(cmd *cobra.Command, args []string) (channelChange, error) {
+		private, err := cmd.Flags().GetBool("private")
+		if err != nil {
+			return channelChange{}, fmt.Errorf("failed to get private flag: %w", err)
+		}
+		return channelChange{Action: channelCreate, Name: strings.TrimPrefix(args[0], "#"), Private: private}, nil
+	})
+
+var channelsArchiveCmd = channelChangeCmd("archive <channel_id>", "Archive a channel", cobra.ExactArgs(1),
+	func(cmd *cobra.Command, args []string) (channelChange, error) {
+		return channelChange{Action: channelArchive, Channel: args[0]}, nil
+	})
+
+var channelsUnarchiveCmd = channelChangeCmd("unarchive <channel_id>", "Unarchive a channel", cobra.ExactArgs(1),
+	func(cmd *cobra.Command, args []string) (channelChange, error) {
+		return channelChange{Action: channelUnarchive, Channel: args[0]}, nil
+	})
+
+var channelsRenameCmd = channelChangeCmd("rename <channel_id> <new_name>", "Rename a channel", cobra.ExactArgs(2),
+	func(cmd *cobra.Command, args []string) (channelChange, error) {
+		return channelChange{Action: channelRename, Channel: args[0], Name: strings.TrimPrefix(args[1], "#")}, nil
+	})
+
+var channelsSetTopicCmd = channelChangeCmd("set-topic <channel_id> <topic>", "Set a channel's topic", cobra.ExactArgs(2),
+	func(cmd *cobra.Command, args []string) (channelChange, error) {
+		return channelChange{Action: channelSetTopic, Channel: args[0], Text: args[1]}, nil
+	})
+
+var channelsSetPurposeCmd = channelChangeCmd("set-purpose <channel_id> <purpose>", "Set a channel's purpose", cobra.ExactArgs(2),
+	func(cmd *cobra.Command, args []string) (channelChange, error) {
+		return channelChange{Action: channelSetPurpose, Channel: args[0], Text: args[1]}, nil
+	})
+
+var channelsInviteCmd = channelChangeCmd("invite <channel_id> <user_id>...", "Invite users to a channel", cobra.MinimumNArgs(2),
+	func(cmd *cobra.Command, args []string) (channelChange, error) {
+		return channelChange{Action: channelInvite, Channel: args[0], Users: args[1:]}, nil
+	})
+
+var channelsKickCmd = channelChangeCmd("kick <channel_id> <user_id>...", "Remove users from a channel", cobra.MinimumNArgs(2),
+	func(cmd *cobra.Command, args []string) (channelChange, error) {
+		return channelChange{Action: channelKick, Channel: args[0], Users: args[1:]}, nil
+	})
+
+var channelsJoinCmd = channelChangeCmd("join <channel_id>", "Join a channel", cobra.ExactArgs(1),
+	func(cmd *cobra.Command, args []string) (channelChange, error) {
+		return channelChange{Action: channelJoin, Channel: args[0]}, nil
+	})
+
+var channelsLeaveCmd = channelChangeCmd("leave <channel_id>", "Leave a channel", cobra.ExactArgs(1),
+	func(cmd *cobra.Command, args []string) (channelChange, error) {
+		return channelChange{Action: channelLeave, Channel: args[0]}, nil
+	})
+
+func init() {
+	channelsMembersCmd.Flags().Int("limit", 0, "Maximum number of members to return (0 for all)")
+	channelsMembersCmd.Flags().Bool("resolve", false, "Look up member names")
+
+	channelsCreateCmd.Flags().Bool("private", false, "Create a private channel")
+
+	channelsCmd.AddCommand(
+		channelsInfoCmd,
+		channelsMembersCmd,
+		channelsCreateCmd,
+		channelsArchiveCmd,
+		channelsUnarchiveCmd,
+		channelsRenameCmd,
+		channelsSetTopicCmd,
+		channelsSetPurposeCmd,
+		channelsInviteCmd,
+		channelsKickCmd,
+		channelsJoinCmd,
+		channelsLeaveCmd,
+	)
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+	slackapi "github.com/slack-go/slack"
+
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+	"github.com/tackeyy/slamy/internal/state"
+)
+
+func TestChannelMembers_PagesAndResolves(t *testing.T) {
+	var cursors []string
+	api := &slackutil.MockSlackAPI{
+		GetUsersInConversationFunc: func(params *slackapi.GetUsersInConversationParameters) ([]string, string, error) {
+			cursors = append(cursors, params.Cursor)
+			if params.Cursor == "" {
+				return []string{"U001", "U002"}, "next", nil
+			}
+			return []string{"U003"}, "", nil
+		},
+		GetUsersFunc: func(options ...slackapi.GetUsersOption) ([]slackapi.User, error) {
+			return []slackapi.User{{ID: "U001", Name: "alice", RealName: "Alice", Profile: slackapi.UserProfile{DisplayName: "ali"}}}, nil
+		},
+	}
+
+	members, err := channelMembers(api, "C001", 0, true)
+
+	if err != nil {
+		t.Fatalf("channelMembers: %v", err)
+	}
+	if strings.Join(cursors, ",") != ",next" || len(members) != 3 {
+		t.Fatalf("cursors = %v, members = %+v", cursors, members)
+	}
+	if members[0] != (slackutil.ChannelMember{ID: "U001", Name: "alice", RealName: "Alice", DisplayName: "ali"}) || members[2].Name != "" {
+		t.Errorf("members = %+v", members)
+	}
+}
+
+func TestChannelMembers_Limit(t *testing.T) {
+	calls := 0
+	api := &slackutil.MockSlackAPI{
+		GetUsersInConversationFunc: func(params *slackapi.GetUsersInConversationParameters) ([]string, string, error) {
+			calls++
+			return []string{"U001", "U002"}, "next", nil
+		},
+	}
+
+	members, err := channelMembers(api, "C001", 1, false)
+
+	if err != nil || len(members) != 1 || calls != 1 {
+		t.Errorf("members = %+v, calls = %d, err = %v", members, calls, err)
+	}
+}
+
+func TestApplyChannelChange(t *testing.T) {
+	var calls []string
+	api := &slackutil.MockSlackAPI{
+		CreateConversationFunc: func(params slackapi.CreateConversationParams) (*slackapi.Channel, error) {
+			calls = append(calls, "create "+params.ChannelName)
+			ch := &slackapi.Channel{}
+			ch.ID, ch.Name = "CNEW", params.ChannelName
+			return ch, nil
+		},
+		ArchiveConversationFunc: func(channelID string) error {
+			calls = append(calls, "archive "+channelID)
+			return nil
+		},
+		SetTopicOfConversationFunc: func(channelID, topic string) (*slackapi.Channel, error) {
+			calls = append(calls, "topic "+channelID+" "+topic)
+			return &slackapi.Channel{}, nil
+		},
+		InviteUsersToConversationFunc: func(channelID string, users ...string) (*slackapi.Channel, error) {
+			calls = append(calls, "invite "+channelID+" "+strings.Join(users, ","))
+			return &slackapi.Channel{}, nil
+		},
+	}
+
+	tests := []struct {
+		change channelChange
+		call   string
+		audit  state.AuditEntry
+	}{
+		{channelChange{Action: channelCreate, Name: "launch", Private: true}, "create launch", state.AuditEntry{Action: state.AuditChannelCreate, Channel: "CNEW", Name: "launch"}},
+		{channelChange{Action: channelArchive, Channel: "C001"}, "archive C001", state.AuditEntry{Action: state.AuditChannelArchive, Channel: "C001"}},
+		{channelChange{Action: channelSetTopic, Channel: "C001", Text: "Q3 launch"}, "topic C001 Q3 launch", state.AuditEntry{Action: state.AuditChannelTopic, Channel: "C001"}},
+		{channelChange{Action: channelInvite, Channel: "C001", Users: []string{"U001", "U002"}}, "invite C001 U001,U002", state.AuditEntry{Action: state.AuditChannelInvite, Channel: "C001", Users: []string{"U001", "U002"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.change.Action, func(t *testing.T) {
+			t.Setenv("SLAMY_HOME", t.TempDir())
+			calls = nil
+
+			if _, err := applyChannelChange(api, tt.change, false); err != nil {
+				t.Fatalf("applyChannelChange: %v", err)
+			}
+
+			if len(calls) != 1 || calls[0] != tt.call {
+				t.Errorf("calls = %v", calls)
+			}
+			entries := auditEntries(t)
+			if len(entries) != 1 {
+				t.Fatalf("entries = %+v", entries)
+			}
+			e := entries[0]
+			if e.Action != tt.audit.Action || e.Channel != tt.audit.Channel || e.Name != tt.audit.Name ||
+				strings.Join(e.Users, ",") != strings.Join(tt.audit.Users, ",") {
+				t.Errorf("entry = %+v", e)
+			}
+		})
+	}
+}
+
+func TestApplyChannelChange_DryRun(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+
+	// Any Slack call panics: the mock has no functions set.
+	c, err := applyChannelChange(&slackutil.MockSlackAPI{}, channelChange{Action: channelArchive, Channel: "C001"}, true)
+
+	if err != nil || !c.DryRun {
+		t.Errorf("change = %+v, err = %v", c, err)
+	}
+	if entries := auditEntries(t); len(entries) != 0 {
+		t.Errorf("dry run was recorded: %+v", entries)
+	}
+}
+
+func TestApplyChannelChange_KickRecordsRemovedUsers(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	api := &slackutil.MockSlackAPI{
+		KickUserFromConversationFunc: func(channelID, user string) error {
+			if user == "U002" {
+				return errors.New("cant_kick_self")
+			}
+			return nil
+		},
+	}
+
+	_, err := applyChannelChange(api, channelChange{Action: channelKick, Channel: "C001", Users: []string{"U001", "U002", "U003"}}, false)
+
+	if err == nil || !strings.Contains(err.Error(), "failed to remove U001, U002, U003 from C001: cant_kick_self") {
+		t.Errorf("err = %v", err)
+	}
+	entries := auditEntries(t)
+	if len(entries) != 1 || strings.Join(entries[0].Users, ",") != "U001" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestApplyChannelChange_TopicSafeguard(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+
+	_, err := applyChannelChange(&slackutil.MockSlackAPI{}, channelChange{Action: channelSetTopic, Channel: "C001", Text: leakedToken}, false)
+
+	if err == nil || !strings.Contains(err.Error(), "blocked by safeguards") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestUndo_SkipsChannelChanges(t *testing.T) {
+	seedAuditLog(t,
+		state.AuditEntry{Action: state.AuditPost, Channel: "C001", TS: "1.0"},
+		state.AuditEntry{Action: state.AuditChannelArchive, Channel: "C002"},
+	)
+	var deleted []string
+
+	results, err := undoWrites(deletingAPI(&deleted), 1, nil, false)
+
+	if err != nil || len(results) != 1 || results[0].Seq != 1 || strings.Join(deleted, ",") != "C001:1.0" {
+		t.Errorf("results = %+v, deleted = %v, err = %v", results, deleted, err)
+	}
+}
+
+func TestHandleGetChannelInfo(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		GetConversationInfoFunc: func(input *slackapi.GetConversationInfoInput) (*slackapi.Channel, error) {
+			if !input.IncludeNumMembers {
+				t.Error("expected IncludeNumMembers")
+			}
+			ch := &slackapi.Channel{IsMember: true}
+			ch.ID, ch.Name, ch.NumMembers, ch.Creator = input.ChannelID, "general", 42, "U001"
+			return ch, nil
+		},
+	})
+	defer cleanup()
+
+	result, err := handleGetChannelInfo(context.Background(), makeRequest(map[string]any{"channel_id": "C001"}))
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got slackutil.ChannelInfo
+	if err := json.Unmarshal([]byte(resultText(t, result)), &got); err != nil {
+		t.Fatalf("failed to parse result JSON: %v", err)
+	}
+	if got.ID != "C001" || got.Name != "general" || got.NumMembers != 42 || !got.IsMember || got.Creator != "U001" {
+		t.Errorf("info = %+v", got)
+	}
+}
+
+func TestHandleGetChannelMembers_NeverExposed(t *testing.T) {
+	writeSafeguardConfig(t, `{"inbound": {"never_expose": ["CSECRET"]}}`)
+	cleanup := setMockClient(&slackutil.MockSlackAPI{})
+	defer cleanup()
+
+	result, err := handleGetChannelMembers(context.Background(), makeRequest(map[string]any{"channel_id": "CSECRET"}))
+
+	if err != nil || !isErrorResult(result) {
+		t.Errorf("result = %s, err = %v", resultText(t, result), err)
+	}
+}
+
+func TestChannelTools_InviteAndAnnotations(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	var invited []string
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		InviteUsersToConversationFunc: func(channelID string, users ...string) (*slackapi.Channel, error) {
+			invited = users
+			return &slackapi.Channel{}, nil
+		},
+	})
+	defer cleanup()
+	s := server.NewMCPServer("test", "0")
+	registerChannelTools(s)
+
+	invite := s.GetTool("slack_invite_to_channel")
+	result, err := invite.Handler(context.Background(), makeRequest(map[string]any{"channel_id": "C001", "user_ids": []any{"U001", "U002"}}))
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(invited, ",") != "U001,U002" {
+		t.Errorf("invited = %v", invited)
+	}
+
+	destructive := map[string]bool{
+		"slack_get_channel_info":    false,
+		"slack_get_channel_members": false,
+		"slack_create_channel":      false,
+		"slack_archive_channel":     true,
+		"slack_unarchive_channel":   false,
+		"slack_rename_channel":      true,
+		"slack_set_channel_topic":   true,
+		"slack_set_channel_purpose": true,
+		"slack_invite_to_channel":   false,
+		"slack_kick_from_channel":   true,
+		"slack_join_channel":        false,
+		"slack_leave_channel":       true,
+	}
+	for name, want := range destructive {
+		tool := s.GetTool(name)
+		if tool == nil {
+			t.Errorf("%s not registered", name)
+			continue
+		}
+		if hint := tool.Tool.Annotations.DestructiveHint; hint == nil || *hint != want {
+			t.Errorf("%s: destructiveHint = %v, want %v", name, hint, want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/tackeyy/slamy/internal/output"
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+	"github.com/tackeyy/slamy/internal/state"
+
+	"github.com/slack-go/slack"
+	"github.com/spf13/cobra"
+)
+
+// channelMark is a move of a channel's read marker.
+type channelMark struct {
+	Channel string `json:"channel"`
+	Name    string `json:"name,omitempty"`
+	TS      string `json:"ts"`
+	DryRun  bool   `json:"dry_run,omitempty"`
+	// Error reports why the unread state of the channel could not be read.
+	Error string `json:"error,omitempty"`
+}
+
+// latestTS returns the ts of the newest message in channelID, or "" when the
+// channel has no messages.
+func latestTS(api slackutil.SlackAPI, channelID string) (string, error) {
+	resp, err := api.GetConversationHistory(&slack.GetConversationHistoryParameters{ChannelID: channelID, Limit: 1})
+	if err != nil {
+		return "", fmt.Errorf("failed to get history: %w", err)
+	}
+	if len(resp.Messages) == 0 {
+		return "", nil
+	}
+	return resp.Messages[0].Timestamp, nil
+}
+
+// markChannel moves the read marker of channelID to ts, or to the newest
+// message when ts is "" or "latest". With dry set it only reports the move.
+func markChannel(api slackutil.SlackAPI, channelID, ts string, dry bool) (channelMark, error) {
+	if ts == "" || ts == "latest" {
+		var err error
+		if ts, err = latestTS(api, channelID); err != nil {
+			return channelMark{}, err
+		}
+		if ts == "" {
+			return channelMark{}, fmt.Errorf("channel %s has no messages to mark as read", channelID)
+		}
+	}
+	m := channelMark{Channel: channelID, TS: ts, DryRun: dry}
+	if dry {
+		return m, nil
+	}
+	if err := api.MarkConversation(channelID, ts); err != nil {
+		return m, fmt.Errorf("failed to mark %s as read: %w", channelID, err)
+	}
+	recordWrite(state.AuditEntry{Action: state.AuditChannelMark, Channel: channelID, TS: ts}, "")
+	return m, nil
+}
+
+// markUnreadChannels marks every conversation with unread messages as read
+// up to its newest message. Conversations whose unread state could not be
+// read are returned unmarked with Error set.
+func markUnreadChannels(client *slackutil.Client, dry bool) ([]channelMark, error) {
+	authResp, err := client.User.AuthTest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get auth info: %w", err)
+	}
+	channels, err := memberChannels(client.User, authResp.UserID, unreadTypes, 0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var marks []channelMark
+	for _, ch := range detectUnreadChannels(client, authResp.UserID, channels) {
+		if ch.Err != nil {
+			marks = append(marks, channelMark{Channel: ch.ID, Name: ch.Name, Error: ch.Err.Error()})
+			continue
+		}
+		m, err := markChannel(client.User, ch.ID, ch.LatestTS, dry)
+		if err != nil {
+			return marks, err
+		}
+		m.Name = ch.Name
+		marks = append(marks, m)
+	}
+	return marks, nil
+}
+
+// channelMarksView renders moved read markers.
+func channelMarksView(marks []channelMark) output.View {
+	return output.View{
+		Data:    marks,
+		Columns: []string{"channel", "name", "ts"},
+		Text: func(w io.Writer) error {
+			if len(marks) == 0 {
+				fmt.Fprintln(w, "No unread channels")
+				return nil
+			}
+			for _, m := range marks {
+				channel := m.Channel
+				if m.Name != "" {
+					channel = "#" + m.Name + " (" + m.Channel + ")"
+				}
+				if m.Error != "" {
+					fmt.Fprintf(w, "Skipped %s: %s\n", channel, m.Error)
+				} else if m.DryRun {
+					fmt.Fprintf(w, "Dry run: would mark %s as read up to %s\n", channel, formatTimestamp(m.TS))
+				} else {
+					fmt.Fprintf(w, "Marked %s as read up to %s\n", channel, formatTimestamp(m.TS))
+				}
+			}
+			return nil
+		},
+	}
+}
+
+var channelsMarkCmd = &cobra.Command{
+	Use:   "mark [channel_id]",
+	Short: "Mark a channel as read",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ts, err := cmd.Flags().GetString("ts")
+		if err != nil {
+			return fmt.Errorf("failed to get ts flag: %w", err)
+		}
+		allUnread, err := cmd.Flags().GetBool("all-unread")
+		if err != nil {
+			return fmt.Errorf("failed to get all-unread flag: %w", err)
+		}
+		if allUnread == (len(args) == 1) {
+			return fmt.Errorf("specify either a channel or --all-unread")
+		}
+		if allUnread && cmd.Flags().Changed("ts") {
+			return fmt.Errorf("--ts cannot be used with --all-unread")
+		}
+
+		client, err := slackutil.NewClient()
+		if err != nil {
+			return err
+		}
+
+		if allUnread {
+			marks, err := markUnreadChannels(client, dryRun)
+			if err != nil {
+				return err
+			}
+			return render(channelMarksView(marks))
+		}
+
+		m, err := markChannel(client.User, args[0], ts, dryRun)
+		if err != nil {
+			return err
+		}
+		return render(channelMarksView([]channelMark{m}))
+	},
+}
+
+func init() {
+	channelsMarkCmd.Flags().String("ts", "latest", "Timestamp of the last message to mark as read")
+	channelsMarkCmd.Flags().Bool("all-unread", false, "Mark every channel with unread messages as read")
+
+	channelsCmd.AddCommand(channelsMarkCmd)
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	slackapi "github.com/slack-go/slack"
+
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+	"github.com/tackeyy/slamy/internal/state"
+)
+
+// historyAPI returns a mock whose channels all have messages up to latest
+// and that records conversations.mark calls in marked.
+func historyAPI(latest string, marked *[]string) *slackutil.MockSlackAPI {
+	return &slackutil.MockSlackAPI{
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			return &slackapi.GetConversationHistoryResponse{
+				Messages: []slackapi.Message{{Msg: slackapi.Msg{Timestamp: latest}}},
+			}, nil
+		},
+		MarkConversationFunc: func(channel, ts string) error {
+			*marked = append(*marked, channel+":"+ts)
+			return nil
+		},
+	}
+}
+
+func TestMarkChannel_Latest(t *testing.T) {
+	var marked []string
+
+	m, err := markChannel(historyAPI("1700000000.000200", &marked), "C001", "latest", false)
+
+	if err != nil || m.TS != "1700000000.000200" {
+		t.Fatalf("mark = %+v, err = %v", m, err)
+	}
+	if strings.Join(marked, ",") != "C001:1700000000.000200" {
+		t.Errorf("marked = %v", marked)
+	}
+}
+
+func TestMarkChannel_ExplicitTSAndDryRun(t *testing.T) {
+	var marked []string
+	api := &slackutil.MockSlackAPI{
+		MarkConversationFunc: func(channel, ts string) error {
+			marked = append(marked, channel+":"+ts)
+			return nil
+		},
+	}
+
+	if _, err := markChannel(api, "C001", "1700000000.000100", false); err != nil {
+		t.Fatalf("markChannel: %v", err)
+	}
+	m, err := markChannel(api, "C001", "1700000000.000300", true)
+
+	if err != nil || !m.DryRun {
+		t.Errorf("mark = %+v, err = %v", m, err)
+	}
+	if strings.Join(marked, ",") != "C001:1700000000.000100" {
+		t.Errorf("marked = %v", marked)
+	}
+}
+
+func TestMarkChannel_EmptyChannel(t *testing.T) {
+	api := &slackutil.MockSlackAPI{
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			return &slackapi.GetConversationHistoryResponse{}, nil
+		},
+	}
+
+	_, err := markChannel(api, "C001", "", false)
+
+	if err == nil || !strings.Contains(err.Error(), "no messages") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestMarkUnreadChannels(t *testing.T) {
+	var marked []string
+	api := historyAPI("1700000000.000200", &marked)
+	api.AuthTestFunc = func() (*slackapi.AuthTestResponse, error) {
+		return &slackapi.AuthTestResponse{UserID: "U001"}, nil
+	}
+	api.GetConversationsForUserFunc = func(params *slackapi.GetConversationsForUserParameters) ([]slackapi.Channel, string, error) {
+		return []slackapi.Channel{
+			{GroupConversation: slackapi.GroupConversation{Conversation: slackapi.Conversation{ID: "C001"}}},
+			{GroupConversation: slackapi.GroupConversation{Conversation: slackapi.Conversation{ID: "C002"}}},
+		}, "", nil
+	}
+	api.GetConversationInfoFunc = func(input *slackapi.GetConversationInfoInput) (*slackapi.Channel, error) {
+		lastRead := "1700000000.000100"
+		if input.ChannelID == "C002" {
+			lastRead = "1700000000.000200"
+		}
+		ch := &slackapi.Channel{IsMember: true}
+		ch.ID, ch.Name, ch.LastRead = input.ChannelID, "general", lastRead
+		return ch, nil
+	}
+
+	marks, err := markUnreadChannels(&slackutil.Client{User: api}, false)
+
+	if err != nil {
+		t.Fatalf("markUnreadChannels: %v", err)
+	}
+	if len(marks) != 1 || marks[0].Name != "general" || strings.Join(marked, ",") != "C001:1700000000.000200" {
+		t.Errorf("marks = %+v, marked = %v", marks, marked)
+	}
+}
+
+func TestHandleMarkChannel(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	setAuditSource(state.OriginMCP, "test-client 1.0")
+	defer setAuditSource(state.OriginCLI, "")
+	var marked []string
+	cleanup := setMockClient(historyAPI("1700000000.000200", &marked))
+	defer cleanup()
+
+	result, err := handleMarkChannel(context.Background(), makeRequest(map[string]any{"channel_id": "C001"}))
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(marked, ",") != "C001:1700000000.000200" {
+		t.Errorf("marked = %v", marked)
+	}
+	entries := auditEntries(t)
+	if len(entries) != 1 || entries[0].Action != state.AuditChannelMark || entries[0].TS != "1700000000.000200" || entries[0].Origin != state.OriginMCP {
+		t.Errorf("audit entries = %+v", entries)
+	}
+}
+
+func TestHandleMarkChannel_DryRunAndNeverExposed(t *testing.T) {
+	writeSafeguardConfig(t, `{"inbound": {"never_expose": ["CSECRET"]}}`)
+	var marked []string
+	cleanup := setMockClient(historyAPI("1700000000.000200", &marked))
+	defer cleanup()
+
+	result, err := handleMarkChannel(context.Background(), makeRequest(map[string]any{"channel_id": "C001", "ts": "1700000000.000100", "dry_run": true}))
+	if err != nil || !strings.Contains(resultText(t, result), `"dry_run": true`) {
+		t.Errorf("dry run: result = %s, err = %v", resultText(t, result), err)
+	}
+	result, err = handleMarkChannel(context.Background(), makeRequest(map[string]any{"channel_id": "CSECRET"}))
+	if err != nil || !isErrorResult(result) {
+		t.Errorf("never exposed: result = %s, err = %v", resultText(t, result), err)
+	}
+	if len(marked) != 0 || len(auditEntries(t)) != 0 {
+		t.Errorf("marked = %v", marked)
+	}
+}
+
+func TestHandleGetChannelHistory_MarkRead(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	var marked []string
+	cleanup := setMockClient(historyAPI("1700000000.000200", &marked))
+	defer cleanup()
+
+	result, err := handleGetChannelHistory(context.Background(), makeRequest(map[string]any{"channel_id": "C001", "mark_read": true}))
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(marked, ",") != "C001:1700000000.000200" {
+		t.Errorf("marked = %v", marked)
+	}
+	entries := auditEntries(t)
+	if len(entries) != 1 || entries[0].Action != state.AuditChannelMark {
+		t.Errorf("audit entries = %+v", entries)
+	}
+}
+
+func TestHandleGetChannelHistory_DoesNotMarkByDefault(t *testing.T) {
+	var marked []string
+	cleanup := setMockClient(historyAPI("1700000000.000200", &marked))
+	defer cleanup()
+
+	result, err := handleGetChannelHistory(context.Background(), makeRequest(map[string]any{"channel_id": "C001"}))
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(marked) != 0 {
+		t.Errorf("marked = %v", marked)
+	}
+}
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -127,7 +129,7 @@ func TestDetectUnreadChannels_HasUnread(t *testing.T) {
 	}
 
 	// Act
-	result := detectUnreadChannels(client, channels)
+	result := detectUnreadChannels(client, "UME", channels)
 
 	// Assert
 	if len(result) != 1 {
@@ -171,7 +173,7 @@ func TestDetectUnreadChannels_NoUnread(t *testing.T) {
 	}
 
 	// Act
-	result := detectUnreadChannels(client, channels)
+	result := detectUnreadChannels(client, "UME", channels)
 
 	// Assert
 	if len(result) != 0 {
@@ -207,7 +209,7 @@ func TestDetectUnreadChannels_EmptyHistory(t *testing.T) {
 	}
 
 	// Act
-	result := detectUnreadChannels(client, channels)
+	result := detectUnreadChannels(client, "UME", channels)
 
 	// Assert
 	if len(result) != 0 {
@@ -238,7 +240,7 @@ func TestDetectUnreadChannels_NotMember(t *testing.T) {
 	}
 
 	// Act
-	result := detectUnreadChannels(client, channels)
+	result := detectUnreadChannels(client, "UME", channels)
 
 	// Assert
 	if len(result) != 0 {
@@ -247,7 +249,7 @@ func TestDetectUnreadChannels_NotMember(t *testing.T) {
 }
 
 func TestDetectUnreadChannels_ConversationInfoError(t *testing.T) {
-	// Arrange: GetConversationInfo returns error → channel is skipped
+	// Arrange: GetConversationInfo returns error → channel is reported with the error
 	mock := &slackutil.MockSlackAPI{
 		GetConversationInfoFunc: func(input *slackapi.GetConversationInfoInput) (*slackapi.Channel, error) {
 			return nil, fmt.Errorf("api error")
@@ -261,16 +263,19 @@ func TestDetectUnreadChannels_ConversationInfoError(t *testing.T) {
 	}
 
 	// Act
-	result := detectUnreadChannels(client, channels)
+	result := detectUnreadChannels(client, "UME", channels)
 
 	// Assert
-	if len(result) != 0 {
-		t.Errorf("expected 0 unread channels, got %d", len(result))
+	if len(result) != 1 || result[0].Err == nil || result[0].HasUnread {
+		t.Fatalf("expected 1 channel with an error, got %+v", result)
+	}
+	if !strings.Contains(result[0].Err.Error(), "api error") {
+		t.Errorf("Err = %v", result[0].Err)
 	}
 }
 
 func TestDetectUnreadChannels_HistoryError(t *testing.T) {
-	// Arrange: GetConversationHistory returns error → channel is skipped
+	// Arrange: GetConversationHistory returns error → channel is reported with the error
 	mock := &slackutil.MockSlackAPI{
 		GetConversationInfoFunc: func(input *slackapi.GetConversationInfoInput) (*slackapi.Channel, error) {
 			return &slackapi.Channel{
@@ -295,11 +300,11 @@ func TestDetectUnreadChannels_HistoryError(t *testing.T) {
 	}
 
 	// Act
-	result := detectUnreadChannels(client, channels)
+	result := detectUnreadChannels(client, "UME", channels)
 
 	// Assert
-	if len(result) != 0 {
-		t.Errorf("expected 0 unread channels, got %d", len(result))
+	if len(result) != 1 || result[0].Err == nil || !strings.Contains(result[0].Err.Error(), "history api error") {
+		t.Errorf("expected 1 channel with the history error, got %+v", result)
 	}
 }
 
@@ -347,7 +352,7 @@ func TestDetectUnreadChannels_ConcurrencyNoRace(t *testing.T) {
 	}
 
 	// Act
-	result := detectUnreadChannels(client, channels)
+	result := detectUnreadChannels(client, "UME", channels)
 
 	// Assert
 	if len(result) != 100 {
@@ -358,32 +363,17 @@ func TestDetectUnreadChannels_ConcurrencyNoRace(t *testing.T) {
 	}
 }
 
-func TestDetectUnreadChannels_UnreadCountFetchError(t *testing.T) {
-	// Arrange: unread exists, but count fetch fails → unreadCount=1
-	callNum := 0
+func TestDetectUnreadChannels_SecondPageError(t *testing.T) {
+	// Arrange: the first page of unread messages succeeds, the second fails →
+	// channel is reported with the error rather than a partial count
 	mock := &slackutil.MockSlackAPI{
 		GetConversationInfoFunc: func(input *slackapi.GetConversationInfoInput) (*slackapi.Channel, error) {
-			return &slackapi.Channel{
-				GroupConversation: slackapi.GroupConversation{
-					Conversation: slackapi.Conversation{
-						ID:       input.ChannelID,
-						LastRead: "1675382300.000000",
-					},
-				},
-				IsMember: true,
-			}, nil
+			return unreadInfo(input.ChannelID, "1675382300.000000"), nil
 		},
 		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
-			callNum++
-			if callNum == 1 {
-				// First call: latest message
-				return &slackapi.GetConversationHistoryResponse{
-					Messages: []slackapi.Message{
-						{Msg: slackapi.Msg{Timestamp: "1675382400.000000"}},
-					},
-				}, nil
+			if params.Cursor == "" {
+				return historyPage("next", "1675382400.000000"), nil
 			}
-			// Second call: count request fails
 			return nil, fmt.Errorf("count api error")
 		},
 	}
@@ -395,13 +385,201 @@ func TestDetectUnreadChannels_UnreadCountFetchError(t *testing.T) {
 	}
 
 	// Act
-	result := detectUnreadChannels(client, channels)
+	result := detectUnreadChannels(client, "UME", channels)
 
 	// Assert
-	if len(result) != 1 {
-		t.Fatalf("expected 1 unread channel, got %d", len(result))
+	if len(result) != 1 || result[0].Err == nil {
+		t.Fatalf("expected 1 channel with an error, got %+v", result)
+	}
+}
+
+// unreadInfo returns a conversations.info result for a member channel.
+func unreadInfo(id, lastRead string) *slackapi.Channel {
+	ch := &slackapi.Channel{IsMember: true}
+	ch.ID, ch.Name, ch.LastRead = id, "general", lastRead
+	return ch
+}
+
+// historyPage returns a conversations.history page of messages with the
+// given timestamps, newest first, and next cursor.
+func historyPage(cursor string, ts ...string) *slackapi.GetConversationHistoryResponse {
+	resp := &slackapi.GetConversationHistoryResponse{HasMore: cursor != ""}
+	resp.ResponseMetaData.NextCursor = cursor
+	for _, t := range ts {
+		resp.Messages = append(resp.Messages, slackapi.Message{Msg: slackapi.Msg{Timestamp: t, User: "U001"}})
+	}
+	return resp
+}
+
+func TestDetectUnreadChannels_PaginatesBeyondOnePage(t *testing.T) {
+	// Arrange: 250 unread messages across two pages
+	var page1, page2 []string
+	for i := 0; i < 200; i++ {
+		page1 = append(page1, fmt.Sprintf("1675383%03d.000000", 999-i))
+	}
+	for i := 0; i < 50; i++ {
+		page2 = append(page2, fmt.Sprintf("1675382%03d.000000", 999-i))
+	}
+	mock := &slackutil.MockSlackAPI{
+		GetConversationInfoFunc: func(input *slackapi.GetConversationInfoInput) (*slackapi.Channel, error) {
+			return unreadInfo(input.ChannelID, "1675382000.000000"), nil
+		},
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			if params.Oldest != "1675382000.000000" {
+				t.Errorf("Oldest = %q", params.Oldest)
+			}
+			if params.Cursor == "" {
+				return historyPage("next", page1...), nil
+			}
+			return historyPage("", page2...), nil
+		},
 	}
-	if result[0].UnreadMsgs != 1 {
-		t.Errorf("expected UnreadMsgs=1 (fallback), got %d", result[0].UnreadMsgs)
+	channels := []slackapi.Channel{{GroupConversation: slackapi.GroupConversation{Conversation: slackapi.Conversation{ID: "C001"}}}}
+
+	// Act
+	result := detectUnreadChannels(&slackutil.Client{User: mock}, "UME", channels)
+
+	// Assert
+	if len(result) != 1 || result[0].UnreadMsgs != 250 || result[0].More {
+		t.Fatalf("result = %+v", result)
+	}
+	if result[0].LatestTS != "1675383999.000000" {
+		t.Errorf("LatestTS = %q", result[0].LatestTS)
+	}
+}
+
+func TestDetectUnreadChannels_ScanLimit(t *testing.T) {
+	// Arrange: a channel that never runs out of unread pages
+	var page []string
+	for i := 0; i < unreadPageSize; i++ {
+		page = append(page, "1675382400.000000")
+	}
+	calls := 0
+	mock := &slackutil.MockSlackAPI{
+		GetConversationInfoFunc: func(input *slackapi.GetConversationInfoInput) (*slackapi.Channel, error) {
+			return unreadInfo(input.ChannelID, ""), nil
+		},
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			calls++
+			return historyPage("next", page...), nil
+		},
+	}
+	channels := []slackapi.Channel{{GroupConversation: slackapi.GroupConversation{Conversation: slackapi.Conversation{ID: "C001"}}}}
+
+	// Act
+	result := detectUnreadChannels(&slackutil.Client{User: mock}, "UME", channels)
+
+	// Assert
+	if len(result) != 1 || !result[0].More || result[0].UnreadMsgs != unreadScanLimit {
+		t.Fatalf("result = %+v", result)
+	}
+	if calls != unreadScanLimit/unreadPageSize {
+		t.Errorf("calls = %d", calls)
+	}
+}
+
+func TestDetectUnreadChannels_ComparesTimestampsNumerically(t *testing.T) {
+	// Arrange: "999999999.000000" > "1000000000.000000" as strings
+	mock := &slackutil.MockSlackAPI{
+		GetConversationInfoFunc: func(input *slackapi.GetConversationInfoInput) (*slackapi.Channel, error) {
+			ch := unreadInfo(input.ChannelID, "1000000000.000000")
+			ch.Latest = &slackapi.Message{Msg: slackapi.Msg{Timestamp: "999999999.000000"}}
+			return ch, nil
+		},
+	}
+	channels := []slackapi.Channel{{GroupConversation: slackapi.GroupConversation{Conversation: slackapi.Conversation{ID: "C001"}}}}
+
+	// Act: the mock has no history function, so a history call would panic
+	result := detectUnreadChannels(&slackutil.Client{User: mock}, "UME", channels)
+
+	// Assert
+	if len(result) != 0 {
+		t.Errorf("expected 0 unread channels, got %+v", result)
+	}
+}
+
+func TestDetectUnreadChannels_UnreadCountDisplay(t *testing.T) {
+	// Arrange: Slack reports 7 unread; 2 messages are fetched for mentions
+	mock := &slackutil.MockSlackAPI{
+		GetConversationInfoFunc: func(input *slackapi.GetConversationInfoInput) (*slackapi.Channel, error) {
+			ch := unreadInfo(input.ChannelID, "1675382300.000000")
+			ch.UnreadCountDisplay = 7
+			return ch, nil
+		},
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			resp := historyPage("", "1675382500.000000", "1675382400.000000")
+			resp.Messages[0].Text = "<@UME> can you look?"
+			return resp, nil
+		},
+	}
+	channels := []slackapi.Channel{{GroupConversation: slackapi.GroupConversation{Conversation: slackapi.Conversation{ID: "C001"}}}}
+
+	// Act
+	result := detectUnreadChannels(&slackutil.Client{User: mock}, "UME", channels)
+
+	// Assert
+	if len(result) != 1 || result[0].UnreadMsgs != 7 || result[0].Mentions != 1 {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestDetectUnreadChannels_DirectMessagesAndMentions(t *testing.T) {
+	// Arrange: a DM (no is_member flag), and a channel with own messages,
+	// @here and a mention of someone else
+	mock := &slackutil.MockSlackAPI{
+		GetConversationInfoFunc: func(input *slackapi.GetConversationInfoInput) (*slackapi.Channel, error) {
+			ch := &slackapi.Channel{}
+			ch.ID, ch.LastRead = input.ChannelID, "1675382300.000000"
+			if input.ChannelID == "D001" {
+				ch.IsIM, ch.User = true, "U001"
+			} else {
+				ch.IsMember, ch.Name = true, "general"
+			}
+			return ch, nil
+		},
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			resp := historyPage("", "1675382600.000000", "1675382500.000000", "1675382400.000000")
+			if params.ChannelID == "C001" {
+				resp.Messages[0].User = "UME"
+				resp.Messages[1].Text = "<!here> deploy at 5"
+				resp.Messages[2].Text = "<@U002|bob> thanks"
+			}
+			return resp, nil
+		},
+	}
+	channels := []slackapi.Channel{
+		{GroupConversation: slackapi.GroupConversation{Conversation: slackapi.Conversation{ID: "D001"}}},
+		{GroupConversation: slackapi.GroupConversation{Conversation: slackapi.Conversation{ID: "C001"}}},
+	}
+
+	// Act
+	result := detectUnreadChannels(&slackutil.Client{User: mock}, "UME", channels)
+
+	// Assert
+	if len(result) != 2 {
+		t.Fatalf("expected 2 unread conversations, got %+v", result)
+	}
+	if dm := result[0]; !dm.IsIM || dm.UnreadMsgs != 3 || dm.Mentions != 3 {
+		t.Errorf("dm = %+v", dm)
+	}
+	if ch := result[1]; ch.UnreadMsgs != 2 || ch.Mentions != 1 || ch.LatestTS != "1675382600.000000" {
+		t.Errorf("channel = %+v", ch)
+	}
+}
+
+func TestUnreadView(t *testing.T) {
+	dm := channelWithUnread{HasUnread: true, UnreadMsgs: 2, Mentions: 2}
+	dm.ID, dm.IsIM, dm.User = "D001", true, "U001"
+	failed := channelWithUnread{Err: fmt.Errorf("ratelimited")}
+	failed.ID, failed.Name = "C002", "random"
+
+	var buf bytes.Buffer
+	if err := unreadView([]channelWithUnread{dm, failed}).Text(&buf); err != nil {
+		t.Fatalf("Text: %v", err)
+	}
+
+	got := buf.String()
+	if !strings.Contains(got, "@U001") || !strings.Contains(got, "[2 unread, 2 mentions]") || !strings.Contains(got, "#random") || !strings.Contains(got, "[error: ratelimited]") {
+		t.Errorf("output = %q", got)
 	}
 }
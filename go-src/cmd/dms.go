@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/tackeyy/slamy/internal/output"
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+
+	"github.com/slack-go/slack"
+	"github.com/spf13/cobra"
+)
+
+// dmTypes are the conversation types listed by dms list.
+var dmTypes = []string{"im", "mpim"}
+
+// reConversationID matches a conversation ID, which no user ID does.
+var reConversationID = regexp.MustCompile(`^[CDG][A-Z0-9]{2,}$`)
+
+// listDMs returns at most limit (0 for all) of the user's direct messages
+// and group DMs with the other members' names resolved.
+func listDMs(api slackutil.SlackAPI, limit int) ([]slackutil.DirectMessage, error) {
+	authResp, err := api.AuthTest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get auth info: %w", err)
+	}
+	channels, err := memberChannels(api, authResp.UserID, dmTypes, limit, false)
+	if err != nil {
+		return nil, err
+	}
+	users, err := api.GetUsers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	names := make(map[string]string, len(users))
+	for _, u := range users {
+		names[u.ID] = userLabel(u)
+	}
+
+	out := make([]slackutil.DirectMessage, len(channels))
+	for i, ch := range channels {
+		dm := slackutil.DirectMessage{ID: ch.ID, IsMpIM: ch.IsMpIM, Users: []string{ch.User}}
+		if ch.IsMpIM {
+			members, _, err := api.GetUsersInConversation(&slack.GetUsersInConversationParameters{ChannelID: ch.ID, Limit: channelMembersPageSize})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list members of %s: %w", ch.ID, err)
+			}
+			dm.Users = slices.DeleteFunc(members, func(id string) bool { return id == authResp.UserID })
+		}
+		for _, id := range dm.Users {
+			name := names[id]
+			if name == "" {
+				name = id
+			}
+			dm.Names = append(dm.Names, name)
+		}
+		out[i] = dm
+	}
+	return out, nil
+}
+
+// userLabel returns the display name of u, falling back to the real name and
+// the user name.
+func userLabel(u slack.User) string {
+	switch {
+	case u.Profile.DisplayName != "":
+		return u.Profile.DisplayName
+	case u.RealName != "":
+		return u.RealName
+	}
+	return u.Name
+}
+
+// dmOpened is the result of opening a direct message.
+type dmOpened struct {
+	Channel string   `json:"channel"`
+	Users   []string `json:"users,omitempty"`
+}
+
+// isConversationRef reports whether refs names a conversation by its ID
+// rather than its members.
+func isConversationRef(refs []string) bool {
+	return len(refs) == 1 && reConversationID.MatchString(refs[0])
+}
+
+// resolveDMUsers returns the user IDs refs refer to.
+func resolveDMUsers(api slackutil.SlackAPI, refs []string) ([]string, error) {
+	resolver := slackutil.NewUserResolver(api)
+	ids := make([]string, len(refs))
+	for i, ref := range refs {
+		id, err := resolver.Resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// openDM returns the direct message with the users refs refer to (a group
+// DM for several users), opening it if needed. A single conversation ID is
+// returned as it is.
+func openDM(api slackutil.SlackAPI, refs []string) (dmOpened, error) {
+	if isConversationRef(refs) {
+		return dmOpened{Channel: refs[0]}, nil
+	}
+	ids, err := resolveDMUsers(api, refs)
+	if err != nil {
+		return dmOpened{}, err
+	}
+	ch, _, _, err := api.OpenConversation(&slack.OpenConversationParameters{Users: ids, ReturnIM: true})
+	if err != nil {
+		return dmOpened{}, fmt.Errorf("failed to open conversation with %s: %w", strings.Join(ids, ", "), err)
+	}
+	return dmOpened{Channel: ch.ID, Users: ids}, nil
+}
+
+// findDM is openDM for readers: it looks the direct message up among the
+// user's existing DMs and group DMs and never opens one.
+func findDM(api slackutil.SlackAPI, refs []string) (dmOpened, error) {
+	if isConversationRef(refs) {
+		return dmOpened{Channel: refs[0]}, nil
+	}
+	ids, err := resolveDMUsers(api, refs)
+	if err != nil {
+		return dmOpened{}, err
+	}
+	authResp, err := api.AuthTest()
+	if err != nil {
+		return dmOpened{}, fmt.Errorf("failed to get auth info: %w", err)
+	}
+	others := slices.DeleteFunc(slices.Clone(ids), func(id string) bool { return id == authResp.UserID })
+	slices.Sort(others)
+	others = slices.Compact(others)
+
+	channels, err := memberChannels(api, authResp.UserID, dmTypes, 0, false)
+	if err != nil {
+		return dmOpened{}, err
+	}
+	for _, ch := range channels {
+		if !ch.IsMpIM {
+			if len(others) == 1 && ch.User == others[0] {
+				return dmOpened{Channel: ch.ID, Users: ids}, nil
+			}
+			continue
+		}
+		if len(others) < 2 {
+			continue
+		}
+		members, _, err := api.GetUsersInConversation(&slack.GetUsersInConversationParameters{ChannelID: ch.ID, Limit: channelMembersPageSize})
+		if err != nil {
+			return dmOpened{}, fmt.Errorf("failed to list members of %s: %w", ch.ID, err)
+		}
+		members = slices.DeleteFunc(members, func(id string) bool { return id == authResp.UserID })
+		slices.Sort(members)
+		if slices.Equal(members, others) {
+			return dmOpened{Channel: ch.ID, Users: ids}, nil
+		}
+	}
+	return dmOpened{}, fmt.Errorf("no direct message with %s; open one with dms open", strings.Join(ids, ", "))
+}
+
+// planDM returns the dry-run plan for sending text to the direct message
+// with the users refs refer to. It makes no Slack calls, so unless refs is
+// a conversation ID the plan's channel is left unresolved.
+func planDM(refs []string, text string, opts slackutil.PostOptions) (*slackutil.PostPlan, error) {
+	if isConversationRef(refs) {
+		return planMessage(refs[0], text, opts)
+	}
+	plan, err := planMessage("", text, opts)
+	if err != nil {
+		return nil, err
+	}
+	plan.Warnings = append(plan.Warnings, fmt.Sprintf("channel unresolved: the direct message with %s is opened when the message is sent", strings.Join(refs, ", ")))
+	return plan, nil
+}
+
+var dmsCmd = &cobra.Command{
+	Use:   "dms",
+	Short: "Direct message operations",
+}
+
+var dmsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List direct messages and group DMs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			return fmt.Errorf("failed to get limit flag: %w", err)
+		}
+
+		client, err := slackutil.NewClient()
+		if err != nil {
+			return err
+		}
+		dms, err := listDMs(client.User, limit)
+		if err != nil {
+			return err
+		}
+
+		return render(output.View{
+			Data:    dms,
+			Columns: []string{"id", "is_mpim", "users", "names"},
+			Text: func(w io.Writer) error {
+				for _, dm := range dms {
+					fmt.Fprintf(w, "%-12s @%s\n", dm.ID, strings.Join(dm.Names, ", @"))
+				}
+				return nil
+			},
+		})
+	},
+}
+
+var dmsOpenCmd = &cobra.Command{
+	Use:   "open <user>...",
+	Short: "Open a direct message, or a group DM with several users",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := slackutil.NewClient()
+		if err != nil {
+			return err
+		}
+		dm, err := openDM(client.User, args)
+		if err != nil {
+			return err
+		}
+
+		return render(output.View{
+			Data:    dm,
+			Columns: []string{"channel", "users"},
+			Text: func(w io.Writer) error {
+				fmt.Fprintln(w, dm.Channel)
+				return nil
+			},
+		})
+	},
+}
+
+var dmsHistoryCmd = &cobra.Command{
+	Use:   "history <user>...",
+	Short: "Get direct message history",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			return fmt.Errorf("failed to get limit flag: %w", err)
+		}
+
+		client, err := slackutil.NewClient()
+		if err != nil {
+			return err
+		}
+		dm, err := findDM(client.User, args)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.User.GetConversationHistory(&slack.GetConversationHistoryParameters{ChannelID: dm.Channel, Limit: limit})
+		if err != nil {
+			return fmt.Errorf("failed to get history: %w", err)
+		}
+
+		return render(messagesView(slackutil.NewMessages(resp.Messages)))
+	},
+}
+
+var dmsSendCmd = &cobra.Command{
+	Use:   "send <user>...",
+	Short: "Send a direct message, or a group DM to several users",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := slackutil.NewClient()
+		if err != nil {
+			return err
+		}
+
+		text, err := messageText(cmd, client.User)
+		if err != nil {
+			return err
+		}
+
+		opts, err := postOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			plan, err := planDM(args, text, opts)
+			if err != nil {
+				return err
+			}
+			return render(postPlanView(plan))
+		}
+
+		dm, err := openDM(client.User, args)
+		if err != nil {
+			return err
+		}
+
+		return sendMessage(cmd, client.User, dm.Channel, text, opts)
+	},
+}
+
+func init() {
+	dmsListCmd.Flags().Int("limit", 100, "Maximum number of conversations to return")
+	dmsHistoryCmd.Flags().Int("limit", 20, "Maximum number of messages to return")
+	dmsSendCmd.Flags().String("text", "", "Message text (- reads stdin)")
+	addTextSourceFlags(dmsSendCmd)
+	addPostFlags(dmsSendCmd)
+
+	dmsCmd.AddCommand(dmsListCmd, dmsOpenCmd, dmsHistoryCmd, dmsSendCmd)
+	rootCmd.AddCommand(dmsCmd)
+}
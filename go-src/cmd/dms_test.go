@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	slackapi "github.com/slack-go/slack"
+
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+)
+
+// dmAPI returns a mock that opens D<user IDs> for the given users and
+// records what it opened and posted. The DM with U0BOB (DU0BOB) and the
+// group DM with U0BOB and U0CAROL (G001) already exist.
+func dmAPI(opened, posted *[]string) *slackutil.MockSlackAPI {
+	im := slackapi.Channel{}
+	im.ID, im.IsIM, im.User = "DU0BOB", true, "U0BOB"
+	mpim := slackapi.Channel{}
+	mpim.ID, mpim.IsMpIM = "G001", true
+	return &slackutil.MockSlackAPI{
+		AuthTestFunc: func() (*slackapi.AuthTestResponse, error) {
+			return &slackapi.AuthTestResponse{UserID: "UME"}, nil
+		},
+		GetConversationsForUserFunc: func(params *slackapi.GetConversationsForUserParameters) ([]slackapi.Channel, string, error) {
+			return []slackapi.Channel{mpim, im}, "", nil
+		},
+		GetUsersInConversationFunc: func(params *slackapi.GetUsersInConversationParameters) ([]string, string, error) {
+			return []string{"U0CAROL", "UME", "U0BOB"}, "", nil
+		},
+		GetUsersFunc: func(options ...slackapi.GetUsersOption) ([]slackapi.User, error) {
+			return []slackapi.User{{ID: "U0BOB", Name: "bob"}}, nil
+		},
+		OpenConversationFunc: func(params *slackapi.OpenConversationParameters) (*slackapi.Channel, bool, bool, error) {
+			*opened = append(*opened, strings.Join(params.Users, ","))
+			ch := &slackapi.Channel{}
+			ch.ID = "D" + strings.Join(params.Users, "")
+			return ch, false, false, nil
+		},
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			*posted = append(*posted, channelID)
+			return channelID, "1675382400.000000", nil
+		},
+	}
+}
+
+func TestListDMs(t *testing.T) {
+	im := slackapi.Channel{}
+	im.ID, im.IsIM, im.User = "D001", true, "U0BOB"
+	mpim := slackapi.Channel{}
+	mpim.ID, mpim.IsMpIM = "G001", true
+	api := &slackutil.MockSlackAPI{
+		AuthTestFunc: func() (*slackapi.AuthTestResponse, error) {
+			return &slackapi.AuthTestResponse{UserID: "UME"}, nil
+		},
+		GetConversationsForUserFunc: func(params *slackapi.GetConversationsForUserParameters) ([]slackapi.Channel, string, error) {
+			if strings.Join(params.Types, ",") != "im,mpim" {
+				t.Errorf("Types = %v", params.Types)
+			}
+			return []slackapi.Channel{im, mpim}, "", nil
+		},
+		GetUsersFunc: func(options ...slackapi.GetUsersOption) ([]slackapi.User, error) {
+			return []slackapi.User{
+				{ID: "U0BOB", Name: "bob", Profile: slackapi.UserProfile{DisplayName: "Bobby"}},
+				{ID: "U0CAROL", Name: "carol", RealName: "Carol King"},
+			}, nil
+		},
+		GetUsersInConversationFunc: func(params *slackapi.GetUsersInConversationParameters) ([]string, string, error) {
+			return []string{"UME", "U0CAROL", "U0GONE"}, "", nil
+		},
+	}
+
+	dms, err := listDMs(api, 0)
+
+	if err != nil {
+		t.Fatalf("listDMs: %v", err)
+	}
+	if len(dms) != 2 {
+		t.Fatalf("dms = %+v", dms)
+	}
+	if dm := dms[0]; dm.ID != "D001" || strings.Join(dm.Names, ",") != "Bobby" {
+		t.Errorf("im = %+v", dm)
+	}
+	if dm := dms[1]; !dm.IsMpIM || strings.Join(dm.Users, ",") != "U0CAROL,U0GONE" || strings.Join(dm.Names, ",") != "Carol King,U0GONE" {
+		t.Errorf("mpim = %+v", dm)
+	}
+}
+
+func TestOpenDM(t *testing.T) {
+	var opened, posted []string
+	api := dmAPI(&opened, &posted)
+
+	dm, err := openDM(api, []string{"D042"})
+	if err != nil || dm.Channel != "D042" || len(opened) != 0 {
+		t.Errorf("dm = %+v, opened = %v, err = %v", dm, opened, err)
+	}
+
+	dm, err = openDM(api, []string{"@bob", "U0CAROL"})
+	if err != nil || dm.Channel != "DU0BOBU0CAROL" || strings.Join(opened, ";") != "U0BOB,U0CAROL" {
+		t.Errorf("dm = %+v, opened = %v, err = %v", dm, opened, err)
+	}
+
+	if _, err := openDM(api, []string{"@nobody"}); err == nil || !strings.Contains(err.Error(), "no user named @nobody") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestFindDM(t *testing.T) {
+	var opened, posted []string
+	api := dmAPI(&opened, &posted)
+
+	dm, err := findDM(api, []string{"@bob"})
+	if err != nil || dm.Channel != "DU0BOB" {
+		t.Errorf("dm = %+v, err = %v", dm, err)
+	}
+	dm, err = findDM(api, []string{"U0CAROL", "bob"})
+	if err != nil || dm.Channel != "G001" {
+		t.Errorf("dm = %+v, err = %v", dm, err)
+	}
+	if _, err := findDM(api, []string{"U0CAROL"}); err == nil || !strings.Contains(err.Error(), "no direct message with U0CAROL") {
+		t.Errorf("err = %v", err)
+	}
+	if len(opened) != 0 {
+		t.Errorf("opened = %v", opened)
+	}
+}
+
+func TestHandleGetDMHistory_DoesNotOpen(t *testing.T) {
+	var opened, posted []string
+	api := dmAPI(&opened, &posted)
+	var channel string
+	api.GetConversationHistoryFunc = func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+		channel = params.ChannelID
+		return &slackapi.GetConversationHistoryResponse{}, nil
+	}
+	cleanup := setMockClient(api)
+	defer cleanup()
+
+	result, err := handleGetDMHistory(context.Background(), makeRequest(map[string]any{"users": []any{"bob"}}))
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("result = %s, err = %v", resultText(t, result), err)
+	}
+	if channel != "DU0BOB" || len(opened) != 0 {
+		t.Errorf("channel = %q, opened = %v", channel, opened)
+	}
+}
+
+func TestHandleSendDM_DryRunMakesNoCalls(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	cleanup := setMockClient(&slackutil.MockSlackAPI{})
+	defer cleanup()
+
+	result, err := handleSendDM(context.Background(), makeRequest(map[string]any{"users": []any{"bob"}, "text": "lunch?", "dry_run": true}))
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("result = %s, err = %v", resultText(t, result), err)
+	}
+	var plan slackutil.PostPlan
+	if err := json.Unmarshal([]byte(resultText(t, result)), &plan); err != nil {
+		t.Fatalf("failed to parse result JSON: %v", err)
+	}
+	if plan.Channel != "" || len(plan.Warnings) != 1 || !strings.Contains(plan.Warnings[0], "channel unresolved") {
+		t.Errorf("plan = %+v", plan)
+	}
+}
+
+func TestHandleSendDM(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	var opened, posted []string
+	cleanup := setMockClient(dmAPI(&opened, &posted))
+	defer cleanup()
+
+	result, err := handleSendDM(context.Background(), makeRequest(map[string]any{"users": []any{"bob"}, "text": "lunch?"}))
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(posted, ",") != "DU0BOB" {
+		t.Errorf("posted = %v", posted)
+	}
+	var got slackutil.PostResult
+	if err := json.Unmarshal([]byte(resultText(t, result)), &got); err != nil {
+		t.Fatalf("failed to parse result JSON: %v", err)
+	}
+	if got.Channel != "DU0BOB" {
+		t.Errorf("result = %+v", got)
+	}
+}
+
+func TestHandleGetDMHistory_NeverExposed(t *testing.T) {
+	writeSafeguardConfig(t, `{"inbound": {"never_expose": ["DU0BOB"]}}`)
+	var opened, posted []string
+	cleanup := setMockClient(dmAPI(&opened, &posted))
+	defer cleanup()
+
+	result, err := handleGetDMHistory(context.Background(), makeRequest(map[string]any{"users": []any{"U0BOB"}}))
+
+	if err != nil || !isErrorResult(result) || !strings.Contains(resultText(t, result), "not exposed") {
+		t.Errorf("result = %s, err = %v", resultText(t, result), err)
+	}
+}
+
+func TestHandleOpenDM_EmptyUsers(t *testing.T) {
+	cleanup := setMockClient(&slackutil.MockSlackAPI{})
+	defer cleanup()
+
+	result, err := handleOpenDM(context.Background(), makeRequest(map[string]any{"users": []any{}}))
+
+	if err != nil || !isErrorResult(result) {
+		t.Errorf("result = %s, err = %v", resultText(t, result), err)
+	}
+}
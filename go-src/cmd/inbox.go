@@ -0,0 +1,375 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tackeyy/slamy/internal/output"
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+
+	"github.com/slack-go/slack"
+	"github.com/spf13/cobra"
+)
+
+// Inbox reasons, in priority order.
+const (
+	inboxDM           = "dm"
+	inboxMention      = "mention"
+	inboxGroupDM      = "group_dm"
+	inboxGroupMention = "group_mention"
+	inboxBroadcast    = "broadcast"
+)
+
+var inboxPriority = []string{inboxDM, inboxMention, inboxGroupDM, inboxGroupMention, inboxBroadcast}
+
+const (
+	// inboxSearchCount is the page size of the inbox searches.
+	inboxSearchCount = 100
+	// inboxSearchPages caps the pages read per search.
+	inboxSearchPages = 3
+	// inboxHistoryPages caps the history pages read per channel when
+	// looking for broadcasts.
+	inboxHistoryPages = 3
+	// inboxRepliesPageSize is the page size used to read a thread.
+	inboxRepliesPageSize = 200
+)
+
+// inboxHistoryTypes are the conversations scanned for broadcasts, which
+// search does not find. DMs have no broadcasts.
+var inboxHistoryTypes = []string{"public_channel", "private_channel", "mpim"}
+
+// inbox is the result of collectInbox.
+type inbox struct {
+	Items []slackutil.InboxItem `json:"items"`
+	// Warnings reports checks that could not be made.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// collectInbox finds messages since the given time that mention the
+// authenticated user or one of their user groups, or were sent to them in a
+// DM or group DM, with search, and @here, @channel and @everyone broadcasts
+// in their channels from the channel history. It then checks whether the
+// user has replied or reacted. Items
+// are ordered by priority (see inboxPriority), newest first within each
+// reason. Unless includeHandled is set, only items without a reply or
+// reaction are returned. limit caps the items (0 for all).
+func collectInbox(api slackutil.SlackAPI, since time.Time, includeHandled bool, limit int) (inbox, error) {
+	var box inbox
+	authResp, err := api.AuthTest()
+	if err != nil {
+		return box, fmt.Errorf("failed to get auth info: %w", err)
+	}
+	me := authResp.UserID
+
+	queries := []string{"<@" + me + ">", "to:me"}
+	var groups []string
+	userGroups, err := api.GetUserGroups(slack.GetUserGroupsOptionIncludeUsers(true))
+	if err != nil {
+		box.Warnings = append(box.Warnings, fmt.Sprintf("user group mentions not checked: %v", err))
+	}
+	for _, g := range userGroups {
+		if slices.Contains(g.Users, me) {
+			groups = append(groups, g.ID)
+			queries = append(queries, "<!subteam^"+g.ID+">")
+		}
+	}
+
+	// search.messages' after: is exclusive and by day; filter by ts below.
+	after := " after:" + since.AddDate(0, 0, -1).Format("2006-01-02")
+	sinceTS := fmt.Sprintf("%d.000000", since.Unix())
+	seen := map[string]bool{}
+	var items []slackutil.InboxItem
+	for _, q := range queries {
+		matches, err := searchAllMessages(api, q+after)
+		if err != nil {
+			return box, err
+		}
+		for _, m := range matches {
+			key := m.Channel.ID + "/" + m.Timestamp
+			if seen[key] || m.User == me || slackutil.CompareTS(m.Timestamp, sinceTS) < 0 {
+				continue
+			}
+			seen[key] = true
+			item := slackutil.InboxItem{Message: slackutil.NewSearchMatch(m), Reason: inboxReason(m.Channel.ID, m.Channel.IsMPIM, m.Text, me, groups)}
+			item.ThreadTs = permalinkThreadTS(m.Permalink)
+			items = append(items, item)
+		}
+	}
+
+	found, warnings := scanInboxHistory(api, me, groups, authResp.URL, sinceTS)
+	box.Warnings = append(box.Warnings, warnings...)
+	for _, item := range found {
+		if key := item.ChannelID + "/" + item.Ts; !seen[key] {
+			seen[key] = true
+			items = append(items, item)
+		}
+	}
+
+	threads := map[string][]slack.Message{}
+	for i := range items {
+		if err := checkResponse(api, me, &items[i], threads); err != nil {
+			box.Warnings = append(box.Warnings, err.Error())
+		}
+	}
+
+	box.Items = []slackutil.InboxItem{}
+	for _, item := range items {
+		if includeHandled || !item.Replied && !item.Reacted {
+			box.Items = append(box.Items, item)
+		}
+	}
+	sort.SliceStable(box.Items, func(i, j int) bool {
+		pi, pj := slices.Index(inboxPriority, box.Items[i].Reason), slices.Index(inboxPriority, box.Items[j].Reason)
+		if pi != pj {
+			return pi < pj
+		}
+		return slackutil.CompareTS(box.Items[i].Ts, box.Items[j].Ts) > 0
+	})
+	if limit > 0 && len(box.Items) > limit {
+		box.Items = box.Items[:limit]
+	}
+	return box, nil
+}
+
+// searchAllMessages returns up to inboxSearchPages pages of matches for
+// query, newest first.
+func searchAllMessages(api slackutil.SlackAPI, query string) ([]slack.SearchMessage, error) {
+	var out []slack.SearchMessage
+	for page := 1; page <= inboxSearchPages; page++ {
+		result, err := api.SearchMessages(query, slack.SearchParameters{
+			Sort:          "timestamp",
+			SortDirection: "desc",
+			Count:         inboxSearchCount,
+			Page:          page,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("search failed: %w", err)
+		}
+		out = append(out, result.Matches...)
+		if page >= result.Paging.Pages {
+			break
+		}
+	}
+	return out, nil
+}
+
+// scanInboxHistory reads the history since sinceTS of the channels and
+// group DMs me is in and returns the top-level messages from others that
+// broadcast with @here, @channel or @everyone or mention me or one of
+// groups. Channels that cannot be read are reported as warnings. teamURL is
+// the workspace URL the permalinks are built from.
+func scanInboxHistory(api slackutil.SlackAPI, me string, groups []string, teamURL, sinceTS string) ([]slackutil.InboxItem, []string) {
+	channels, err := memberChannels(api, me, inboxHistoryTypes, 0, false)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("broadcasts not checked: %v", err)}
+	}
+	var items []slackutil.InboxItem
+	var warnings []string
+	for _, ch := range channels {
+		params := &slack.GetConversationHistoryParameters{ChannelID: ch.ID, Oldest: sinceTS, Limit: unreadPageSize}
+		for page := 1; page <= inboxHistoryPages; page++ {
+			resp, err := api.GetConversationHistory(params)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("broadcasts in %s not checked: %v", ch.ID, err))
+				break
+			}
+			for _, msg := range resp.Messages {
+				if msg.User == me || msg.SubType != "" {
+					continue
+				}
+				reason := inboxReason(ch.ID, ch.IsMpIM, msg.Text, me, groups)
+				if reason != inboxGroupMention && !mentions(msg.Text, me) {
+					continue
+				}
+				m := slackutil.NewMessage(msg)
+				// Like search results, only replies carry their thread.
+				m.ChannelID, m.Channel, m.ThreadTs = ch.ID, ch.Name, ""
+				m.Permalink = messagePermalink(teamURL, ch.ID, msg.Timestamp)
+				items = append(items, slackutil.InboxItem{Message: m, Reason: reason})
+			}
+			if resp.ResponseMetaData.NextCursor == "" {
+				break
+			}
+			params.Cursor = resp.ResponseMetaData.NextCursor
+		}
+	}
+	return items, warnings
+}
+
+// messagePermalink returns the permalink of a top-level message in the
+// workspace at teamURL, or "" when the URL is unknown.
+func messagePermalink(teamURL, channelID, ts string) string {
+	if teamURL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(teamURL, "/") + "/archives/" + channelID + "/p" + strings.Replace(ts, ".", "", 1)
+}
+
+// inboxReason classifies a message in channelID with text found for the
+// user me.
+func inboxReason(channelID string, isMpIM bool, text, me string, groups []string) string {
+	switch {
+	case strings.HasPrefix(channelID, "D"):
+		return inboxDM
+	case strings.Contains(text, "<@"+me+">") || strings.Contains(text, "<@"+me+"|"):
+		return inboxMention
+	case isMpIM:
+		return inboxGroupDM
+	}
+	for _, g := range groups {
+		if strings.Contains(text, "<!subteam^"+g) {
+			return inboxGroupMention
+		}
+	}
+	if mentions(text, "") {
+		return inboxBroadcast
+	}
+	return inboxMention
+}
+
+// permalinkThreadTS returns the thread_ts query parameter of a message
+// permalink, which search results carry for thread replies.
+func permalinkThreadTS(permalink string) string {
+	u, err := url.Parse(permalink)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("thread_ts")
+}
+
+// checkResponse sets item.Replied and item.Reacted from the thread the item
+// belongs to and, for a top-level DM message, the conversation after it.
+// threads caches conversations.replies results by channel and thread.
+func checkResponse(api slackutil.SlackAPI, me string, item *slackutil.InboxItem, threads map[string][]slack.Message) error {
+	root := item.ThreadTs
+	if root == "" {
+		root = item.Ts
+	}
+	key := item.ChannelID + "/" + root
+	thread, ok := threads[key]
+	if !ok {
+		params := &slack.GetConversationRepliesParameters{ChannelID: item.ChannelID, Timestamp: root, Limit: inboxRepliesPageSize}
+		for {
+			msgs, _, nextCursor, err := api.GetConversationReplies(params)
+			if err != nil {
+				return fmt.Errorf("could not check replies to %s in %s: %v", item.Ts, item.ChannelID, err)
+			}
+			thread = append(thread, msgs...)
+			if nextCursor == "" {
+				break
+			}
+			params.Cursor = nextCursor
+		}
+		threads[key] = thread
+	}
+	for _, msg := range thread {
+		if msg.Timestamp == item.Ts {
+			item.Reacted = reactedBy(msg, me)
+		} else if msg.User == me && slackutil.CompareTS(msg.Timestamp, item.Ts) > 0 {
+			item.Replied = true
+		}
+	}
+
+	if item.Replied || item.ThreadTs != "" || item.Reason != inboxDM && item.Reason != inboxGroupDM {
+		return nil
+	}
+	resp, err := api.GetConversationHistory(&slack.GetConversationHistoryParameters{ChannelID: item.ChannelID, Oldest: item.Ts, Limit: 100})
+	if err != nil {
+		return fmt.Errorf("could not check replies to %s in %s: %v", item.Ts, item.ChannelID, err)
+	}
+	for _, msg := range resp.Messages {
+		if msg.User == me && slackutil.CompareTS(msg.Timestamp, item.Ts) > 0 {
+			item.Replied = true
+		}
+	}
+	return nil
+}
+
+// reactedBy reports whether user has reacted to msg.
+func reactedBy(msg slack.Message, user string) bool {
+	for _, r := range msg.Reactions {
+		if slices.Contains(r.Users, user) {
+			return true
+		}
+	}
+	return false
+}
+
+var inboxCmd = &cobra.Command{
+	Use:   "inbox",
+	Short: "List recent messages to you that still need a response (requires User Token)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, err := cmd.Flags().GetDuration("since")
+		if err != nil {
+			return fmt.Errorf("failed to get since flag: %w", err)
+		}
+		all, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			return fmt.Errorf("failed to get all flag: %w", err)
+		}
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			return fmt.Errorf("failed to get limit flag: %w", err)
+		}
+
+		client, err := slackutil.NewClient()
+		if err != nil {
+			return err
+		}
+		if client.User == nil {
+			return fmt.Errorf("SLACK_USER_TOKEN is required for inbox")
+		}
+
+		box, err := collectInbox(client.User, time.Now().Add(-since), all, limit)
+		if err != nil {
+			return err
+		}
+		for _, warning := range box.Warnings {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", warning)
+		}
+
+		return render(output.View{
+			Data:    box.Items,
+			Columns: []string{"reason", "ts", "channel_id", "channel", "user", "text", "replied", "reacted", "permalink"},
+			Text: func(w io.Writer) error {
+				if len(box.Items) == 0 {
+					fmt.Fprintln(w, "Inbox is empty")
+					return nil
+				}
+				for _, item := range box.Items {
+					where := item.ChannelID
+					if item.Channel != "" && item.Reason != inboxDM {
+						where = "#" + item.Channel
+					}
+					state := ""
+					switch {
+					case item.Replied:
+						state = " (replied)"
+					case item.Reacted:
+						state = " (reacted)"
+					}
+					text := item.Text
+					if len(text) > 200 {
+						text = text[:200] + "..."
+					}
+					fmt.Fprintf(w, "[%s] %-13s %s %s%s:\n  %s\n  %s\n\n", formatTimestamp(item.Ts), item.Reason, where, item.Author(), state, text, item.Permalink)
+				}
+				return nil
+			},
+		})
+	},
+}
+
+func init() {
+	inboxCmd.Flags().Duration("since", 7*24*time.Hour, "How far back to look")
+	inboxCmd.Flags().Bool("all", false, "Include messages you have already replied or reacted to")
+	inboxCmd.Flags().Int("limit", 50, "Maximum number of messages to return (0 for all)")
+
+	rootCmd.AddCommand(inboxCmd)
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	slackapi "github.com/slack-go/slack"
+
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+)
+
+// inboxSince is the start of the inbox window used by the tests.
+var inboxSince = time.Unix(1700000000, 0)
+
+func searchMatch(channel, ts, user, text string) slackapi.SearchMessage {
+	m := slackapi.SearchMessage{Timestamp: ts, User: user, Text: text, Permalink: "https://example.slack.com/archives/" + channel + "/p" + strings.ReplaceAll(ts, ".", "")}
+	m.Channel.ID = channel
+	return m
+}
+
+// inboxAPI returns a mock workspace where UME is in user group S001 and:
+//   - D001 holds an unanswered DM,
+//   - C001 holds a mention UME replied to in the thread,
+//   - C002 holds a user group mention UME reacted to,
+//   - C003 holds an unanswered @here broadcast, which only its history shows.
+func inboxAPI(t *testing.T) (*slackutil.MockSlackAPI, *[]string) {
+	var queries []string
+	dm := searchMatch("D001", "1700000300.000000", "U001", "are you around?")
+	mention := searchMatch("C001", "1700000200.000000", "U002", "<@UME> can you review?")
+	mention.Channel.Name = "dev"
+	group := searchMatch("C002", "1700000100.000000", "U003", "<!subteam^S001|@oncall> standup moved")
+	group.Channel.Name = "ops"
+	own := searchMatch("C001", "1700000400.000000", "UME", "<@UME> note to self")
+	old := searchMatch("C001", "1690000000.000000", "U002", "<@UME> ancient")
+
+	general := slackapi.Channel{}
+	general.ID, general.Name = "C003", "general"
+	mpim := slackapi.Channel{}
+	mpim.ID, mpim.IsMpIM = "G001", true
+	historyMsg := func(ts, user, text string) slackapi.Message {
+		return slackapi.Message{Msg: slackapi.Msg{Timestamp: ts, User: user, Text: text}}
+	}
+
+	api := &slackutil.MockSlackAPI{
+		AuthTestFunc: func() (*slackapi.AuthTestResponse, error) {
+			return &slackapi.AuthTestResponse{UserID: "UME", URL: "https://example.slack.com/"}, nil
+		},
+		GetConversationsForUserFunc: func(params *slackapi.GetConversationsForUserParameters) ([]slackapi.Channel, string, error) {
+			if strings.Join(params.Types, ",") != "public_channel,private_channel,mpim" {
+				t.Errorf("Types = %v", params.Types)
+			}
+			return []slackapi.Channel{general, mpim}, "", nil
+		},
+		GetUserGroupsFunc: func(options ...slackapi.GetUserGroupsOption) ([]slackapi.UserGroup, error) {
+			return []slackapi.UserGroup{{ID: "S001", Users: []string{"U003", "UME"}}, {ID: "S002", Users: []string{"U003"}}}, nil
+		},
+		SearchMessagesFunc: func(query string, params slackapi.SearchParameters) (*slackapi.SearchMessages, error) {
+			queries = append(queries, query)
+			result := &slackapi.SearchMessages{}
+			result.Paging.Pages = 1
+			switch {
+			case strings.HasPrefix(query, "<@UME>"):
+				result.Matches = []slackapi.SearchMessage{own, mention, old}
+			case strings.HasPrefix(query, "to:me"):
+				result.Matches = []slackapi.SearchMessage{dm, mention}
+			case strings.HasPrefix(query, "<!subteam^S001>"):
+				result.Matches = []slackapi.SearchMessage{group}
+			default:
+				t.Errorf("unexpected query %q", query)
+			}
+			return result, nil
+		},
+		GetConversationRepliesFunc: func(params *slackapi.GetConversationRepliesParameters) ([]slackapi.Message, bool, string, error) {
+			msg := slackapi.Message{Msg: slackapi.Msg{Timestamp: params.Timestamp, User: "U00X"}}
+			switch params.ChannelID {
+			case "C001":
+				reply := slackapi.Message{Msg: slackapi.Msg{Timestamp: "1700000250.000000", User: "UME", ThreadTimestamp: params.Timestamp}}
+				return []slackapi.Message{msg, reply}, false, "", nil
+			case "C002":
+				msg.Reactions = []slackapi.ItemReaction{{Name: "eyes", Users: []string{"UME"}}}
+			}
+			return []slackapi.Message{msg}, false, "", nil
+		},
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			resp := &slackapi.GetConversationHistoryResponse{}
+			switch params.ChannelID {
+			case "D001":
+				if params.Oldest != "1700000300.000000" {
+					t.Errorf("history params = %+v", params)
+				}
+			case "C003":
+				if slackutil.CompareTS(params.Oldest, "1700000000.000000") > 0 {
+					t.Errorf("history params = %+v", params)
+				}
+				joined := historyMsg("1700000600.000000", "U005", "<!channel> joined")
+				joined.SubType = "channel_join"
+				resp.Messages = []slackapi.Message{
+					joined,
+					historyMsg("1700000550.000000", "UME", "<!here> my own"),
+					historyMsg("1700000500.000000", "U004", "<!here> deploy at 5"),
+					historyMsg("1700000450.000000", "U004", "no mention"),
+				}
+			case "G001":
+				resp.Messages = []slackapi.Message{historyMsg("1700000350.000000", "U004", "plain group DM")}
+			default:
+				t.Errorf("history params = %+v", params)
+			}
+			return resp, nil
+		},
+	}
+	return api, &queries
+}
+
+func TestCollectInbox_OpenItems(t *testing.T) {
+	api, queries := inboxAPI(t)
+
+	box, err := collectInbox(api, inboxSince, false, 0)
+
+	if err != nil {
+		t.Fatalf("collectInbox: %v", err)
+	}
+	if len(*queries) != 3 || !strings.HasSuffix((*queries)[0], " after:2023-11-13") {
+		t.Errorf("queries = %q", *queries)
+	}
+	if len(box.Items) != 2 || box.Items[0].ChannelID != "D001" || box.Items[0].Reason != inboxDM {
+		t.Fatalf("items = %+v", box.Items)
+	}
+	if box.Items[0].Permalink == "" || len(box.Warnings) != 0 {
+		t.Errorf("box = %+v", box)
+	}
+	broadcast := box.Items[1]
+	if broadcast.ChannelID != "C003" || broadcast.Channel != "general" || broadcast.Reason != inboxBroadcast || broadcast.Ts != "1700000500.000000" {
+		t.Errorf("broadcast = %+v", broadcast)
+	}
+	if broadcast.Permalink != "https://example.slack.com/archives/C003/p1700000500000000" {
+		t.Errorf("permalink = %q", broadcast.Permalink)
+	}
+}
+
+func TestCollectInbox_HistoryUnavailable(t *testing.T) {
+	api, _ := inboxAPI(t)
+	api.GetConversationsForUserFunc = func(params *slackapi.GetConversationsForUserParameters) ([]slackapi.Channel, string, error) {
+		return nil, "", errors.New("ratelimited")
+	}
+
+	box, err := collectInbox(api, inboxSince, false, 0)
+
+	if err != nil {
+		t.Fatalf("collectInbox: %v", err)
+	}
+	if len(box.Items) != 1 || len(box.Warnings) != 1 || !strings.Contains(box.Warnings[0], "broadcasts not checked") {
+		t.Errorf("box = %+v", box)
+	}
+}
+
+func TestCheckResponse_PagesThroughThread(t *testing.T) {
+	var cursors []string
+	api := &slackutil.MockSlackAPI{
+		GetConversationRepliesFunc: func(params *slackapi.GetConversationRepliesParameters) ([]slackapi.Message, bool, string, error) {
+			cursors = append(cursors, params.Cursor)
+			if params.Cursor == "" {
+				return []slackapi.Message{{Msg: slackapi.Msg{Timestamp: "1700000200.000000", User: "U002"}}}, true, "page2", nil
+			}
+			return []slackapi.Message{{Msg: slackapi.Msg{Timestamp: "1700009999.000000", User: "UME"}}}, false, "", nil
+		},
+	}
+	item := slackutil.InboxItem{Message: slackutil.Message{Ts: "1700000200.000000", ChannelID: "C001"}, Reason: inboxMention}
+
+	if err := checkResponse(api, "UME", &item, map[string][]slackapi.Message{}); err != nil {
+		t.Fatalf("checkResponse: %v", err)
+	}
+	if !item.Replied || strings.Join(cursors, ",") != ",page2" {
+		t.Errorf("replied = %v, cursors = %q", item.Replied, cursors)
+	}
+}
+
+func TestCollectInbox_IncludeHandled(t *testing.T) {
+	api, _ := inboxAPI(t)
+
+	box, err := collectInbox(api, inboxSince, true, 0)
+
+	if err != nil {
+		t.Fatalf("collectInbox: %v", err)
+	}
+	var got []string
+	for _, item := range box.Items {
+		state := "open"
+		if item.Replied {
+			state = "replied"
+		} else if item.Reacted {
+			state = "reacted"
+		}
+		got = append(got, item.ChannelID+":"+item.Reason+":"+state)
+	}
+	want := "D001:dm:open,C001:mention:replied,C002:group_mention:reacted,C003:broadcast:open"
+	if strings.Join(got, ",") != want {
+		t.Errorf("items = %v, want %s", got, want)
+	}
+}
+
+func TestCollectInbox_UserGroupsUnavailable(t *testing.T) {
+	api, queries := inboxAPI(t)
+	api.GetUserGroupsFunc = func(options ...slackapi.GetUserGroupsOption) ([]slackapi.UserGroup, error) {
+		return nil, errors.New("missing_scope")
+	}
+
+	box, err := collectInbox(api, inboxSince, false, 1)
+
+	if err != nil {
+		t.Fatalf("collectInbox: %v", err)
+	}
+	if len(*queries) != 2 || len(box.Warnings) != 1 || !strings.Contains(box.Warnings[0], "missing_scope") {
+		t.Errorf("queries = %q, warnings = %v", *queries, box.Warnings)
+	}
+}
+
+func TestInboxReason(t *testing.T) {
+	tests := []struct {
+		channel string
+		isMpIM  bool
+		text    string
+		want    string
+	}{
+		{"D001", false, "<!here> hi", inboxDM},
+		{"C001", false, "<@UME|me> look", inboxMention},
+		{"C009", true, "lunch?", inboxGroupDM},
+		{"C001", false, "<!subteam^S001> look", inboxGroupMention},
+		{"C001", false, "<!channel> outage", inboxBroadcast},
+	}
+	for _, tt := range tests {
+		if got := inboxReason(tt.channel, tt.isMpIM, tt.text, "UME", []string{"S001"}); got != tt.want {
+			t.Errorf("inboxReason(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPermalinkThreadTS(t *testing.T) {
+	got := permalinkThreadTS("https://example.slack.com/archives/C001/p1700000250000000?thread_ts=1700000200.000000&cid=C001")
+	if got != "1700000200.000000" {
+		t.Errorf("permalinkThreadTS = %q", got)
+	}
+	if got := permalinkThreadTS("https://example.slack.com/archives/C001/p1700000200000000"); got != "" {
+		t.Errorf("permalinkThreadTS = %q", got)
+	}
+}
+
+func TestHandleGetInbox(t *testing.T) {
+	api, _ := inboxAPI(t)
+	cleanup := setMockClient(api)
+	defer cleanup()
+
+	// The fixtures are from 2023, so look back far enough to include them.
+	result, err := handleGetInbox(context.Background(), makeRequest(map[string]any{"since_hours": float64(time.Since(inboxSince).Hours() + 1)}))
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got struct {
+		Items []slackutil.InboxItem `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(resultText(t, result)), &got); err != nil {
+		t.Fatalf("failed to parse result JSON: %v", err)
+	}
+	if len(got.Items) != 2 || got.Items[0].ChannelID != "D001" || got.Items[1].ChannelID != "C003" {
+		t.Errorf("items = %+v", got.Items)
+	}
+}
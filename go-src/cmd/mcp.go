@@ -3,10 +3,11 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -14,29 +15,76 @@ import (
 	slackapi "github.com/slack-go/slack"
 	"github.com/spf13/cobra"
 
+	"github.com/tackeyy/slamy/internal/safeguard"
 	slackutil "github.com/tackeyy/slamy/internal/slack"
+	"github.com/tackeyy/slamy/internal/state"
 )
 
 var mcpCmd = &cobra.Command{
 	Use:   "mcp",
 	Short: "Start MCP server (stdio transport)",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runMCPServer()
+		flushInterval, err := cmd.Flags().GetDuration("outbox-flush-interval")
+		if err != nil {
+			return fmt.Errorf("failed to get outbox-flush-interval flag: %w", err)
+		}
+		pollInterval, err := cmd.Flags().GetDuration("resource-poll-interval")
+		if err != nil {
+			return fmt.Errorf("failed to get resource-poll-interval flag: %w", err)
+		}
+		return runMCPServer(flushInterval, pollInterval)
 	},
 }
 
-func runMCPServer() error {
+// runMCPServer serves the MCP tools, resources and prompts over stdio. When
+// flushInterval is positive the outbox is also flushed in the background at
+// that interval. When pollInterval is positive, clients may subscribe to
+// resources, which are polled for new messages at that interval.
+func runMCPServer(flushInterval, pollInterval time.Duration) error {
+	setAuditSource(state.OriginMCP, "")
+	hooks := &server.Hooks{}
+	hooks.AddAfterInitialize(func(ctx context.Context, id any, message *mcp.InitializeRequest, result *mcp.InitializeResult) {
+		client := message.Params.ClientInfo.Name
+		if v := message.Params.ClientInfo.Version; v != "" {
+			client += " " + v
+		}
+		setAuditSource(state.OriginMCP, client)
+	})
+
 	mcpServer := server.NewMCPServer("slamy", version,
 		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(pollInterval > 0, false),
+		server.WithPromptCapabilities(false),
 		server.WithRecovery(),
+		server.WithHooks(hooks),
 	)
 
+	logger := log.New(os.Stderr, "[slamy-mcp] ", log.LstdFlags)
 	registerMCPTools(mcpServer)
+	registerMCPResources(mcpServer)
+	registerMCPPrompts(mcpServer, logger)
 
 	stdioServer := server.NewStdioServer(mcpServer)
-	stdioServer.SetErrorLogger(log.New(os.Stderr, "[slamy-mcp] ", log.LstdFlags))
+	stdioServer.SetErrorLogger(logger)
+
+	if flushInterval > 0 {
+		done := make(chan struct{})
+		defer close(done)
+		go runOutboxFlusher(flushInterval, done, logger)
+	}
+
+	var stdin io.Reader = os.Stdin
+	var stdout io.Writer = os.Stdout
+	if pollInterval > 0 {
+		stdout = &syncWriter{w: os.Stdout}
+		subs := newResourceSubscriptions()
+		stdin = newSubscriptionReader(os.Stdin, stdout, subs)
+		done := make(chan struct{})
+		defer close(done)
+		go runResourcePoller(mcpServer, subs, pollInterval, done, logger)
+	}
 
-	return stdioServer.Listen(context.Background(), os.Stdin, os.Stdout)
+	return stdioServer.Listen(context.Background(), stdin, stdout)
 }
 
 func registerMCPTools(s *server.MCPServer) {
@@ -52,14 +100,30 @@ func registerMCPTools(s *server.MCPServer) {
 		handleListChannels,
 	)
 
+	// slack_list_unread
+	s.AddTool(
+		mcp.NewTool("slack_list_unread",
+			mcp.WithDescription("List channels, DMs and group DMs with unread messages, with unread and mention counts, ordered by priority: DMs, then channels with mentions, then other channels"),
+			mcp.WithNumber("limit", mcp.Description("Maximum number of conversations to check (default 100)")),
+			mcp.WithBoolean("include_messages", mcp.Description("Also return the unread messages of each conversation")),
+			mcp.WithNumber("messages_per_channel", mcp.Description("Maximum number of unread messages per conversation, newest first (default 10, at most 200; 0 returns only the counts)")),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		),
+		handleListUnread,
+	)
+
 	// slack_get_channel_history
 	s.AddTool(
 		mcp.NewTool("slack_get_channel_history",
 			mcp.WithDescription("Get message history from a Slack channel"),
 			mcp.WithString("channel_id", mcp.Required(), mcp.Description("The channel ID")),
 			mcp.WithNumber("limit", mcp.Description("Maximum number of messages (default 20)")),
-			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("oldest", mcp.Description("Only return messages posted after this Slack timestamp")),
+			mcp.WithBoolean("mark_read", mcp.Description("Also mark the channel as read up to the newest message returned")),
+			mcp.WithReadOnlyHintAnnotation(false),
 			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
 		),
 		handleGetChannelHistory,
 	)
@@ -77,12 +141,16 @@ func registerMCPTools(s *server.MCPServer) {
 		handleGetThreadReplies,
 	)
 
+	registerChannelTools(s)
+	registerDMTools(s)
+
 	// slack_post_message
 	s.AddTool(
 		mcp.NewTool("slack_post_message",
 			mcp.WithDescription("Post a message to a Slack channel"),
 			mcp.WithString("channel_id", mcp.Required(), mcp.Description("The channel ID")),
-			mcp.WithString("text", mcp.Required(), mcp.Description("Message text")),
+			mcp.WithString("text", mcp.Required(), mcp.Description("Message text (Markdown is converted to Slack formatting)")),
+			withPostParams(),
 			mcp.WithDestructiveHintAnnotation(false),
 		),
 		handlePostMessage,
@@ -94,7 +162,9 @@ func registerMCPTools(s *server.MCPServer) {
 			mcp.WithDescription("Reply to a message thread"),
 			mcp.WithString("channel_id", mcp.Required(), mcp.Description("The channel ID")),
 			mcp.WithString("thread_ts", mcp.Required(), mcp.Description("Timestamp of the parent message")),
-			mcp.WithString("text", mcp.Required(), mcp.Description("Reply text")),
+			mcp.WithString("text", mcp.Required(), mcp.Description("Reply text (Markdown is converted to Slack formatting)")),
+			mcp.WithBoolean("broadcast", mcp.Description("Also post the reply to the channel (only the first message of a split reply)")),
+			withPostParams(),
 			mcp.WithDestructiveHintAnnotation(false),
 		),
 		handleReplyToThread,
@@ -107,11 +177,38 @@ func registerMCPTools(s *server.MCPServer) {
 			mcp.WithString("channel_id", mcp.Required(), mcp.Description("The channel ID")),
 			mcp.WithString("timestamp", mcp.Required(), mcp.Description("Message timestamp")),
 			mcp.WithString("reaction", mcp.Required(), mcp.Description("Emoji name without colons")),
+			withDryRunParam(),
 			mcp.WithDestructiveHintAnnotation(false),
 		),
 		handleAddReaction,
 	)
 
+	// slack_mark_channel
+	s.AddTool(
+		mcp.NewTool("slack_mark_channel",
+			mcp.WithDescription("Mark a channel, DM or group DM as read, moving your read marker so its unread badge clears"),
+			mcp.WithString("channel_id", mcp.Required(), mcp.Description("The channel ID")),
+			mcp.WithString("ts", mcp.Description("Timestamp of the last message to mark as read (default: the newest message)")),
+			withDryRunParam(),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+		),
+		handleMarkChannel,
+	)
+
+	// slack_undo
+	s.AddTool(
+		mcp.NewTool("slack_undo",
+			mcp.WithDescription("Retract recent writes made through slamy: delete posted messages (every message of a split post) and remove added reactions. Only writes recorded in slamy's audit log can be undone"),
+			mcp.WithNumber("last", mcp.Description("Undo the last N writes (default 1)")),
+			mcp.WithString("ts", mcp.Description("Undo the post that created this message timestamp instead")),
+			withDryRunParam(),
+			mcp.WithDestructiveHintAnnotation(true),
+		),
+		handleUndo,
+	)
+
 	// slack_get_users
 	s.AddTool(
 		mcp.NewTool("slack_get_users",
@@ -146,6 +243,19 @@ func registerMCPTools(s *server.MCPServer) {
 		),
 		handleSearchMessages,
 	)
+
+	// slack_get_inbox
+	s.AddTool(
+		mcp.NewTool("slack_get_inbox",
+			mcp.WithDescription("List recent messages that mention you, your user groups or were sent to you in DMs and that you have not replied or reacted to, ordered by priority: DMs, direct mentions, group DMs, user group mentions, then @here/@channel"),
+			mcp.WithNumber("since_hours", mcp.Description("How many hours back to look (default 168)")),
+			mcp.WithBoolean("include_handled", mcp.Description("Also return messages you have replied or reacted to")),
+			mcp.WithNumber("limit", mcp.Description("Maximum number of messages (default 50)")),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		),
+		handleGetInbox,
+	)
 }
 
 var getClientFunc = func() (*slackutil.Client, error) {
@@ -184,25 +294,43 @@ func handleListChannels(ctx context.Context, request mcp.CallToolRequest) (*mcp.
 		allChannels = allChannels[:limit]
 	}
 
-	type channelOut struct {
-		ID         string `json:"id"`
-		Name       string `json:"name"`
-		Topic      string `json:"topic"`
-		Purpose    string `json:"purpose"`
-		NumMembers int    `json:"num_members"`
-		IsPrivate  bool   `json:"is_private"`
-		IsArchived bool   `json:"is_archived"`
-	}
-	out := make([]channelOut, len(allChannels))
-	for i, ch := range allChannels {
-		out[i] = channelOut{
-			ID:         ch.ID,
-			Name:       ch.Name,
-			Topic:      ch.Topic.Value,
-			Purpose:    ch.Purpose.Value,
-			NumMembers: ch.NumMembers,
-			IsPrivate:  ch.IsPrivate,
-			IsArchived: ch.IsArchived,
+	return jsonResult(slackutil.NewChannels(allChannels))
+}
+
+// unreadMaxMessages caps messages_per_channel of slack_list_unread.
+const unreadMaxMessages = 200
+
+func handleListUnread(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := getClientFunc()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	limit := request.GetInt("limit", 100)
+	includeMessages := request.GetBool("include_messages", false)
+	perChannel := min(max(request.GetInt("messages_per_channel", 10), 0), unreadMaxMessages)
+
+	authResp, err := client.User.AuthTest()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get auth info: %v", err)), nil
+	}
+	channels, err := memberChannels(client.User, authResp.UserID, unreadTypes, limit, false)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	unread := detectUnreadChannels(client, authResp.UserID, channels)
+	sortUnread(unread)
+
+	out := make([]slackutil.UnreadChannel, len(unread))
+	for i, ch := range unread {
+		out[i] = ch.view()
+		if includeMessages {
+			msgs := ch.Messages
+			if len(msgs) > perChannel {
+				msgs = msgs[:perChannel]
+			}
+			out[i].Messages = slackutil.NewMessages(msgs)
 		}
 	}
 
@@ -219,11 +347,15 @@ func handleGetChannelHistory(ctx context.Context, request mcp.CallToolRequest) (
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	if err := checkChannelExposed(channelID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	limit := request.GetInt("limit", 20)
 
 	params := &slackapi.GetConversationHistoryParameters{
 		ChannelID: channelID,
 		Limit:     limit,
+		Oldest:    request.GetString("oldest", ""),
 	}
 
 	resp, err := client.User.GetConversationHistory(params)
@@ -231,27 +363,13 @@ func handleGetChannelHistory(ctx context.Context, request mcp.CallToolRequest) (
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get history: %v", err)), nil
 	}
 
-	type msgOut struct {
-		Ts         string `json:"ts"`
-		User       string `json:"user"`
-		Text       string `json:"text"`
-		ThreadTs   string `json:"thread_ts,omitempty"`
-		Time       string `json:"time"`
-		ReplyCount int    `json:"reply_count,omitempty"`
-	}
-	out := make([]msgOut, len(resp.Messages))
-	for i, msg := range resp.Messages {
-		out[i] = msgOut{
-			Ts:         msg.Timestamp,
-			User:       msg.User,
-			Text:       msg.Text,
-			ThreadTs:   msg.ThreadTimestamp,
-			ReplyCount: msg.ReplyCount,
-			Time:       tsToTime(msg.Timestamp),
+	if request.GetBool("mark_read", false) && len(resp.Messages) > 0 {
+		if _, err := markChannel(client.User, channelID, resp.Messages[0].Timestamp, false); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 	}
 
-	return jsonResult(out)
+	return jsonResult(slackutil.NewMessages(resp.Messages))
 }
 
 func handleGetThreadReplies(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -264,6 +382,9 @@ func handleGetThreadReplies(ctx context.Context, request mcp.CallToolRequest) (*
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	if err := checkChannelExposed(channelID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	threadTs, err := request.RequireString("thread_ts")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
@@ -281,23 +402,40 @@ func handleGetThreadReplies(ctx context.Context, request mcp.CallToolRequest) (*
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get replies: %v", err)), nil
 	}
 
-	type msgOut struct {
-		Ts   string `json:"ts"`
-		User string `json:"user"`
-		Text string `json:"text"`
-		Time string `json:"time"`
-	}
-	out := make([]msgOut, len(msgs))
-	for i, msg := range msgs {
-		out[i] = msgOut{
-			Ts:   msg.Timestamp,
-			User: msg.User,
-			Text: msg.Text,
-			Time: tsToTime(msg.Timestamp),
+	return jsonResult(slackutil.NewMessages(msgs))
+}
+
+// withPostParams adds the parameters shared by the tools that post text.
+func withPostParams() mcp.ToolOption {
+	opts := []mcp.ToolOption{
+		mcp.WithString("format", mcp.Description("How to send the Markdown: mrkdwn (text, default) or blocks (Block Kit headers, lists, code and tables)"),
+			mcp.Enum(slackutil.FormatMrkdwn, slackutil.FormatBlocks)),
+		mcp.WithString("overflow", mcp.Description("How to post text longer than one message: thread (default, continue in a thread), sequential (several messages), truncate (cut with a marker) or file (upload as a snippet)"),
+			mcp.Enum(slackutil.OverflowStrategies...)),
+		mcp.WithBoolean("continuation_markers", mcp.Description("Append (1/3)-style markers when text is posted as several messages")),
+		mcp.WithString("idempotency_key", mcp.Description("Client-supplied key; retrying with the same key returns the original message instead of posting again")),
+		mcp.WithBoolean("queue", mcp.Description("Add the message to the local outbox; if it cannot be posted now it is kept there and retried later")),
+		withDryRunParam(),
+	}
+	return func(t *mcp.Tool) {
+		for _, opt := range opts {
+			opt(t)
 		}
 	}
+}
 
-	return jsonResult(out)
+// withDryRunParam adds the dry_run parameter to a tool that writes to Slack.
+func withDryRunParam() mcp.ToolOption {
+	return mcp.WithBoolean("dry_run", mcp.Description("Return what would be sent without calling Slack"))
+}
+
+// postOptionsFromRequest reads the parameters added by withPostParams.
+func postOptionsFromRequest(request mcp.CallToolRequest) slackutil.PostOptions {
+	return slackutil.PostOptions{
+		Format:   request.GetString("format", slackutil.FormatMrkdwn),
+		Overflow: request.GetString("overflow", slackutil.OverflowThread),
+		Markers:  request.GetBool("continuation_markers", false),
+	}
 }
 
 func handlePostMessage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -315,27 +453,7 @@ func handlePostMessage(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	text = slackutil.FixSlackMrkdwn(text)
-	chunks := slackutil.SplitMessage(text, slackutil.MaxMessageLength)
-
-	_, ts, err := client.User.PostMessage(channelID,
-		slackapi.MsgOptionText(chunks[0], false),
-	)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to post message: %v", err)), nil
-	}
-
-	for _, chunk := range chunks[1:] {
-		_, _, err := client.User.PostMessage(channelID,
-			slackapi.MsgOptionText(chunk, false),
-			slackapi.MsgOptionTS(ts),
-		)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to post thread reply: %v", err)), nil
-		}
-	}
-
-	return jsonResult(map[string]string{"channel": channelID, "ts": ts})
+	return sendMessageFromRequest(client.User, channelID, text, postOptionsFromRequest(request), request)
 }
 
 func handleReplyToThread(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -357,16 +475,39 @@ func handleReplyToThread(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	text = slackutil.FixSlackMrkdwn(text)
-	_, ts, err := client.User.PostMessage(channelID,
-		slackapi.MsgOptionText(text, false),
-		slackapi.MsgOptionTS(threadTs),
-	)
+	opts := postOptionsFromRequest(request)
+	opts.ThreadTS = threadTs
+	opts.Broadcast = request.GetBool("broadcast", false)
+
+	return sendMessageFromRequest(client.User, channelID, text, opts, request)
+}
+
+// sendMessageFromRequest posts text, or queues it when the request sets
+// queue, and returns the result as JSON.
+func sendMessageFromRequest(api slackutil.SlackAPI, channelID, text string, opts slackutil.PostOptions, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if request.GetBool("dry_run", false) {
+		plan, err := planMessage(channelID, text, opts)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return jsonResult(plan)
+	}
+
+	key := request.GetString("idempotency_key", "")
+	if request.GetBool("queue", false) {
+		result, err := queueMessage(api, channelID, text, opts, key, slackutil.DefaultDedupWindow)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return jsonResult(result)
+	}
+
+	result, err := postMessage(api, channelID, text, opts, key, slackutil.DefaultDedupWindow)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to reply: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	return jsonResult(map[string]string{"channel": channelID, "ts": ts, "thread_ts": threadTs})
+	return jsonResult(result)
 }
 
 func handleAddReaction(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -388,15 +529,65 @@ func handleAddReaction(ctx context.Context, request mcp.CallToolRequest) (*mcp.C
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	if request.GetBool("dry_run", false) {
+		return jsonResult(map[string]any{"channel": channelID, "ts": timestamp, "reaction": reaction, "dry_run": true})
+	}
+
 	ref := slackapi.NewRefToMessage(channelID, timestamp)
 	err = client.User.AddReaction(reaction, ref)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to add reaction: %v", err)), nil
 	}
+	recordWrite(state.AuditEntry{Action: state.AuditReactionAdd, Channel: channelID, TS: timestamp, Reaction: reaction}, "")
 
 	return jsonResult(map[string]string{"channel": channelID, "ts": timestamp, "reaction": reaction})
 }
 
+func handleMarkChannel(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := getClientFunc()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	channelID, err := request.RequireString("channel_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := checkChannelExposed(channelID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	m, err := markChannel(client.User, channelID, request.GetString("ts", ""), request.GetBool("dry_run", false))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return jsonResult(m)
+}
+
+func handleUndo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	dry := request.GetBool("dry_run", false)
+	var api slackutil.SlackAPI
+	if !dry {
+		client, err := getClientFunc()
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		api = client.User
+	}
+
+	var timestamps []string
+	if ts := request.GetString("ts", ""); ts != "" {
+		timestamps = []string{ts}
+	}
+
+	results, err := undoWrites(api, request.GetInt("last", 1), timestamps, dry)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return jsonResult(results)
+}
+
 func handleGetUsers(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	client, err := getClientFunc()
 	if err != nil {
@@ -410,16 +601,7 @@ func handleGetUsers(ctx context.Context, request mcp.CallToolRequest) (*mcp.Call
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list users: %v", err)), nil
 	}
 
-	type userOut struct {
-		ID          string `json:"id"`
-		Name        string `json:"name"`
-		RealName    string `json:"real_name"`
-		DisplayName string `json:"display_name"`
-		IsBot       bool   `json:"is_bot"`
-		Deleted     bool   `json:"deleted"`
-	}
-
-	var out []userOut
+	var out []slackutil.User
 	for _, u := range users {
 		if !includeBots && u.IsBot {
 			continue
@@ -427,14 +609,10 @@ func handleGetUsers(ctx context.Context, request mcp.CallToolRequest) (*mcp.Call
 		if u.Deleted {
 			continue
 		}
-		out = append(out, userOut{
-			ID:          u.ID,
-			Name:        u.Name,
-			RealName:    u.RealName,
-			DisplayName: u.Profile.DisplayName,
-			IsBot:       u.IsBot,
-			Deleted:     u.Deleted,
-		})
+		user := slackutil.NewUser(u)
+		// Email addresses are only exposed through slack_get_user_profile.
+		user.Email = ""
+		out = append(out, user)
 	}
 
 	return jsonResult(out)
@@ -456,22 +634,7 @@ func handleGetUserProfile(ctx context.Context, request mcp.CallToolRequest) (*mc
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get user profile: %v", err)), nil
 	}
 
-	out := map[string]interface{}{
-		"id":           user.ID,
-		"name":         user.Name,
-		"real_name":    user.RealName,
-		"display_name": user.Profile.DisplayName,
-		"email":        user.Profile.Email,
-		"title":        user.Profile.Title,
-		"phone":        user.Profile.Phone,
-		"status_text":  user.Profile.StatusText,
-		"status_emoji": user.Profile.StatusEmoji,
-		"tz":           user.TZ,
-		"is_admin":     user.IsAdmin,
-		"is_bot":       user.IsBot,
-	}
-
-	return jsonResult(out)
+	return jsonResult(slackutil.NewUserProfile(user))
 }
 
 func handleSearchMessages(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -499,37 +662,39 @@ func handleSearchMessages(ctx context.Context, request mcp.CallToolRequest) (*mc
 		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
 	}
 
-	type matchOut struct {
-		Ts        string `json:"ts"`
-		Channel   string `json:"channel"`
-		ChannelID string `json:"channel_id"`
-		User      string `json:"user"`
-		Text      string `json:"text"`
-		Permalink string `json:"permalink"`
-	}
-	out := struct {
-		Matches []matchOut `json:"matches"`
-		Total   int        `json:"total"`
-		Page    int        `json:"page"`
-	}{
-		Total: result.Total,
-		Page:  result.Paging.Page,
-	}
-	for _, m := range result.Matches {
-		out.Matches = append(out.Matches, matchOut{
-			Ts:        m.Timestamp,
-			Channel:   m.Channel.Name,
-			ChannelID: m.Channel.ID,
-			User:      m.User,
-			Text:      m.Text,
-			Permalink: m.Permalink,
-		})
+	return jsonResult(slackutil.NewSearchResult(result))
+}
+
+func handleGetInbox(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := getClientFunc()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	return jsonResult(out)
+	since := time.Now().Add(-time.Duration(request.GetInt("since_hours", 168)) * time.Hour)
+	box, err := collectInbox(client.User, since, request.GetBool("include_handled", false), request.GetInt("limit", 50))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return jsonResult(box)
 }
 
+// jsonResult returns v as JSON text, after the inbound safeguards have
+// redacted sensitive data and dropped never-exposed channels.
 func jsonResult(v interface{}) (*mcp.CallToolResult, error) {
+	redactor, err := outputRedactor()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	v, err = redactor.Redact(v)
+	var hidden *safeguard.HiddenError
+	if errors.As(err, &hidden) {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("JSON marshal error: %v", err)), nil
+	}
 	b, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("JSON marshal error: %v", err)), nil
@@ -538,18 +703,11 @@ func jsonResult(v interface{}) (*mcp.CallToolResult, error) {
 }
 
 func tsToTime(ts string) string {
-	parts := strings.SplitN(ts, ".", 2)
-	if len(parts) == 0 {
-		return ts
-	}
-	var sec int64
-	_, err := fmt.Sscanf(parts[0], "%d", &sec)
-	if err != nil || sec == 0 {
-		return ts
-	}
-	return time.Unix(sec, 0).Format("2006-01-02 15:04:05")
+	return slackutil.FormatTS(ts)
 }
 
 func init() {
+	mcpCmd.Flags().Duration("outbox-flush-interval", 0, "Flush the outbox in the background at this interval, e.g. 1m (0 disables)")
+	mcpCmd.Flags().Duration("resource-poll-interval", 30*time.Second, "Poll subscribed resources for new messages at this interval (0 disables subscriptions)")
 	rootCmd.AddCommand(mcpCmd)
 }
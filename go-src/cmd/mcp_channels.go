@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerChannelTools adds the channel information and management tools.
+func registerChannelTools(s *server.MCPServer) {
+	// slack_get_channel_info
+	s.AddTool(
+		mcp.NewTool("slack_get_channel_info",
+			mcp.WithDescription("Get details of a Slack channel: name, topic, purpose, member count, creator and flags"),
+			mcp.WithString("channel_id", mcp.Required(), mcp.Description("The channel ID")),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		),
+		handleGetChannelInfo,
+	)
+
+	// slack_get_channel_members
+	s.AddTool(
+		mcp.NewTool("slack_get_channel_members",
+			mcp.WithDescription("List the members of a Slack channel"),
+			mcp.WithString("channel_id", mcp.Required(), mcp.Description("The channel ID")),
+			mcp.WithNumber("limit", mcp.Description("Maximum number of members (default: all)")),
+			mcp.WithBoolean("resolve_names", mcp.Description("Also return each member's user name, real name and display name")),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		),
+		handleGetChannelMembers,
+	)
+
+	// slack_create_channel
+	s.AddTool(
+		mcp.NewTool("slack_create_channel",
+			mcp.WithDescription("Create a Slack channel"),
+			mcp.WithString("name", mcp.Required(), mcp.Description("Channel name (lowercase, no spaces)")),
+			mcp.WithBoolean("is_private", mcp.Description("Create a private channel")),
+			withDryRunParam(),
+			mcp.WithDestructiveHintAnnotation(false),
+		),
+		channelChangeHandler(func(request mcp.CallToolRequest) (channelChange, error) {
+			name, err := request.RequireString("name")
+			return channelChange{Action: channelCreate, Name: strings.TrimPrefix(name, "#"), Private: request.GetBool("is_private", false)}, err
+		}),
+	)
+
+	// slack_archive_channel
+	s.AddTool(
+		mcp.NewTool("slack_archive_channel",
+			mcp.WithDescription("Archive a Slack channel"),
+			mcp.WithString("channel_id", mcp.Required(), mcp.Description("The channel ID")),
+			withDryRunParam(),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithIdempotentHintAnnotation(true),
+		),
+		channelChangeHandler(channelOnly(channelArchive)),
+	)
+
+	// slack_unarchive_channel
+	s.AddTool(
+		mcp.NewTool("slack_unarchive_channel",
+			mcp.WithDescription("Unarchive a Slack channel"),
+			mcp.WithString("channel_id", mcp.Required(), mcp.Description("The channel ID")),
+			withDryRunParam(),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+		),
+		channelChangeHandler(channelOnly(channelUnarchive)),
+	)
+
+	// slack_rename_channel
+	s.AddTool(
+		mcp.NewTool("slack_rename_channel",
+			mcp.WithDescription("Rename a Slack channel"),
+			mcp.WithString("channel_id", mcp.Required(), mcp.Description("The channel ID")),
+			mcp.WithString("name", mcp.Required(), mcp.Description("New channel name")),
+			withDryRunParam(),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithIdempotentHintAnnotation(true),
+		),
+		channelChangeHandler(channelWithString(channelRename, "name")),
+	)
+
+	// slack_set_channel_topic
+	s.AddTool(
+		mcp.NewTool("slack_set_channel_topic",
+			mcp.WithDescription("Set the topic of a Slack channel, replacing the current one"),
+			mcp.WithString("channel_id", mcp.Required(), mcp.Description("The channel ID")),
+			mcp.WithString("topic", mcp.Required(), mcp.Description("New topic")),
+			withDryRunParam(),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithIdempotentHintAnnotation(true),
+		),
+		channelChangeHandler(channelWithString(channelSetTopic, "topic")),
+	)
+
+	// slack_set_channel_purpose
+	s.AddTool(
+		mcp.NewTool("slack_set_channel_purpose",
+			mcp.WithDescription("Set the purpose of a Slack channel, replacing the current one"),
+			mcp.WithString("channel_id", mcp.Required(), mcp.Description("The channel ID")),
+			mcp.WithString("purpose", mcp.Required(), mcp.Description("New purpose")),
+			withDryRunParam(),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithIdempotentHintAnnotation(true),
+		),
+		channelChangeHandler(channelWithString(channelSetPurpose, "purpose")),
+	)
+
+	// slack_invite_to_channel
+	s.AddTool(
+		mcp.NewTool("slack_invite_to_channel",
+			mcp.WithDescription("Invite users to a Slack channel"),
+			mcp.WithString("channel_id", mcp.Required(), mcp.Description("The channel ID")),
+			mcp.WithArray("user_ids", mcp.Required(), mcp.WithStringItems(), mcp.Description("IDs of the users to invite")),
+			withDryRunParam(),
+			mcp.WithDestructiveHintAnnotation(false),
+		),
+		channelChangeHandler(channelWithUsers(channelInvite)),
+	)
+
+	// slack_kick_from_channel
+	s.AddTool(
+		mcp.NewTool("slack_kick_from_channel",
+			mcp.WithDescription("Remove users from a Slack channel"),
+			mcp.WithString("channel_id", mcp.Required(), mcp.Description("The channel ID")),
+			mcp.WithArray("user_ids", mcp.Required(), mcp.WithStringItems(), mcp.Description("IDs of the users to remove")),
+			withDryRunParam(),
+			mcp.WithDestructiveHintAnnotation(true),
+		),
+		channelChangeHandler(channelWithUsers(channelKick)),
+	)
+
+	// slack_join_channel
+	s.AddTool(
+		mcp.NewTool("slack_join_channel",
+			mcp.WithDescription("Join a public Slack channel"),
+			mcp.WithString("channel_id", mcp.Required(), mcp.Description("The channel ID")),
+			withDryRunParam(),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+		),
+		channelChangeHandler(channelOnly(channelJoin)),
+	)
+
+	// slack_leave_channel
+	s.AddTool(
+		mcp.NewTool("slack_leave_channel",
+			mcp.WithDescription("Leave a Slack channel"),
+			mcp.WithString("channel_id", mcp.Required(), mcp.Description("The channel ID")),
+			withDryRunParam(),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithIdempotentHintAnnotation(true),
+		),
+		channelChangeHandler(channelOnly(channelLeave)),
+	)
+}
+
+func handleGetChannelInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := getClientFunc()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	channelID, err := request.RequireString("channel_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := checkChannelExposed(channelID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	info, err := channelInfo(client.User, channelID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return jsonResult(info)
+}
+
+func handleGetChannelMembers(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := getClientFunc()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	channelID, err := request.RequireString("channel_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := checkChannelExposed(channelID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	members, err := channelMembers(client.User, channelID, request.GetInt("limit", 0), request.GetBool("resolve_names", false))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return jsonResult(members)
+}
+
+// channelChangeHandler returns a tool handler that applies the channel change
+// built from the request, honouring dry_run.
+func channelChangeHandler(build func(request mcp.CallToolRequest) (channelChange, error)) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := getClientFunc()
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		c, err := build(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		c, err = applyChannelChange(client.User, c, request.GetBool("dry_run", false))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return jsonResult(c)
+	}
+}
+
+// channelOnly builds a change that takes only channel_id.
+func channelOnly(action string) func(mcp.CallToolRequest) (channelChange, error) {
+	return func(request mcp.CallToolRequest) (channelChange, error) {
+		channelID, err := request.RequireString("channel_id")
+		return channelChange{Action: action, Channel: channelID}, err
+	}
+}
+
+// channelWithString builds a rename, topic or purpose change from
+// channel_id and the named string parameter.
+func channelWithString(action, param string) func(mcp.CallToolRequest) (channelChange, error) {
+	return func(request mcp.CallToolRequest) (channelChange, error) {
+		channelID, err := request.RequireString("channel_id")
+		if err != nil {
+			return channelChange{}, err
+		}
+		value, err := request.RequireString(param)
+		if err != nil {
+			return channelChange{}, err
+		}
+		c := channelChange{Action: action, Channel: channelID}
+		if action == channelRename {
+			c.Name = strings.TrimPrefix(value, "#")
+		} else {
+			c.Text = value
+		}
+		return c, nil
+	}
+}
+
+// channelWithUsers builds an invite or kick change from channel_id and
+// user_ids.
+func channelWithUsers(action string) func(mcp.CallToolRequest) (channelChange, error) {
+	return func(request mcp.CallToolRequest) (channelChange, error) {
+		channelID, err := request.RequireString("channel_id")
+		if err != nil {
+			return channelChange{}, err
+		}
+		users, err := request.RequireStringSlice("user_ids")
+		if err != nil {
+			return channelChange{}, err
+		}
+		return channelChange{Action: action, Channel: channelID, Users: users}, nil
+	}
+}
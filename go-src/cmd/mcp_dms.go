@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	slackapi "github.com/slack-go/slack"
+
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+)
+
+// usersParam is the users parameter shared by the DM tools.
+func usersParam() mcp.ToolOption {
+	return mcp.WithArray("users", mcp.Required(), mcp.WithStringItems(),
+		mcp.Description("The other members: user IDs, email addresses or user names (several users make a group DM). A single DM ID is also accepted"))
+}
+
+// registerDMTools adds the direct message tools.
+func registerDMTools(s *server.MCPServer) {
+	// slack_list_dms
+	s.AddTool(
+		mcp.NewTool("slack_list_dms",
+			mcp.WithDescription("List your direct messages and group DMs with the other members' names"),
+			mcp.WithNumber("limit", mcp.Description("Maximum number of conversations (default 100)")),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		),
+		handleListDMs,
+	)
+
+	// slack_open_dm
+	s.AddTool(
+		mcp.NewTool("slack_open_dm",
+			mcp.WithDescription("Open a direct message or group DM and return its channel ID"),
+			usersParam(),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+		),
+		handleOpenDM,
+	)
+
+	// slack_get_dm_history
+	s.AddTool(
+		mcp.NewTool("slack_get_dm_history",
+			mcp.WithDescription("Get message history of an existing direct message or group DM"),
+			usersParam(),
+			mcp.WithNumber("limit", mcp.Description("Maximum number of messages (default 20)")),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+		),
+		handleGetDMHistory,
+	)
+
+	// slack_send_dm
+	s.AddTool(
+		mcp.NewTool("slack_send_dm",
+			mcp.WithDescription("Send a direct message, or a group DM to several users"),
+			usersParam(),
+			mcp.WithString("text", mcp.Required(), mcp.Description("Message text (Markdown is converted to Slack formatting)")),
+			withPostParams(),
+			mcp.WithDestructiveHintAnnotation(false),
+		),
+		handleSendDM,
+	)
+}
+
+func handleListDMs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := getClientFunc()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	dms, err := listDMs(client.User, request.GetInt("limit", 100))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return jsonResult(dms)
+}
+
+// dmUsers returns the request's users parameter.
+func dmUsers(request mcp.CallToolRequest) ([]string, error) {
+	users, err := request.RequireStringSlice("users")
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("users must not be empty")
+	}
+	return users, nil
+}
+
+// dmFromRequest opens the DM named by the request's users parameter.
+func dmFromRequest(api slackutil.SlackAPI, request mcp.CallToolRequest) (dmOpened, error) {
+	users, err := dmUsers(request)
+	if err != nil {
+		return dmOpened{}, err
+	}
+	return openDM(api, users)
+}
+
+func handleOpenDM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := getClientFunc()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	dm, err := dmFromRequest(client.User, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return jsonResult(dm)
+}
+
+func handleGetDMHistory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := getClientFunc()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	users, err := dmUsers(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dm, err := findDM(client.User, users)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := checkChannelExposed(dm.Channel); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	resp, err := client.User.GetConversationHistory(&slackapi.GetConversationHistoryParameters{
+		ChannelID: dm.Channel,
+		Limit:     request.GetInt("limit", 20),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get history: %v", err)), nil
+	}
+
+	return jsonResult(slackutil.NewMessages(resp.Messages))
+}
+
+func handleSendDM(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := getClientFunc()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	text, err := request.RequireString("text")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if request.GetBool("dry_run", false) {
+		users, err := dmUsers(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		plan, err := planDM(users, text, postOptionsFromRequest(request))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return jsonResult(plan)
+	}
+	dm, err := dmFromRequest(client.User, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return sendMessageFromRequest(client.User, dm.Channel, text, postOptionsFromRequest(request), request)
+}
@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+
+	"github.com/tackeyy/slamy/internal/state"
+)
+
+// builtinPrompts holds the prompts slamy ships with, in the same format as
+// user prompt files.
+//
+//go:embed prompts/*.md
+var builtinPrompts embed.FS
+
+// promptsDir is the directory of user prompt files in the data directory.
+const promptsDir = "prompts"
+
+var rePromptName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// promptTools are the tools prompt templates may call. Only tools that read
+// from Slack are listed.
+var promptTools = map[string]server.ToolHandlerFunc{
+	"slack_list_channels":       handleListChannels,
+	"slack_list_unread":         handleListUnread,
+	"slack_get_channel_history": handleGetChannelHistory,
+	"slack_get_thread_replies":  handleGetThreadReplies,
+	"slack_get_channel_info":    handleGetChannelInfo,
+	"slack_get_channel_members": handleGetChannelMembers,
+	"slack_list_dms":            handleListDMs,
+	"slack_get_dm_history":      handleGetDMHistory,
+	"slack_get_users":           handleGetUsers,
+	"slack_get_user_profile":    handleGetUserProfile,
+	"slack_search_messages":     handleSearchMessages,
+	"slack_get_inbox":           handleGetInbox,
+}
+
+// promptArg is an argument a prompt takes.
+type promptArg struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+}
+
+// promptDef is a prompt template. A prompt file is Markdown with YAML front
+// matter giving the description and arguments; the rest of the file is a Go
+// text/template rendered into the prompt's message.
+type promptDef struct {
+	Name        string      `yaml:"-"`
+	Description string      `yaml:"description"`
+	Arguments   []promptArg `yaml:"arguments"`
+	tmpl        *template.Template
+}
+
+// parsePrompt parses the prompt file src named name.
+func parsePrompt(name string, src []byte) (promptDef, error) {
+	def := promptDef{Name: name}
+	if !rePromptName.MatchString(name) {
+		return def, fmt.Errorf("invalid prompt name %q: use lowercase letters, digits, - and _", name)
+	}
+	src = bytes.ReplaceAll(src, []byte("\r\n"), []byte("\n"))
+	rest, ok := bytes.CutPrefix(src, []byte("---\n"))
+	if !ok {
+		return def, fmt.Errorf("prompt %s: missing front matter", name)
+	}
+	// The leading newline lets the front matter be empty.
+	front, body, ok := bytes.Cut(append([]byte("\n"), rest...), []byte("\n---\n"))
+	if !ok {
+		return def, fmt.Errorf("prompt %s: unterminated front matter", name)
+	}
+	if err := yaml.Unmarshal(front, &def); err != nil {
+		return def, fmt.Errorf("prompt %s: failed to parse front matter: %w", name, err)
+	}
+	for _, arg := range def.Arguments {
+		if arg.Name == "" {
+			return def, fmt.Errorf("prompt %s: argument without a name", name)
+		}
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(promptFuncs(context.Background())).Parse(string(body))
+	if err != nil {
+		return def, fmt.Errorf("prompt %s: failed to parse template: %w", name, err)
+	}
+	def.tmpl = tmpl
+	return def, nil
+}
+
+// loadPrompts returns the built-in prompts and the user's prompt files, by
+// name. A user file replaces the built-in prompt of the same name. Files
+// that cannot be read or parsed are skipped and reported in the errors.
+func loadPrompts() ([]promptDef, []error) {
+	byName := map[string]promptDef{}
+	var errs []error
+
+	builtins, _ := fs.Glob(builtinPrompts, "prompts/*.md")
+	for _, path := range builtins {
+		src, err := builtinPrompts.ReadFile(path)
+		if err == nil {
+			var def promptDef
+			def, err = parsePrompt(strings.TrimSuffix(filepath.Base(path), ".md"), src)
+			byName[def.Name] = def
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	dir, err := state.Path(promptsDir)
+	if err != nil {
+		return nil, append(errs, err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		errs = append(errs, fmt.Errorf("failed to read prompts: %w", err))
+	}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".md" {
+			continue
+		}
+		src, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read prompt: %w", err))
+			continue
+		}
+		def, err := parsePrompt(strings.TrimSuffix(e.Name(), ".md"), src)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		byName[def.Name] = def
+	}
+
+	defs := make([]promptDef, 0, len(byName))
+	for _, def := range byName {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs, errs
+}
+
+func registerMCPPrompts(s *server.MCPServer, logger *log.Logger) {
+	defs, errs := loadPrompts()
+	for _, err := range errs {
+		logger.Printf("skipping prompt: %v", err)
+	}
+	for _, def := range defs {
+		opts := []mcp.PromptOption{mcp.WithPromptDescription(def.Description)}
+		for _, arg := range def.Arguments {
+			argOpts := []mcp.ArgumentOption{mcp.ArgumentDescription(arg.Description)}
+			if arg.Required {
+				argOpts = append(argOpts, mcp.RequiredArgument())
+			}
+			opts = append(opts, mcp.WithArgument(arg.Name, argOpts...))
+		}
+		s.AddPrompt(mcp.NewPrompt(def.Name, opts...), promptHandler(def))
+	}
+}
+
+// promptHandler renders def with the request's arguments into a single user
+// message.
+func promptHandler(def promptDef) server.PromptHandlerFunc {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		text, err := renderPrompt(ctx, def, request.Params.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewGetPromptResult(def.Description, []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+		}), nil
+	}
+}
+
+// renderPrompt executes def's template. Every declared argument is set in
+// the template data, to "" when it was not given.
+func renderPrompt(ctx context.Context, def promptDef, args map[string]string) (string, error) {
+	data := map[string]any{}
+	for _, arg := range def.Arguments {
+		v := strings.TrimSpace(args[arg.Name])
+		if v == "" && arg.Required {
+			return "", fmt.Errorf("prompt %s: missing required argument %s", def.Name, arg.Name)
+		}
+		data[arg.Name] = v
+	}
+	tmpl, err := def.tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Funcs(promptFuncs(ctx)).Execute(&b, data); err != nil {
+		return "", fmt.Errorf("prompt %s: %w", def.Name, err)
+	}
+	return b.String(), nil
+}
+
+// promptFuncs returns the functions prompt templates can use:
+//
+//	tool NAME [KEY VALUE]...  the JSON result of a read-only slamy tool
+//	ago DURATION              the Slack timestamp DURATION (e.g. 24h, 7d) ago
+//	list S                    S split at commas and whitespace
+//	default DEF VALUE         VALUE, or DEF when VALUE is empty
+func promptFuncs(ctx context.Context) template.FuncMap {
+	return template.FuncMap{
+		"tool": func(name string, kv ...any) (string, error) {
+			return callPromptTool(ctx, name, kv)
+		},
+		"ago": func(d string) (string, error) {
+			dur, err := parseAgo(d)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d.000000", time.Now().Add(-dur).Unix()), nil
+		},
+		"list": func(s string) []string {
+			return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' })
+		},
+		"default": func(def, v string) string {
+			if v == "" {
+				return def
+			}
+			return v
+		},
+	}
+}
+
+// callPromptTool calls the tool name with the arguments kv, given as
+// alternating keys and values, and returns its text result.
+func callPromptTool(ctx context.Context, name string, kv []any) (string, error) {
+	handler, ok := promptTools[name]
+	if !ok {
+		return "", fmt.Errorf("tool %s is not available to prompts", name)
+	}
+	if len(kv)%2 != 0 {
+		return "", fmt.Errorf("tool %s: arguments must be key/value pairs", name)
+	}
+	args := make(map[string]any, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			return "", fmt.Errorf("tool %s: argument name %v is not a string", name, kv[i])
+		}
+		args[key] = kv[i+1]
+	}
+	// Prompts only read.
+	delete(args, "mark_read")
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = args
+	result, err := handler(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("tool %s: %w", name, err)
+	}
+	var texts []string
+	for _, c := range result.Content {
+		if text, ok := c.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	if result.IsError {
+		return "", fmt.Errorf("tool %s: %s", name, strings.Join(texts, " "))
+	}
+	return strings.Join(texts, "\n"), nil
+}
+
+// parseAgo parses a Go duration, also accepting whole days such as "7d".
+func parseAgo(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
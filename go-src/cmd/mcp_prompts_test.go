@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	slackapi "github.com/slack-go/slack"
+
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+)
+
+// writePrompt writes a user prompt file into the data directory.
+func writePrompt(t *testing.T, name, src string) {
+	t.Helper()
+	dir := filepath.Join(os.Getenv("SLAMY_HOME"), promptsDir)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func findPrompt(t *testing.T, defs []promptDef, name string) promptDef {
+	t.Helper()
+	for _, def := range defs {
+		if def.Name == name {
+			return def
+		}
+	}
+	t.Fatalf("prompt %s not found", name)
+	return promptDef{}
+}
+
+// historyPromptAPI returns a mock whose history holds one message per
+// channel and records the history requests.
+func historyPromptAPI(requests *[]*slackapi.GetConversationHistoryParameters) *slackutil.MockSlackAPI {
+	return &slackutil.MockSlackAPI{
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			*requests = append(*requests, params)
+			return &slackapi.GetConversationHistoryResponse{Messages: []slackapi.Message{watchMsg("1700000000.000100", "", "hello from "+params.ChannelID)}}, nil
+		},
+	}
+}
+
+func TestLoadPrompts_Builtins(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+
+	defs, errs := loadPrompts()
+
+	if len(errs) != 0 {
+		t.Fatalf("errs = %v", errs)
+	}
+	var names []string
+	for _, def := range defs {
+		names = append(names, def.Name)
+	}
+	if strings.Join(names, ",") != "draft_reply,summarize_channel,triage_inbox,weekly_digest" {
+		t.Errorf("names = %v", names)
+	}
+	summarize := findPrompt(t, defs, "summarize_channel")
+	if len(summarize.Arguments) != 2 || !summarize.Arguments[0].Required || summarize.Arguments[1].Required {
+		t.Errorf("arguments = %+v", summarize.Arguments)
+	}
+}
+
+func TestLoadPrompts_UserFiles(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	writePrompt(t, "summarize_channel.md", "---\ndescription: Our summary\n---\nSummarize {{.channel}}\n")
+	writePrompt(t, "standup.md", "---\ndescription: Standup notes\narguments:\n  - name: channel\n    required: true\n---\nStandup in {{.channel}}\n")
+	writePrompt(t, "broken.md", "no front matter\n")
+	writePrompt(t, "notes.txt", "ignored")
+
+	defs, errs := loadPrompts()
+
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "broken") {
+		t.Errorf("errs = %v", errs)
+	}
+	if len(defs) != 5 {
+		t.Errorf("defs = %d", len(defs))
+	}
+	if def := findPrompt(t, defs, "summarize_channel"); def.Description != "Our summary" {
+		t.Errorf("summarize_channel = %+v", def)
+	}
+	text, err := renderPrompt(context.Background(), findPrompt(t, defs, "standup"), map[string]string{"channel": "C001"})
+	if err != nil || text != "Standup in C001\n" {
+		t.Errorf("text = %q, err = %v", text, err)
+	}
+}
+
+func TestParsePrompt_Errors(t *testing.T) {
+	tests := []struct{ name, src, want string }{
+		{"Bad Name", "---\n---\n", "invalid prompt name"},
+		{"p", "---\ndescription: x\n", "unterminated front matter"},
+		{"p", "---\ndescription: [\n---\n", "front matter"},
+		{"p", "---\narguments:\n  - description: x\n---\n", "without a name"},
+		{"p", "---\n---\n{{tool\n", "failed to parse template"},
+	}
+	for _, tt := range tests {
+		if _, err := parsePrompt(tt.name, []byte(tt.src)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parsePrompt(%q) err = %v, want %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestPromptHandler_SummarizeChannel(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	var requests []*slackapi.GetConversationHistoryParameters
+	cleanup := setMockClient(historyPromptAPI(&requests))
+	defer cleanup()
+	defs, _ := loadPrompts()
+	request := mcp.GetPromptRequest{}
+	request.Params.Arguments = map[string]string{"channel": "C001", "since": "2d"}
+
+	result, err := promptHandler(findPrompt(t, defs, "summarize_channel"))(context.Background(), request)
+
+	if err != nil {
+		t.Fatalf("prompt: %v", err)
+	}
+	text := result.Messages[0].Content.(mcp.TextContent).Text
+	if result.Messages[0].Role != mcp.RoleUser || !strings.Contains(text, "<#C001> over the last 2d") || !strings.Contains(text, "hello from C001") {
+		t.Errorf("text = %s", text)
+	}
+	if len(requests) != 1 || requests[0].Limit != 200 {
+		t.Fatalf("requests = %+v", requests)
+	}
+	wantOldest := time.Now().Add(-48 * time.Hour).Unix()
+	secs, _, _ := strings.Cut(requests[0].Oldest, ".")
+	if got, err := strconv.ParseInt(secs, 10, 64); err != nil || got < wantOldest-5 || got > wantOldest+5 {
+		t.Errorf("oldest = %q, want about %d", requests[0].Oldest, wantOldest)
+	}
+}
+
+func TestRenderPrompt_WeeklyDigest(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	var requests []*slackapi.GetConversationHistoryParameters
+	cleanup := setMockClient(historyPromptAPI(&requests))
+	defer cleanup()
+	defs, _ := loadPrompts()
+
+	text, err := renderPrompt(context.Background(), findPrompt(t, defs, "weekly_digest"), map[string]string{"channels": "C001, C002"})
+
+	if err != nil {
+		t.Fatalf("renderPrompt: %v", err)
+	}
+	if len(requests) != 2 || !strings.Contains(text, "## <#C002>") || !strings.Contains(text, "hello from C002") {
+		t.Errorf("requests = %d, text = %s", len(requests), text)
+	}
+}
+
+func TestRenderPrompt_MissingArgument(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	defs, _ := loadPrompts()
+
+	_, err := renderPrompt(context.Background(), findPrompt(t, defs, "draft_reply"), map[string]string{"channel": "C001"})
+
+	if err == nil || !strings.Contains(err.Error(), "missing required argument thread_ts") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestCallPromptTool(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	var requests []*slackapi.GetConversationHistoryParameters
+	cleanup := setMockClient(historyPromptAPI(&requests))
+	defer cleanup()
+
+	if _, err := callPromptTool(context.Background(), "slack_post_message", []any{"channel_id", "C001"}); err == nil || !strings.Contains(err.Error(), "not available") {
+		t.Errorf("post err = %v", err)
+	}
+	if _, err := callPromptTool(context.Background(), "slack_get_channel_history", []any{"channel_id"}); err == nil {
+		t.Error("expected error for odd arguments")
+	}
+	// mark_read is dropped, so MarkConversation (unset in the mock) is never called.
+	text, err := callPromptTool(context.Background(), "slack_get_channel_history", []any{"channel_id", "C001", "mark_read", true})
+	if err != nil || !strings.Contains(text, "hello from C001") {
+		t.Errorf("text = %q, err = %v", text, err)
+	}
+}
+
+func TestCallPromptTool_ToolError(t *testing.T) {
+	writeSafeguardConfig(t, `{"inbound": {"never_expose": ["C001"]}}`)
+	cleanup := setMockClient(&slackutil.MockSlackAPI{})
+	defer cleanup()
+
+	_, err := callPromptTool(context.Background(), "slack_get_channel_history", []any{"channel_id", "C001"})
+
+	if err == nil || !strings.Contains(err.Error(), "not exposed") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestParseAgo(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"24h", 24 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"d", 0, true},
+		{"-1h", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseAgo(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseAgo(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
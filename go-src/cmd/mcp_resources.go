@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	slackapi "github.com/slack-go/slack"
+
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+)
+
+const (
+	channelHistoryURITemplate = "slack://channel/{id}/history"
+	threadURITemplate         = "slack://thread/{channel}/{ts}"
+
+	// resourceHistoryLimit is the number of messages a channel history
+	// resource holds.
+	resourceHistoryLimit = 50
+)
+
+var (
+	reChannelHistoryURI = regexp.MustCompile(`^slack://channel/([^/]+)/history$`)
+	reThreadURI         = regexp.MustCompile(`^slack://thread/([^/]+)/([^/]+)$`)
+)
+
+// parseResourceURI returns the channel and, for a thread resource, the
+// thread_ts a slamy resource URI refers to.
+func parseResourceURI(uri string) (channelID, threadTS string, err error) {
+	if m := reChannelHistoryURI.FindStringSubmatch(uri); m != nil {
+		return m[1], "", nil
+	}
+	if m := reThreadURI.FindStringSubmatch(uri); m != nil {
+		return m[1], m[2], nil
+	}
+	return "", "", fmt.Errorf("unknown resource %s", uri)
+}
+
+func registerMCPResources(s *server.MCPServer) {
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(channelHistoryURITemplate, "Channel history",
+			mcp.WithTemplateDescription(fmt.Sprintf("The latest %d messages in a channel, newest first", resourceHistoryLimit)),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		handleReadResource,
+	)
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(threadURITemplate, "Thread",
+			mcp.WithTemplateDescription("A thread's parent message and replies, oldest first"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		handleReadResource,
+	)
+}
+
+// handleReadResource reads a channel history or thread resource.
+func handleReadResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	client, err := getClientFunc()
+	if err != nil {
+		return nil, err
+	}
+	uri := request.Params.URI
+	channelID, threadTS, err := parseResourceURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkChannelExposed(channelID); err != nil {
+		return nil, err
+	}
+
+	var msgs []slackapi.Message
+	if threadTS == "" {
+		resp, err := client.User.GetConversationHistory(&slackapi.GetConversationHistoryParameters{ChannelID: channelID, Limit: resourceHistoryLimit})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get history: %w", err)
+		}
+		msgs = resp.Messages
+	} else {
+		msgs, _, _, err = client.User.GetConversationReplies(&slackapi.GetConversationRepliesParameters{ChannelID: channelID, Timestamp: threadTS, Limit: unreadPageSize})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get replies: %w", err)
+		}
+	}
+	return resourceJSON(uri, slackutil.NewMessages(msgs))
+}
+
+// resourceJSON is jsonResult for resources: it redacts v and returns it as
+// the JSON contents of uri.
+func resourceJSON(uri string, v any) ([]mcp.ResourceContents, error) {
+	redactor, err := outputRedactor()
+	if err != nil {
+		return nil, err
+	}
+	v, err = redactor.Redact(v)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("JSON marshal error: %w", err)
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(b)}}, nil
+}
+
+// resourceSubscriptions holds the resources the client has subscribed to,
+// each with the newest message seen in it.
+type resourceSubscriptions struct {
+	mu     sync.Mutex
+	latest map[string]string
+	now    func() time.Time
+}
+
+func newResourceSubscriptions() *resourceSubscriptions {
+	return &resourceSubscriptions{latest: map[string]string{}, now: time.Now}
+}
+
+// subscribe starts watching uri for messages posted from now on.
+// Subscribing again to the same resource keeps its position.
+func (s *resourceSubscriptions) subscribe(uri string) error {
+	channelID, _, err := parseResourceURI(uri)
+	if err != nil {
+		return err
+	}
+	if err := checkChannelExposed(channelID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.latest[uri]; !ok {
+		s.latest[uri] = fmt.Sprintf("%d.000000", s.now().Unix())
+	}
+	return nil
+}
+
+func (s *resourceSubscriptions) unsubscribe(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.latest, uri)
+}
+
+// poll returns the subscribed resources with messages newer than the last
+// poll. It checks every resource even if some fail, and returns the last
+// error.
+func (s *resourceSubscriptions) poll(api slackutil.SlackAPI) ([]string, error) {
+	s.mu.Lock()
+	latest := make(map[string]string, len(s.latest))
+	for uri, ts := range s.latest {
+		latest[uri] = ts
+	}
+	s.mu.Unlock()
+
+	var updated []string
+	var pollErr error
+	for uri, last := range latest {
+		newest, err := newestMessage(api, uri, last)
+		if err != nil {
+			pollErr = err
+			continue
+		}
+		if newest == "" {
+			continue
+		}
+		s.mu.Lock()
+		if _, ok := s.latest[uri]; ok {
+			s.latest[uri] = newest
+			updated = append(updated, uri)
+		}
+		s.mu.Unlock()
+	}
+	return updated, pollErr
+}
+
+// newestMessage returns the ts of the newest message in the resource uri
+// after the ts last, or "" if there is none.
+func newestMessage(api slackutil.SlackAPI, uri, last string) (string, error) {
+	channelID, threadTS, err := parseResourceURI(uri)
+	if err != nil {
+		return "", err
+	}
+	if threadTS != "" {
+		replies, err := newReplies(api, channelID, threadTS, last)
+		if err != nil || len(replies) == 0 {
+			return "", err
+		}
+		return replies[len(replies)-1].Timestamp, nil
+	}
+	resp, err := api.GetConversationHistory(&slackapi.GetConversationHistoryParameters{ChannelID: channelID, Oldest: last, Limit: 1})
+	if err != nil {
+		return "", fmt.Errorf("failed to get history of %s: %w", channelID, err)
+	}
+	if len(resp.Messages) == 0 {
+		return "", nil
+	}
+	return resp.Messages[0].Timestamp, nil
+}
+
+// runResourcePoller polls the subscribed resources every interval until
+// done is closed, sending notifications/resources/updated for each one that
+// changed. Like watch, it backs off while polls fail.
+func runResourcePoller(s *server.MCPServer, subs *resourceSubscriptions, interval time.Duration, done <-chan struct{}, logger *log.Logger) {
+	delay := interval
+	for {
+		select {
+		case <-done:
+			return
+		case <-time.After(delay):
+		}
+		client, err := getClientFunc()
+		if err != nil {
+			logger.Printf("resource poll failed: %v", err)
+			delay = watchInterval(interval, delay, err)
+			continue
+		}
+		updated, err := subs.poll(client.User)
+		if err != nil {
+			logger.Printf("resource poll failed: %v", err)
+		}
+		for _, uri := range updated {
+			s.SendNotificationToAllClients(mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+		}
+		delay = watchInterval(interval, delay, err)
+	}
+}
+
+// syncWriter serializes writes, so responses written by subscriptionReader
+// do not interleave with the stdio server's.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// subscriptionReader passes the client's messages on to the stdio server,
+// answering resources/subscribe and resources/unsubscribe itself, since
+// mcp-go does not route them to the server.
+type subscriptionReader struct {
+	in   *bufio.Reader
+	out  io.Writer
+	subs *resourceSubscriptions
+	buf  []byte
+	err  error
+}
+
+func newSubscriptionReader(in io.Reader, out io.Writer, subs *resourceSubscriptions) *subscriptionReader {
+	return &subscriptionReader{in: bufio.NewReader(in), out: out, subs: subs}
+}
+
+func (r *subscriptionReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		line, err := r.in.ReadBytes('\n')
+		r.err = err
+		if len(line) > 0 && !r.intercept(line) {
+			r.buf = line
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// intercept answers line if it is a subscription request, reporting
+// whether it did.
+func (r *subscriptionReader) intercept(line []byte) bool {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if json.Unmarshal(line, &req) != nil || len(req.ID) == 0 {
+		return false
+	}
+
+	var err error
+	switch req.Method {
+	case "resources/subscribe":
+		err = r.subs.subscribe(req.Params.URI)
+	case "resources/unsubscribe":
+		r.subs.unsubscribe(req.Params.URI)
+	default:
+		return false
+	}
+
+	resp := map[string]any{"jsonrpc": mcp.JSONRPC_VERSION, "id": req.ID}
+	if err != nil {
+		resp["error"] = mcp.JSONRPCErrorDetails{Code: mcp.INVALID_PARAMS, Message: err.Error()}
+	} else {
+		resp["result"] = mcp.EmptyResult{}
+	}
+	b, _ := json.Marshal(resp)
+	r.out.Write(append(b, '\n'))
+	return true
+}
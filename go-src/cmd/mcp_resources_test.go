@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	slackapi "github.com/slack-go/slack"
+
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+)
+
+func TestParseResourceURI(t *testing.T) {
+	tests := []struct {
+		uri, channel, thread string
+		wantErr              bool
+	}{
+		{"slack://channel/C001/history", "C001", "", false},
+		{"slack://thread/C001/1700000000.000100", "C001", "1700000000.000100", false},
+		{"slack://channel/C001", "", "", true},
+		{"https://example.com/C001", "", "", true},
+	}
+	for _, tt := range tests {
+		channel, thread, err := parseResourceURI(tt.uri)
+		if (err != nil) != tt.wantErr || channel != tt.channel || thread != tt.thread {
+			t.Errorf("parseResourceURI(%q) = %q, %q, %v", tt.uri, channel, thread, err)
+		}
+	}
+}
+
+func TestHandleReadResource_Thread(t *testing.T) {
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		GetConversationRepliesFunc: func(params *slackapi.GetConversationRepliesParameters) ([]slackapi.Message, bool, string, error) {
+			if params.ChannelID != "C001" || params.Timestamp != "100.0" {
+				t.Errorf("params = %+v", params)
+			}
+			return []slackapi.Message{watchMsg("100.0", "100.0", "root"), watchMsg("200.0", "100.0", "reply")}, false, "", nil
+		},
+	})
+	defer cleanup()
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = "slack://thread/C001/100.0"
+
+	contents, err := handleReadResource(context.Background(), request)
+
+	if err != nil {
+		t.Fatalf("handleReadResource: %v", err)
+	}
+	text, ok := contents[0].(mcp.TextResourceContents)
+	if len(contents) != 1 || !ok || text.URI != request.Params.URI || text.MIMEType != "application/json" {
+		t.Fatalf("contents = %+v", contents)
+	}
+	var msgs []slackutil.Message
+	if err := json.Unmarshal([]byte(text.Text), &msgs); err != nil {
+		t.Fatalf("failed to parse resource JSON: %v", err)
+	}
+	if len(msgs) != 2 || msgs[1].Text != "reply" {
+		t.Errorf("msgs = %+v", msgs)
+	}
+}
+
+func TestHandleReadResource_NeverExposed(t *testing.T) {
+	writeSafeguardConfig(t, `{"inbound": {"never_expose": ["C001"]}}`)
+	cleanup := setMockClient(&slackutil.MockSlackAPI{})
+	defer cleanup()
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = "slack://channel/C001/history"
+
+	if _, err := handleReadResource(context.Background(), request); err == nil || !strings.Contains(err.Error(), "not exposed") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestResourceSubscriptions_Poll(t *testing.T) {
+	subs := newResourceSubscriptions()
+	subs.now = func() time.Time { return time.Unix(100, 0) }
+	for _, uri := range []string{"slack://channel/C001/history", "slack://channel/C002/history", "slack://thread/C001/50.0"} {
+		if err := subs.subscribe(uri); err != nil {
+			t.Fatalf("subscribe(%s): %v", uri, err)
+		}
+	}
+	subs.unsubscribe("slack://channel/C002/history")
+	var oldest []string
+	api := &slackutil.MockSlackAPI{
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			oldest = append(oldest, params.ChannelID+">"+params.Oldest)
+			if params.Oldest == "100.000000" {
+				return &slackapi.GetConversationHistoryResponse{Messages: []slackapi.Message{watchMsg("150.0", "", "new")}}, nil
+			}
+			return &slackapi.GetConversationHistoryResponse{}, nil
+		},
+		GetConversationRepliesFunc: func(params *slackapi.GetConversationRepliesParameters) ([]slackapi.Message, bool, string, error) {
+			return nil, false, "", errors.New("thread_not_found")
+		},
+	}
+
+	updated, err := subs.poll(api)
+
+	if err == nil || !strings.Contains(err.Error(), "thread_not_found") {
+		t.Errorf("err = %v", err)
+	}
+	if strings.Join(updated, ",") != "slack://channel/C001/history" {
+		t.Errorf("updated = %v", updated)
+	}
+
+	updated, _ = subs.poll(api)
+
+	if len(updated) != 0 || strings.Join(oldest, ",") != "C001>100.000000,C001>150.0" {
+		t.Errorf("updated = %v, oldest = %v", updated, oldest)
+	}
+}
+
+func TestSubscriptionReader(t *testing.T) {
+	writeSafeguardConfig(t, `{"inbound": {"never_expose": ["C009"]}}`)
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"slack://channel/C001/history"}}`,
+		`{"jsonrpc":"2.0","id":"x","method":"resources/subscribe","params":{"uri":"slack://channel/C009/history"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/unsubscribe","params":{"uri":"slack://channel/C001/history"}}`,
+	}, "\n") + "\n"
+	var out bytes.Buffer
+	subs := newResourceSubscriptions()
+
+	passed, err := io.ReadAll(newSubscriptionReader(strings.NewReader(in), &out, subs))
+
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	want := `{"jsonrpc":"2.0","id":1,"method":"tools/list"}` + "\n" + `{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n"
+	if string(passed) != want {
+		t.Errorf("passed = %q", passed)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || lines[0] != `{"id":2,"jsonrpc":"2.0","result":{}}` || !strings.Contains(lines[1], `"id":"x"`) || !strings.Contains(lines[1], "not exposed") {
+		t.Errorf("responses = %q", lines)
+	}
+	if len(subs.latest) != 0 {
+		t.Errorf("subscriptions = %v", subs.latest)
+	}
+}
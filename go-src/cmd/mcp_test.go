@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -16,6 +17,21 @@ import (
 
 // ---------- helper ----------
 
+// TestMain points SLAMY_HOME at a temporary directory so that the audit log
+// and other local state written by the commands under test never touch the
+// user's real data directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "slamy-test-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Setenv("SLAMY_HOME", dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 // makeRequest builds a CallToolRequest with the given arguments map.
 func makeRequest(args map[string]any) mcp.CallToolRequest {
 	return mcp.CallToolRequest{
@@ -292,6 +308,147 @@ func TestHandleListChannels_ClientError(t *testing.T) {
 	}
 }
 
+// ---------- handleListUnread ----------
+
+func TestHandleListUnread_PriorityAndMessages(t *testing.T) {
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		AuthTestFunc: func() (*slackapi.AuthTestResponse, error) {
+			return &slackapi.AuthTestResponse{UserID: "UME"}, nil
+		},
+		GetConversationsForUserFunc: func(params *slackapi.GetConversationsForUserParameters) ([]slackapi.Channel, string, error) {
+			if !strings.Contains(strings.Join(params.Types, ","), "im") {
+				t.Errorf("Types = %v", params.Types)
+			}
+			var out []slackapi.Channel
+			for _, id := range []string{"C001", "C002", "D001", "C003"} {
+				ch := slackapi.Channel{}
+				ch.ID = id
+				out = append(out, ch)
+			}
+			return out, "", nil
+		},
+		GetConversationInfoFunc: func(input *slackapi.GetConversationInfoInput) (*slackapi.Channel, error) {
+			if input.ChannelID == "C003" {
+				return nil, fmt.Errorf("ratelimited")
+			}
+			ch := &slackapi.Channel{IsMember: true}
+			ch.ID, ch.Name, ch.LastRead = input.ChannelID, strings.ToLower(input.ChannelID), "1675382300.000000"
+			if input.ChannelID == "D001" {
+				ch.IsIM, ch.User = true, "U001"
+			}
+			return ch, nil
+		},
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			resp := &slackapi.GetConversationHistoryResponse{Messages: []slackapi.Message{
+				{Msg: slackapi.Msg{Timestamp: "1675382500.000000", User: "U001", Text: "newest"}},
+				{Msg: slackapi.Msg{Timestamp: "1675382400.000000", User: "U001", Text: "older"}},
+			}}
+			if params.ChannelID == "C002" {
+				resp.Messages[1].Text = "<@UME> please review"
+			}
+			return resp, nil
+		},
+	})
+	defer cleanup()
+
+	result, err := handleListUnread(context.Background(), makeRequest(map[string]any{"include_messages": true, "messages_per_channel": float64(1)}))
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []slackutil.UnreadChannel
+	if err := json.Unmarshal([]byte(resultText(t, result)), &got); err != nil {
+		t.Fatalf("failed to parse result JSON: %v", err)
+	}
+	var order []string
+	for _, ch := range got {
+		order = append(order, ch.ID)
+	}
+	if strings.Join(order, ",") != "D001,C002,C001,C003" {
+		t.Fatalf("order = %v", order)
+	}
+	if got[1].MentionCount != 1 || got[1].UnreadCount != 2 {
+		t.Errorf("C002 = %+v", got[1])
+	}
+	if len(got[0].Messages) != 1 || got[0].Messages[0].Text != "newest" {
+		t.Errorf("D001 messages = %+v", got[0].Messages)
+	}
+	if got[3].Error == "" || got[3].Messages != nil {
+		t.Errorf("C003 = %+v", got[3])
+	}
+}
+
+func TestHandleListUnread_MessagesPerChannelClamped(t *testing.T) {
+	var history []slackapi.Message
+	for i := 300; i > 0; i-- {
+		history = append(history, slackapi.Message{Msg: slackapi.Msg{Timestamp: fmt.Sprintf("1675382%03d.000000", i), User: "U001", Text: "msg"}})
+	}
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		AuthTestFunc: func() (*slackapi.AuthTestResponse, error) {
+			return &slackapi.AuthTestResponse{UserID: "UME"}, nil
+		},
+		GetConversationsForUserFunc: func(params *slackapi.GetConversationsForUserParameters) ([]slackapi.Channel, string, error) {
+			ch := slackapi.Channel{}
+			ch.ID = "C001"
+			return []slackapi.Channel{ch}, "", nil
+		},
+		GetConversationInfoFunc: func(input *slackapi.GetConversationInfoInput) (*slackapi.Channel, error) {
+			ch := &slackapi.Channel{IsMember: true}
+			ch.ID, ch.LastRead = input.ChannelID, "1675382000.000000"
+			return ch, nil
+		},
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			return &slackapi.GetConversationHistoryResponse{Messages: history}, nil
+		},
+	})
+	defer cleanup()
+
+	tests := []struct {
+		perChannel float64
+		want       int
+	}{
+		{-1, 0},
+		{0, 0},
+		{5, 5},
+		{500, unreadMaxMessages},
+	}
+	for _, tt := range tests {
+		result, err := handleListUnread(context.Background(), makeRequest(map[string]any{"include_messages": true, "messages_per_channel": tt.perChannel}))
+
+		if err != nil || isErrorResult(result) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got []slackutil.UnreadChannel
+		if err := json.Unmarshal([]byte(resultText(t, result)), &got); err != nil {
+			t.Fatalf("failed to parse result JSON: %v", err)
+		}
+		if len(got) != 1 || len(got[0].Messages) != tt.want || got[0].UnreadCount != 300 {
+			t.Errorf("messages_per_channel %v: got %d messages, unread %d, want %d messages", tt.perChannel, len(got[0].Messages), got[0].UnreadCount, tt.want)
+		}
+	}
+}
+
+func TestHandleListUnread_WithoutMessages(t *testing.T) {
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		AuthTestFunc: func() (*slackapi.AuthTestResponse, error) {
+			return &slackapi.AuthTestResponse{UserID: "UME"}, nil
+		},
+		GetConversationsForUserFunc: func(params *slackapi.GetConversationsForUserParameters) ([]slackapi.Channel, string, error) {
+			return nil, "", nil
+		},
+	})
+	defer cleanup()
+
+	result, err := handleListUnread(context.Background(), makeRequest(map[string]any{}))
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, result); text != "[]" {
+		t.Errorf("result = %q", text)
+	}
+}
+
 // ---------- handleGetChannelHistory ----------
 
 func TestHandleGetChannelHistory_Success(t *testing.T) {
@@ -321,6 +478,58 @@ func TestHandleGetChannelHistory_Success(t *testing.T) {
 	}
 }
 
+func TestHandleGetChannelHistory_RichMessage(t *testing.T) {
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			return &slackapi.GetConversationHistoryResponse{
+				Messages: []slackapi.Message{
+					{Msg: slackapi.Msg{
+						Timestamp:   "1675382400.000000",
+						BotID:       "B001",
+						Username:    "alert-bot",
+						SubType:     "bot_message",
+						Attachments: []slackapi.Attachment{{Text: "disk full"}},
+						Reactions:   []slackapi.ItemReaction{{Name: "eyes", Count: 1}},
+						Files:       []slackapi.File{{ID: "F001", Name: "df.txt"}},
+						Edited:      &slackapi.Edited{User: "U001", Timestamp: "1675382500.000000"},
+					}},
+				},
+			}, nil
+		},
+	})
+	defer cleanup()
+
+	req := makeRequest(map[string]any{"channel_id": "C001"})
+	result, err := handleGetChannelHistory(context.Background(), req)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var parsed []slackutil.Message
+	if jsonErr := json.Unmarshal([]byte(resultText(t, result)), &parsed); jsonErr != nil {
+		t.Fatalf("failed to parse JSON: %v", jsonErr)
+	}
+	if len(parsed) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(parsed))
+	}
+	msg := parsed[0]
+	if msg.Text != "disk full" {
+		t.Errorf("text = %q, want attachment fallback", msg.Text)
+	}
+	if msg.BotID != "B001" || msg.Username != "alert-bot" || msg.Subtype != "bot_message" {
+		t.Errorf("bot fields = %q/%q/%q", msg.BotID, msg.Username, msg.Subtype)
+	}
+	if len(msg.Reactions) != 1 || msg.Reactions[0].Name != "eyes" {
+		t.Errorf("reactions = %+v", msg.Reactions)
+	}
+	if len(msg.Files) != 1 || msg.Files[0].ID != "F001" {
+		t.Errorf("files = %+v", msg.Files)
+	}
+	if msg.Edited == nil {
+		t.Error("expected edited marker")
+	}
+}
+
 func TestHandleGetChannelHistory_MissingChannelID(t *testing.T) {
 	cleanup := setMockClient(&slackutil.MockSlackAPI{})
 	defer cleanup()
@@ -531,6 +740,192 @@ func TestHandlePostMessage_ThreadReplyError(t *testing.T) {
 	}
 }
 
+// msgValues applies message options and returns the resulting form values.
+func msgValues(t *testing.T, options ...slackapi.MsgOption) map[string]string {
+	t.Helper()
+	_, values, err := slackapi.UnsafeApplyMsgOptions("", "C001", "", options...)
+	if err != nil {
+		t.Fatalf("apply options: %v", err)
+	}
+	got := map[string]string{}
+	for k := range values {
+		got[k] = values.Get(k)
+	}
+	return got
+}
+
+func TestHandlePostMessage_BlocksFormat(t *testing.T) {
+	var posted []map[string]string
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			posted = append(posted, msgValues(t, options...))
+			return channelID, "1675382400.000000", nil
+		},
+	})
+	defer cleanup()
+
+	req := makeRequest(map[string]any{
+		"channel_id": "C001",
+		"text":       "# Status\n\n- **done**\n- pending",
+		"format":     "blocks",
+	})
+	result, err := handlePostMessage(context.Background(), req)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if isErrorResult(result) {
+		t.Fatalf("unexpected error result: %s", resultText(t, result))
+	}
+	if len(posted) != 1 {
+		t.Fatalf("expected 1 post, got %d", len(posted))
+	}
+	if !strings.Contains(posted[0]["blocks"], `"type":"header"`) || !strings.Contains(posted[0]["blocks"], `"type":"rich_text_list"`) {
+		t.Errorf("unexpected blocks: %s", posted[0]["blocks"])
+	}
+	if posted[0]["text"] != "*Status*\n\n• *done*\n• pending" {
+		t.Errorf("fallback text = %q", posted[0]["text"])
+	}
+}
+
+func TestHandlePostMessage_BlocksOverflowThreaded(t *testing.T) {
+	var posted []map[string]string
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			posted = append(posted, msgValues(t, options...))
+			return channelID, "1675382400.000000", nil
+		},
+	})
+	defer cleanup()
+
+	text := strings.Repeat("# h\n", slackutil.MaxBlocksPerMessage+1)
+	req := makeRequest(map[string]any{"channel_id": "C001", "text": text, "format": "blocks"})
+	result, err := handlePostMessage(context.Background(), req)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if isErrorResult(result) {
+		t.Fatalf("unexpected error result: %s", resultText(t, result))
+	}
+	if len(posted) != 2 {
+		t.Fatalf("expected 2 posts, got %d", len(posted))
+	}
+	if posted[0]["thread_ts"] != "" || posted[1]["thread_ts"] != "1675382400.000000" {
+		t.Errorf("thread_ts = %q, %q", posted[0]["thread_ts"], posted[1]["thread_ts"])
+	}
+}
+
+func TestHandlePostMessage_InvalidFormat(t *testing.T) {
+	cleanup := setMockClient(&slackutil.MockSlackAPI{})
+	defer cleanup()
+
+	req := makeRequest(map[string]any{"channel_id": "C001", "text": "hi", "format": "html"})
+	result, err := handlePostMessage(context.Background(), req)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !isErrorResult(result) {
+		t.Error("expected error result for unknown format")
+	}
+}
+
+func TestHandlePostMessage_IdempotencyKeyReturnsOriginal(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	posts := 0
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		AuthTestFunc: func() (*slackapi.AuthTestResponse, error) {
+			return &slackapi.AuthTestResponse{UserID: "U001"}, nil
+		},
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			return &slackapi.GetConversationHistoryResponse{}, nil
+		},
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			posts++
+			return channelID, "1675382400.000000", nil
+		},
+	})
+	defer cleanup()
+
+	req := makeRequest(map[string]any{"channel_id": "C001", "text": "deploy done", "idempotency_key": "deploy-42"})
+	first, err := handlePostMessage(context.Background(), req)
+	if err != nil || isErrorResult(first) {
+		t.Fatalf("first post failed: %v", err)
+	}
+	second, err := handlePostMessage(context.Background(), req)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if posts != 1 {
+		t.Errorf("expected 1 post, got %d", posts)
+	}
+	var got slackutil.PostResult
+	if err := json.Unmarshal([]byte(resultText(t, second)), &got); err != nil {
+		t.Fatalf("failed to parse result JSON: %v", err)
+	}
+	if got.TS != "1675382400.000000" || !got.Duplicate {
+		t.Errorf("result = %+v, want the original message", got)
+	}
+}
+
+func TestHandlePostMessage_IdempotencyKeyFindsRecentDuplicate(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		AuthTestFunc: func() (*slackapi.AuthTestResponse, error) {
+			return &slackapi.AuthTestResponse{UserID: "U001"}, nil
+		},
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			return &slackapi.GetConversationHistoryResponse{Messages: []slackapi.Message{
+				{Msg: slackapi.Msg{User: "U001", Timestamp: "1675382300.000000", Text: "deploy done"}},
+			}}, nil
+		},
+	})
+	defer cleanup()
+
+	req := makeRequest(map[string]any{"channel_id": "C001", "text": "deploy done", "idempotency_key": "deploy-43"})
+	result, err := handlePostMessage(context.Background(), req)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(t, result)
+	if !strings.Contains(text, `"ts": "1675382300.000000"`) || !strings.Contains(text, `"duplicate": true`) {
+		t.Errorf("unexpected result: %s", text)
+	}
+}
+
+func TestHandlePostMessage_DryRun(t *testing.T) {
+	// Any Slack call panics: the mock has no functions set.
+	cleanup := setMockClient(&slackutil.MockSlackAPI{})
+	defer cleanup()
+
+	req := makeRequest(map[string]any{
+		"channel_id":           "C001",
+		"text":                 strings.Repeat("a", 3000) + "\n\n" + strings.Repeat("b", 3000),
+		"continuation_markers": true,
+		"idempotency_key":      "k",
+		"queue":                true,
+		"dry_run":              true,
+	})
+	result, err := handlePostMessage(context.Background(), req)
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var plan slackutil.PostPlan
+	if err := json.Unmarshal([]byte(resultText(t, result)), &plan); err != nil {
+		t.Fatalf("failed to parse result JSON: %v", err)
+	}
+	if plan.Channel != "C001" || len(plan.Messages) != 2 {
+		t.Fatalf("plan = %+v", plan)
+	}
+	if m := plan.Messages[1]; m.Placement != "thread of message 1" || !strings.HasSuffix(m.Text, "(2/2)") || m.Runes != len([]rune(m.Text)) {
+		t.Errorf("second message = %+v", m)
+	}
+}
+
 // ---------- handleReplyToThread ----------
 
 func TestHandleReplyToThread_Success(t *testing.T) {
@@ -605,6 +1000,102 @@ func TestHandleReplyToThread_MissingText(t *testing.T) {
 	}
 }
 
+func TestHandleReplyToThread_LongTextSplitsInThread(t *testing.T) {
+	var posted []map[string]string
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			posted = append(posted, msgValues(t, options...))
+			return channelID, fmt.Sprintf("1675382500.00000%d", len(posted)), nil
+		},
+	})
+	defer cleanup()
+
+	longText := strings.Repeat("a", 3000) + "\n\n" + strings.Repeat("b", 3000)
+	req := makeRequest(map[string]any{
+		"channel_id":           "C001",
+		"thread_ts":            "1675382400.000000",
+		"text":                 longText,
+		"continuation_markers": true,
+	})
+	result, err := handleReplyToThread(context.Background(), req)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if isErrorResult(result) {
+		t.Fatalf("unexpected error result: %s", resultText(t, result))
+	}
+	if len(posted) != 2 {
+		t.Fatalf("expected 2 posts, got %d", len(posted))
+	}
+	for i, p := range posted {
+		if p["thread_ts"] != "1675382400.000000" {
+			t.Errorf("post %d thread_ts = %q", i, p["thread_ts"])
+		}
+		if !strings.HasSuffix(p["text"], fmt.Sprintf("(%d/2)", i+1)) {
+			t.Errorf("post %d missing continuation marker", i)
+		}
+	}
+}
+
+func TestHandleReplyToThread_BroadcastFirstChunkAndTimestamps(t *testing.T) {
+	var posted []map[string]string
+	cleanup := setMockClient(&slackutil.MockSlackAPI{
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			posted = append(posted, msgValues(t, options...))
+			return channelID, fmt.Sprintf("1675382500.00000%d", len(posted)), nil
+		},
+	})
+	defer cleanup()
+
+	longText := strings.Repeat("a", 3000) + "\n\n" + strings.Repeat("b", 3000)
+	req := makeRequest(map[string]any{
+		"channel_id": "C001",
+		"thread_ts":  "1675382400.000000",
+		"text":       longText,
+		"broadcast":  true,
+	})
+	result, err := handleReplyToThread(context.Background(), req)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(posted) != 2 {
+		t.Fatalf("expected 2 posts, got %d", len(posted))
+	}
+	if posted[0]["reply_broadcast"] != "true" || posted[1]["reply_broadcast"] != "" {
+		t.Errorf("reply_broadcast = %q, %q", posted[0]["reply_broadcast"], posted[1]["reply_broadcast"])
+	}
+
+	var got slackutil.PostResult
+	if err := json.Unmarshal([]byte(resultText(t, result)), &got); err != nil {
+		t.Fatalf("failed to parse result JSON: %v", err)
+	}
+	if strings.Join(got.Timestamps, ",") != "1675382500.000001,1675382500.000002" {
+		t.Errorf("timestamps = %v", got.Timestamps)
+	}
+}
+
+func TestHandleReplyToThread_InvalidOverflow(t *testing.T) {
+	cleanup := setMockClient(&slackutil.MockSlackAPI{})
+	defer cleanup()
+
+	req := makeRequest(map[string]any{
+		"channel_id": "C001",
+		"thread_ts":  "1675382400.000000",
+		"text":       "hi",
+		"overflow":   "drop",
+	})
+	result, err := handleReplyToThread(context.Background(), req)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !isErrorResult(result) {
+		t.Error("expected error result for unknown overflow")
+	}
+}
+
 // ---------- handleAddReaction ----------
 
 func TestHandleAddReaction_Success(t *testing.T) {
@@ -681,6 +1172,22 @@ func TestHandleAddReaction_MissingReaction(t *testing.T) {
 	}
 }
 
+func TestHandleAddReaction_DryRun(t *testing.T) {
+	// Any Slack call panics: the mock has no functions set.
+	cleanup := setMockClient(&slackutil.MockSlackAPI{})
+	defer cleanup()
+
+	req := makeRequest(map[string]any{"channel_id": "C001", "timestamp": "1.0", "reaction": "eyes", "dry_run": true})
+	result, err := handleAddReaction(context.Background(), req)
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, result); !strings.Contains(text, `"dry_run": true`) {
+		t.Errorf("unexpected result: %s", text)
+	}
+}
+
 // ---------- handleGetUsers ----------
 
 func TestHandleGetUsers_Success(t *testing.T) {
@@ -791,8 +1298,9 @@ func TestHandleGetUserProfile_Success(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	text := resultText(t, result)
-	if !strings.Contains(text, "alice@example.com") {
-		t.Errorf("expected email in result, got %q", text)
+	// Inbound redaction removes emails and phone numbers by default.
+	if strings.Contains(text, "alice@example.com") || !strings.Contains(text, `"email": "[REDACTED:email]"`) {
+		t.Errorf("expected redacted email in result, got %q", text)
 	}
 	if !strings.Contains(text, "Alice Smith") {
 		t.Errorf("expected real_name in result, got %q", text)
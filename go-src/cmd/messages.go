@@ -2,15 +2,389 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/tackeyy/slamy/internal/output"
 	slackutil "github.com/tackeyy/slamy/internal/slack"
+	"github.com/tackeyy/slamy/internal/state"
 
-	"github.com/slack-go/slack"
 	"github.com/spf13/cobra"
 )
 
+// addPostFlags registers the flags shared by commands that post text.
+func addPostFlags(cmd *cobra.Command) {
+	cmd.Flags().String("format", slackutil.FormatMrkdwn, "Message format: mrkdwn|blocks (Markdown converted to mrkdwn text or Block Kit)")
+	cmd.Flags().Bool("markdown-blocks", false, "Post Markdown as Block Kit blocks (same as --format blocks)")
+	cmd.Flags().String("overflow", slackutil.OverflowThread, "How to post text longer than one message: "+strings.Join(slackutil.OverflowStrategies, "|"))
+	cmd.Flags().Bool("continuation-markers", false, "Append (1/3)-style markers when text is posted as several messages")
+	cmd.Flags().String("idempotency-key", "", "Client-supplied key; retries with the same key return the original message instead of posting again")
+	cmd.Flags().Duration("dedup-window", slackutil.DefaultDedupWindow, "With --idempotency-key, also treat an identical message you posted this recently as already sent (0 disables)")
+	cmd.Flags().Bool("queue", false, "Add the message to the local outbox and keep it there for retry if it cannot be posted now")
+}
+
+// addTextSourceFlags registers the flags that read message text from a
+// file or template, alongside --text.
+func addTextSourceFlags(cmd *cobra.Command) {
+	cmd.Flags().String("file", "", "Read the message text from a file (- for stdin)")
+	cmd.Flags().String("text-template", "", "Render the message text from a Go text/template file (--template formats command output)")
+	cmd.Flags().StringArray("var", nil, "Template variable as key=value (repeatable)")
+	cmd.Flags().String("vars", "", "JSON file of template variables (--var takes precedence)")
+}
+
+// messageText returns the message text from --text, --file or
+// --text-template. "-" reads --text or --file from stdin.
+func messageText(cmd *cobra.Command, api slackutil.SlackAPI) (string, error) {
+	text, err := cmd.Flags().GetString("text")
+	if err != nil {
+		return "", fmt.Errorf("failed to get text flag: %w", err)
+	}
+	file, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return "", fmt.Errorf("failed to get file flag: %w", err)
+	}
+	tmplFile, err := cmd.Flags().GetString("text-template")
+	if err != nil {
+		return "", fmt.Errorf("failed to get text-template flag: %w", err)
+	}
+	vars, err := templateVars(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	sources := 0
+	for _, s := range []string{text, file, tmplFile} {
+		if s != "" {
+			sources++
+		}
+	}
+	switch {
+	case sources == 0:
+		return "", fmt.Errorf("--text, --file or --text-template is required")
+	case sources > 1:
+		return "", fmt.Errorf("only one of --text, --file and --text-template may be used")
+	case vars != nil && tmplFile == "":
+		return "", fmt.Errorf("--var and --vars require --text-template")
+	}
+
+	switch {
+	case text == "-" || file == "-":
+		b, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return "", fmt.Errorf("failed to read stdin: %w", err)
+		}
+		text = string(b)
+	case file != "":
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read message file: %w", err)
+		}
+		text = string(b)
+	case tmplFile != "":
+		b, err := os.ReadFile(tmplFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read template: %w", err)
+		}
+		text, err = slackutil.RenderTemplate(api, filepath.Base(tmplFile), string(b), vars)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	text = strings.TrimRight(text, "\r\n")
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("message text is empty")
+	}
+	return text, nil
+}
+
+// templateVars reads --vars and --var. It returns nil when neither is set.
+func templateVars(cmd *cobra.Command) (map[string]any, error) {
+	varsFile, err := cmd.Flags().GetString("vars")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vars flag: %w", err)
+	}
+	pairs, err := cmd.Flags().GetStringArray("var")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get var flag: %w", err)
+	}
+	if varsFile == "" && len(pairs) == 0 {
+		return nil, nil
+	}
+
+	vars := map[string]any{}
+	if varsFile != "" {
+		b, err := os.ReadFile(varsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vars file: %w", err)
+		}
+		if err := json.Unmarshal(b, &vars); err != nil {
+			return nil, fmt.Errorf("failed to parse vars file: %w", err)
+		}
+	}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --var %q (want key=value)", pair)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// sendMessage posts text, or queues it with --queue, and renders the result.
+func sendMessage(cmd *cobra.Command, api slackutil.SlackAPI, channelID, text string, opts slackutil.PostOptions) error {
+	key, window, err := idempotencyFlags(cmd)
+	if err != nil {
+		return err
+	}
+	queue, err := cmd.Flags().GetBool("queue")
+	if err != nil {
+		return fmt.Errorf("failed to get queue flag: %w", err)
+	}
+
+	if dryRun {
+		plan, err := planMessage(channelID, text, opts)
+		if err != nil {
+			return err
+		}
+		return render(postPlanView(plan))
+	}
+
+	if queue {
+		result, err := queueMessage(api, channelID, text, opts, key, window)
+		if err != nil {
+			return err
+		}
+		return render(output.View{
+			Data:    result,
+			Columns: []string{"id", "channel", "status", "ts"},
+			Text: func(w io.Writer) error {
+				return writeOutboxResult(w, *result)
+			},
+		})
+	}
+
+	result, err := postMessage(api, channelID, text, opts, key, window)
+	if err != nil {
+		return err
+	}
+	return render(postResultView(result))
+}
+
+// idempotencyFlags reads --idempotency-key and --dedup-window.
+func idempotencyFlags(cmd *cobra.Command) (string, time.Duration, error) {
+	key, err := cmd.Flags().GetString("idempotency-key")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get idempotency-key flag: %w", err)
+	}
+	window, err := cmd.Flags().GetDuration("dedup-window")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get dedup-window flag: %w", err)
+	}
+	return key, window, nil
+}
+
+// postMessage posts text with slackutil.PostText after applying the
+// outbound safeguards. When key is set, a key that was already used, or an
+// identical message posted by the same user within window, returns the
+// earlier message instead of posting again.
+func postMessage(api slackutil.SlackAPI, channelID, text string, opts slackutil.PostOptions, key string, window time.Duration) (*slackutil.PostResult, error) {
+	text, warnings, err := guardText(channelID, text)
+	if err != nil {
+		return nil, err
+	}
+	result, err := postGuardedMessage(api, channelID, text, opts, key, window)
+	if result != nil {
+		result.Warnings = warnings
+	}
+	return result, err
+}
+
+// postGuardedMessage is postMessage for text that already passed the
+// safeguards.
+func postGuardedMessage(api slackutil.SlackAPI, channelID, text string, opts slackutil.PostOptions, key string, window time.Duration) (*slackutil.PostResult, error) {
+	if key == "" {
+		result, err := slackutil.PostText(api, channelID, text, opts)
+		recordPost(channelID, text, opts, result)
+		return result, err
+	}
+
+	store, err := state.OpenIdempotencyStore()
+	if err != nil {
+		return nil, err
+	}
+	rec, err := reserveIdempotencyKey(store, key, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if rec != nil {
+		return &slackutil.PostResult{
+			Channel:    rec.Channel,
+			TS:         rec.TS,
+			ThreadTS:   rec.ThreadTS,
+			Timestamps: rec.Timestamps,
+			Duplicate:  true,
+		}, nil
+	}
+
+	result, err := slackutil.FindDuplicate(api, channelID, text, opts, window)
+	if err != nil {
+		return nil, errors.Join(err, store.Release(key))
+	}
+	if result == nil {
+		result, err = slackutil.PostText(api, channelID, text, opts)
+		recordPost(channelID, text, opts, result)
+		if err != nil {
+			return result, errors.Join(err, store.Release(key))
+		}
+	}
+
+	err = store.Put(key, state.IdempotencyRecord{
+		Channel:    result.Channel,
+		TS:         result.TS,
+		ThreadTS:   result.ThreadTS,
+		Timestamps: result.Timestamps,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to record idempotency key: %w", err)
+	}
+	return result, nil
+}
+
+// idempotencyPendingWait is how long a post waits for another request that
+// is posting with the same idempotency key.
+const idempotencyPendingWait = 30 * time.Second
+
+// reserveIdempotencyKey reserves key for channelID, waiting while another
+// request with the same key is still posting so that a retry returns the
+// message that request posted.
+func reserveIdempotencyKey(store *state.IdempotencyStore, key, channelID string) (*state.IdempotencyRecord, error) {
+	deadline := time.Now().Add(idempotencyPendingWait)
+	for {
+		rec, err := store.Reserve(key, channelID)
+		if !errors.Is(err, state.ErrIdempotencyPending) {
+			return rec, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("idempotency key %q: %w", key, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// postOptions reads the flags registered by addPostFlags.
+func postOptions(cmd *cobra.Command) (slackutil.PostOptions, error) {
+	var opts slackutil.PostOptions
+
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return opts, fmt.Errorf("failed to get format flag: %w", err)
+	}
+	markdownBlocks, err := cmd.Flags().GetBool("markdown-blocks")
+	if err != nil {
+		return opts, fmt.Errorf("failed to get markdown-blocks flag: %w", err)
+	}
+	if markdownBlocks {
+		format = slackutil.FormatBlocks
+	}
+	overflow, err := cmd.Flags().GetString("overflow")
+	if err != nil {
+		return opts, fmt.Errorf("failed to get overflow flag: %w", err)
+	}
+	markers, err := cmd.Flags().GetBool("continuation-markers")
+	if err != nil {
+		return opts, fmt.Errorf("failed to get continuation-markers flag: %w", err)
+	}
+
+	opts = slackutil.PostOptions{Format: format, Overflow: overflow, Markers: markers}
+	return opts, opts.Validate()
+}
+
+// postResultView renders the result of posting a message.
+func postResultView(result *slackutil.PostResult) output.View {
+	return output.View{
+		Data:    result,
+		Columns: []string{"channel", "ts", "thread_ts"},
+		Text: func(w io.Writer) error {
+			var err error
+			switch {
+			case result.Duplicate:
+				_, err = fmt.Fprintf(w, "Message already posted to %s (ts: %s)\n", result.Channel, postedTimestamps(result))
+			case result.FileID != "":
+				_, err = fmt.Fprintf(w, "Message uploaded to %s as file %s\n", result.Channel, result.FileID)
+			case result.ThreadTS != "":
+				_, err = fmt.Fprintf(w, "Reply posted to %s thread %s (ts: %s)\n", result.Channel, result.ThreadTS, postedTimestamps(result))
+			default:
+				_, err = fmt.Fprintf(w, "Message posted to %s (ts: %s)\n", result.Channel, postedTimestamps(result))
+			}
+			for _, warning := range result.Warnings {
+				if err == nil {
+					_, err = fmt.Fprintf(w, "Warning: %s\n", warning)
+				}
+			}
+			return err
+		},
+	}
+}
+
+// postPlanView renders what a dry run would post.
+func postPlanView(plan *slackutil.PostPlan) output.View {
+	return output.View{
+		Data:    plan,
+		Columns: []string{"channel", "thread_ts", "format", "overflow"},
+		Text: func(w io.Writer) error {
+			fmt.Fprintln(w, "Dry run: nothing was sent")
+			channel := plan.Channel
+			if channel == "" {
+				channel = "(unresolved)"
+			}
+			fmt.Fprintf(w, "Channel:  %s\n", channel)
+			if plan.ThreadTS != "" {
+				broadcast := ""
+				if plan.Broadcast {
+					broadcast = " (broadcast)"
+				}
+				fmt.Fprintf(w, "Thread:   %s%s\n", plan.ThreadTS, broadcast)
+			}
+			fmt.Fprintf(w, "Format:   %s\n", plan.Format)
+			fmt.Fprintf(w, "Overflow: %s\n", plan.Overflow)
+			if plan.Upload != nil {
+				fmt.Fprintf(w, "Upload:   %s (%d bytes)\n", plan.Upload.Filename, plan.Upload.Bytes)
+			}
+			for _, warning := range plan.Warnings {
+				fmt.Fprintf(w, "Warning:  %s\n", warning)
+			}
+			fmt.Fprintf(w, "\n--- mrkdwn ---\n%s\n", plan.Mrkdwn)
+			for i, m := range plan.Messages {
+				details := fmt.Sprintf("%d runes", m.Runes)
+				if m.Blocks > 0 {
+					details += fmt.Sprintf(", %d blocks", m.Blocks)
+				}
+				details += ", " + m.Placement
+				if m.Broadcast {
+					details += ", broadcast"
+				}
+				fmt.Fprintf(w, "\n--- message %d/%d (%s) ---\n%s\n", i+1, len(plan.Messages), details, m.Text)
+			}
+			return nil
+		},
+	}
+}
+
+// postedTimestamps lists the timestamps of every message in result.
+func postedTimestamps(result *slackutil.PostResult) string {
+	if len(result.Timestamps) > 0 {
+		return strings.Join(result.Timestamps, ", ")
+	}
+	return result.TS
+}
+
 var messagesCmd = &cobra.Command{
 	Use:   "messages",
 	Short: "Message operations",
@@ -28,49 +402,17 @@ var messagesPostCmd = &cobra.Command{
 			return err
 		}
 
-		text, err := cmd.Flags().GetString("text")
+		text, err := messageText(cmd, client.User)
 		if err != nil {
-			return fmt.Errorf("failed to get text flag: %w", err)
-		}
-		if text == "" {
-			return fmt.Errorf("--text is required")
+			return err
 		}
 
-		text = slackutil.FixSlackMrkdwn(text)
-		chunks := slackutil.SplitMessage(text, slackutil.MaxMessageLength)
-
-		_, ts, err := client.User.PostMessage(channelID, slack.MsgOptionText(chunks[0], false))
+		opts, err := postOptions(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to post message: %w", err)
-		}
-
-		for _, chunk := range chunks[1:] {
-			_, _, err := client.User.PostMessage(channelID,
-				slack.MsgOptionText(chunk, false),
-				slack.MsgOptionTS(ts),
-			)
-			if err != nil {
-				return fmt.Errorf("failed to post thread reply: %w", err)
-			}
-		}
-
-		if outputJSON {
-			out := map[string]string{
-				"channel": channelID,
-				"ts":      ts,
-			}
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(out)
-		}
-
-		if outputPlain {
-			fmt.Printf("%s\t%s\n", channelID, ts)
-			return nil
+			return err
 		}
 
-		fmt.Printf("Message posted to %s (ts: %s)\n", channelID, ts)
-		return nil
+		return sendMessage(cmd, client.User, channelID, text, opts)
 	},
 }
 
@@ -87,58 +429,35 @@ var messagesReplyCmd = &cobra.Command{
 			return err
 		}
 
-		text, err := cmd.Flags().GetString("text")
+		text, err := messageText(cmd, client.User)
 		if err != nil {
-			return fmt.Errorf("failed to get text flag: %w", err)
-		}
-		if text == "" {
-			return fmt.Errorf("--text is required")
+			return err
 		}
 
-		text = slackutil.FixSlackMrkdwn(text)
-		opts := []slack.MsgOption{
-			slack.MsgOptionText(text, false),
-			slack.MsgOptionTS(threadTs),
+		opts, err := postOptions(cmd)
+		if err != nil {
+			return err
 		}
+		opts.ThreadTS = threadTs
 
 		broadcast, err := cmd.Flags().GetBool("broadcast")
 		if err != nil {
 			return fmt.Errorf("failed to get broadcast flag: %w", err)
 		}
-		if broadcast {
-			opts = append(opts, slack.MsgOptionBroadcast())
-		}
-
-		_, ts, err := client.User.PostMessage(channelID, opts...)
-		if err != nil {
-			return fmt.Errorf("failed to reply: %w", err)
-		}
-
-		if outputJSON {
-			out := map[string]string{
-				"channel":   channelID,
-				"ts":        ts,
-				"thread_ts": threadTs,
-			}
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(out)
-		}
-
-		if outputPlain {
-			fmt.Printf("%s\t%s\t%s\n", channelID, ts, threadTs)
-			return nil
-		}
+		opts.Broadcast = broadcast
 
-		fmt.Printf("Reply posted to %s thread %s (ts: %s)\n", channelID, threadTs, ts)
-		return nil
+		return sendMessage(cmd, client.User, channelID, text, opts)
 	},
 }
 
 func init() {
-	messagesPostCmd.Flags().String("text", "", "Message text")
-	messagesReplyCmd.Flags().String("text", "", "Reply text")
+	messagesPostCmd.Flags().String("text", "", "Message text (- reads stdin)")
+	addTextSourceFlags(messagesPostCmd)
+	addPostFlags(messagesPostCmd)
+	messagesReplyCmd.Flags().String("text", "", "Reply text (- reads stdin)")
+	addTextSourceFlags(messagesReplyCmd)
 	messagesReplyCmd.Flags().Bool("broadcast", false, "Also post to the channel (reply_broadcast)")
+	addPostFlags(messagesReplyCmd)
 
 	messagesCmd.AddCommand(messagesPostCmd)
 	messagesCmd.AddCommand(messagesReplyCmd)
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	slackapi "github.com/slack-go/slack"
+	"github.com/spf13/cobra"
+
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+)
+
+// textCmd returns a command with the message text flags parsed from args
+// and stdin as its input.
+func textCmd(t *testing.T, stdin string, args ...string) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{}
+	cmd.Flags().String("text", "", "")
+	addTextSourceFlags(cmd)
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	cmd.SetIn(strings.NewReader(stdin))
+	return cmd
+}
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMessageText(t *testing.T) {
+	report := writeTestFile(t, "report.md", "# Report\n\n- done\n")
+	tmpl := writeTestFile(t, "tmpl.md", "{{.title}} by {{.owner}} ({{.build}})\n")
+	vars := writeTestFile(t, "vars.json", `{"title": "Deploy", "owner": "ops", "build": 42}`)
+
+	tests := []struct {
+		name  string
+		stdin string
+		args  []string
+		want  string
+	}{
+		{"Text", "", []string{"--text", "hello"}, "hello"},
+		{"TextStdin", "line 1\nline 2\n", []string{"--text", "-"}, "line 1\nline 2"},
+		{"File", "", []string{"--file", report}, "# Report\n\n- done"},
+		{"FileStdin", "from stdin\n", []string{"--file", "-"}, "from stdin"},
+		{"Template", "", []string{"--text-template", tmpl, "--vars", vars, "--var", "owner=alice"}, "Deploy by alice (42)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := messageText(textCmd(t, tt.stdin, tt.args...), &slackutil.MockSlackAPI{})
+
+			if err != nil {
+				t.Fatalf("messageText: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageText_Errors(t *testing.T) {
+	tmpl := writeTestFile(t, "tmpl.md", "{{.missing}}")
+
+	tests := []struct {
+		name    string
+		stdin   string
+		args    []string
+		wantErr string
+	}{
+		{"NoSource", "", nil, "--text, --file or --text-template is required"},
+		{"TwoSources", "", []string{"--text", "a", "--file", "b.md"}, "only one of"},
+		{"VarsWithoutTemplate", "", []string{"--text", "a", "--var", "k=v"}, "require --text-template"},
+		{"BadVar", "", []string{"--text-template", tmpl, "--var", "novalue"}, "invalid --var"},
+		{"MissingFile", "", []string{"--file", "/nonexistent/report.md"}, "failed to read message file"},
+		{"EmptyStdin", "\n", []string{"--text", "-"}, "message text is empty"},
+		{"MissingTemplateVar", "", []string{"--text-template", tmpl}, "failed to render template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := messageText(textCmd(t, tt.stdin, tt.args...), &slackutil.MockSlackAPI{})
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPostMessage_ConcurrentRetriesPostOnce(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	var posts atomic.Int32
+	api := &slackutil.MockSlackAPI{
+		AuthTestFunc: func() (*slackapi.AuthTestResponse, error) {
+			return &slackapi.AuthTestResponse{UserID: "U001"}, nil
+		},
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			return &slackapi.GetConversationHistoryResponse{}, nil
+		},
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			posts.Add(1)
+			time.Sleep(200 * time.Millisecond)
+			return channelID, "1675382400.000000", nil
+		},
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*slackutil.PostResult, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := postMessage(api, "C001", "deploy done", slackutil.PostOptions{}, "deploy-42", time.Minute)
+			if err != nil {
+				t.Errorf("postMessage: %v", err)
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	if n := posts.Load(); n != 1 {
+		t.Errorf("posted %d times, want once", n)
+	}
+	for i, r := range results {
+		if r == nil || r.TS != "1675382400.000000" {
+			t.Errorf("result %d = %+v", i, r)
+		}
+	}
+}
+
+func TestPostMessage_FailedPostReleasesKey(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	fail := true
+	api := &slackutil.MockSlackAPI{
+		AuthTestFunc: func() (*slackapi.AuthTestResponse, error) {
+			return &slackapi.AuthTestResponse{UserID: "U001"}, nil
+		},
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			return &slackapi.GetConversationHistoryResponse{}, nil
+		},
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			if fail {
+				return "", "", errors.New("ratelimited")
+			}
+			return channelID, "1675382400.000000", nil
+		},
+	}
+	if _, err := postMessage(api, "C001", "deploy done", slackutil.PostOptions{}, "deploy-42", time.Minute); err == nil {
+		t.Fatal("expected the first post to fail")
+	}
+
+	fail = false
+	result, err := postMessage(api, "C001", "deploy done", slackutil.PostOptions{}, "deploy-42", time.Minute)
+
+	if err != nil || result.TS != "1675382400.000000" || result.Duplicate {
+		t.Errorf("retry = %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,531 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/tackeyy/slamy/internal/output"
+	"github.com/tackeyy/slamy/internal/safeguard"
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+	"github.com/tackeyy/slamy/internal/state"
+
+	"github.com/slack-go/slack"
+	"github.com/spf13/cobra"
+)
+
+// Delivery statuses of outbox entries.
+const (
+	// outboxSent means the message was posted and removed from the outbox.
+	outboxSent = "sent"
+	// outboxPartial means a split message was only partly posted. The rest
+	// stays queued and is posted after the parts already sent.
+	outboxPartial = "partial"
+	// outboxFailed means the attempt failed; the entry will be retried.
+	outboxFailed = "failed"
+	// outboxDead means the entry failed permanently or ran out of attempts.
+	// It stays in the outbox but no longer holds back its channel.
+	outboxDead = "dead"
+	// outboxDeferred means the entry is waiting for its next retry time.
+	outboxDeferred = "deferred"
+	// outboxWaiting means an earlier entry for the same channel is still
+	// queued, so this one is held back to keep the channel in order.
+	outboxWaiting = "waiting"
+	// outboxDue means a dry run would attempt the entry.
+	outboxDue = "due"
+	// outboxQueued means the entry could not be attempted because another
+	// process is flushing the outbox.
+	outboxQueued = "queued"
+)
+
+// Retry backoff for failed outbox entries: outboxRetryBase after the first
+// failure, doubling up to outboxRetryMax.
+const (
+	outboxRetryBase = 30 * time.Second
+	outboxRetryMax  = time.Hour
+)
+
+// outboxMaxAttempts is how many times an entry is attempted before it is
+// marked dead.
+const outboxMaxAttempts = 20
+
+// permanentSlackErrors are Slack API errors that retrying the same message
+// cannot fix.
+var permanentSlackErrors = map[string]bool{
+	"account_inactive":        true,
+	"cannot_reply_to_message": true,
+	"channel_not_found":       true,
+	"invalid_auth":            true,
+	"invalid_blocks":          true,
+	"is_archived":             true,
+	"missing_scope":           true,
+	"msg_too_long":            true,
+	"no_permission":           true,
+	"no_text":                 true,
+	"not_authed":              true,
+	"not_in_channel":          true,
+	"restricted_action":       true,
+	"thread_not_found":        true,
+	"token_expired":           true,
+	"token_revoked":           true,
+	"too_many_attachments":    true,
+}
+
+// outboxResult reports what happened to an outbox entry.
+type outboxResult struct {
+	ID            string     `json:"id"`
+	Channel       string     `json:"channel"`
+	Status        string     `json:"status"`
+	TS            string     `json:"ts,omitempty"`
+	Timestamps    []string   `json:"timestamps,omitempty"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	// Warnings reports what the outbound safeguards found when the message
+	// was queued.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// flushOutbox attempts delivery of the queued entries, oldest first, and
+// removes those that were posted. Entries for one channel are delivered in
+// order: once an entry fails or is not yet due, later entries for the same
+// channel wait. Dead entries are skipped. When channel is set only that
+// channel is flushed; force ignores retry times and retries dead entries.
+func flushOutbox(api slackutil.SlackAPI, box *state.Outbox, channel string, force bool) ([]outboxResult, error) {
+	unlock, err := box.Lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	entries, err := box.List()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []outboxResult{}
+	blocked := map[string]bool{}
+	for _, e := range entries {
+		if (channel != "" && e.Channel != channel) || (e.Dead && !force) {
+			continue
+		}
+		res := outboxResult{ID: e.ID, Channel: e.Channel, Attempts: e.Attempts, NextAttemptAt: e.NextAttemptAt, Error: e.LastError}
+		now := time.Now()
+		switch {
+		case blocked[e.Channel]:
+			res.Status = outboxWaiting
+		case !force && !e.Due(now):
+			res.Status = outboxDeferred
+			blocked[e.Channel] = true
+		default:
+			res, err = deliverOutboxEntry(api, box, e, now)
+			if err != nil {
+				return results, err
+			}
+			if res.Status == outboxFailed || res.Status == outboxPartial {
+				blocked[e.Channel] = true
+			}
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// previewOutboxFlush reports what flushOutbox would attempt, without
+// posting, assuming every attempt succeeds.
+func previewOutboxFlush(box *state.Outbox, channel string, force bool) ([]outboxResult, error) {
+	entries, err := box.List()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []outboxResult{}
+	blocked := map[string]bool{}
+	now := time.Now()
+	for _, e := range entries {
+		if (channel != "" && e.Channel != channel) || (e.Dead && !force) {
+			continue
+		}
+		res := outboxResult{ID: e.ID, Channel: e.Channel, Status: outboxDue, Attempts: e.Attempts, NextAttemptAt: e.NextAttemptAt, Error: e.LastError}
+		switch {
+		case blocked[e.Channel]:
+			res.Status = outboxWaiting
+		case !force && !e.Due(now):
+			res.Status = outboxDeferred
+			blocked[e.Channel] = true
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// deliverOutboxEntry posts e and updates the outbox with the outcome. A
+// partly posted message keeps its remaining parts queued, and an entry that
+// failed permanently or ran out of attempts is marked dead.
+func deliverOutboxEntry(api slackutil.SlackAPI, box *state.Outbox, e state.OutboxEntry, now time.Time) (outboxResult, error) {
+	opts := slackutil.PostOptions{
+		Format:    e.Format,
+		Overflow:  e.Overflow,
+		Markers:   e.Markers,
+		ThreadTS:  e.ThreadTS,
+		Broadcast: e.Broadcast,
+		Posted:    e.Posted,
+	}
+	posted, postErr := postMessage(api, e.Channel, e.Text, opts, e.IdempotencyKey, e.DedupWindow)
+
+	e.Attempts++
+	e.LastAttemptAt = &now
+	res := outboxResult{ID: e.ID, Channel: e.Channel, Attempts: e.Attempts}
+	if posted != nil {
+		res.TS = posted.TS
+		res.Timestamps = posted.Timestamps
+	}
+	if postErr == nil {
+		res.Status = outboxSent
+		return res, box.Remove(e.ID)
+	}
+
+	if posted != nil && len(posted.Timestamps) > len(e.Posted) {
+		e.Posted = posted.Timestamps
+	}
+	e.LastError = postErr.Error()
+	e.NextAttemptAt = nil
+	res.Error = e.LastError
+	switch {
+	case isPermanentPostError(postErr) || e.Attempts >= outboxMaxAttempts:
+		e.Dead = true
+		res.Status = outboxDead
+	default:
+		e.Dead = false
+		next := now.Add(outboxRetryDelay(e.Attempts, postErr))
+		e.NextAttemptAt = &next
+		res.NextAttemptAt = &next
+		res.Status = outboxFailed
+		if len(e.Posted) > 0 {
+			res.Status = outboxPartial
+		}
+	}
+	return res, box.Update(e)
+}
+
+// isPermanentPostError reports whether err means posting the message will
+// keep failing, such as a missing channel, revoked token or a message the
+// safeguards block.
+func isPermanentPostError(err error) bool {
+	var slackErr slack.SlackErrorResponse
+	if errors.As(err, &slackErr) {
+		return permanentSlackErrors[slackErr.Err]
+	}
+	var blocked *safeguard.BlockedError
+	return errors.As(err, &blocked)
+}
+
+// outboxRetryDelay returns how long to wait before retrying an entry that
+// has failed attempts times, honouring Slack's Retry-After when rate limited.
+func outboxRetryDelay(attempts int, err error) time.Duration {
+	var rateLimited *slack.RateLimitedError
+	if errors.As(err, &rateLimited) && rateLimited.RetryAfter > 0 {
+		return rateLimited.RetryAfter
+	}
+	delay := outboxRetryBase
+	for i := 1; i < attempts && delay < outboxRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, outboxRetryMax)
+}
+
+// queueMessage adds a message to the outbox and immediately flushes its
+// channel. A failed delivery leaves the message queued for a later flush.
+// The safeguards are applied before queueing, so a blocked message is never
+// queued and a redacted one is stored redacted. As with postMessage, window
+// only applies when key is set.
+func queueMessage(api slackutil.SlackAPI, channelID, text string, opts slackutil.PostOptions, key string, window time.Duration) (*outboxResult, error) {
+	text, warnings, err := guardText(channelID, text)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		window = 0
+	}
+	box, err := state.OpenOutbox()
+	if err != nil {
+		return nil, err
+	}
+	entry, err := box.Add(state.OutboxEntry{
+		Channel:        channelID,
+		Text:           text,
+		Format:         opts.Format,
+		Overflow:       opts.Overflow,
+		Markers:        opts.Markers,
+		ThreadTS:       opts.ThreadTS,
+		Broadcast:      opts.Broadcast,
+		IdempotencyKey: key,
+		DedupWindow:    window,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	queued := &outboxResult{ID: entry.ID, Channel: channelID, Status: outboxQueued, Warnings: warnings}
+	results, err := flushOutbox(api, box, channelID, false)
+	if errors.Is(err, state.ErrOutboxLocked) {
+		return queued, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, res := range results {
+		if res.ID == entry.ID {
+			res.Warnings = warnings
+			return &res, nil
+		}
+	}
+	return queued, nil
+}
+
+// runOutboxFlusher flushes the outbox every interval until done is closed,
+// logging failures to logger.
+func runOutboxFlusher(interval time.Duration, done <-chan struct{}, logger *log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		client, err := getClientFunc()
+		if err != nil {
+			logger.Printf("outbox flush: %v", err)
+			continue
+		}
+		box, err := state.OpenOutbox()
+		if err != nil {
+			logger.Printf("outbox flush: %v", err)
+			continue
+		}
+		results, err := flushOutbox(client.User, box, "", false)
+		if err != nil && !errors.Is(err, state.ErrOutboxLocked) {
+			logger.Printf("outbox flush: %v", err)
+		}
+		for _, res := range results {
+			if res.Status == outboxFailed || res.Status == outboxPartial || res.Status == outboxDead {
+				logger.Printf("outbox flush: %s to %s %s (attempt %d): %s", res.ID, res.Channel, res.Status, res.Attempts, res.Error)
+			}
+		}
+	}
+}
+
+// outboxResultsView renders the results of flushing the outbox.
+func outboxResultsView(results []outboxResult) output.View {
+	return output.View{
+		Data:    results,
+		Columns: []string{"id", "channel", "status", "ts", "attempts", "error"},
+		Text: func(w io.Writer) error {
+			if len(results) == 0 {
+				_, err := fmt.Fprintln(w, "Outbox is empty")
+				return err
+			}
+			for _, res := range results {
+				if err := writeOutboxResult(w, res); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// writeOutboxResult writes a one-line description of res.
+func writeOutboxResult(w io.Writer, res outboxResult) error {
+	var err error
+	switch res.Status {
+	case outboxSent:
+		_, err = fmt.Fprintf(w, "%s sent to %s (ts: %s)\n", res.ID, res.Channel, res.TS)
+	case outboxPartial:
+		_, err = fmt.Fprintf(w, "%s partly sent to %s (ts: %s, rest retried at %s): %s\n",
+			res.ID, res.Channel, res.TS, res.NextAttemptAt.Local().Format(time.DateTime), res.Error)
+	case outboxDead:
+		_, err = fmt.Fprintf(w, "%s gave up on %s after %d attempt(s): %s\n", res.ID, res.Channel, res.Attempts, res.Error)
+	case outboxFailed:
+		_, err = fmt.Fprintf(w, "%s failed for %s (attempt %d, retry at %s): %s\n",
+			res.ID, res.Channel, res.Attempts, res.NextAttemptAt.Local().Format(time.DateTime), res.Error)
+	case outboxDeferred:
+		_, err = fmt.Fprintf(w, "%s deferred for %s until %s\n", res.ID, res.Channel, res.NextAttemptAt.Local().Format(time.DateTime))
+	case outboxDue:
+		_, err = fmt.Fprintf(w, "%s would be sent to %s\n", res.ID, res.Channel)
+	case outboxWaiting:
+		_, err = fmt.Fprintf(w, "%s waiting behind an earlier message for %s\n", res.ID, res.Channel)
+	default:
+		_, err = fmt.Fprintf(w, "%s queued for %s\n", res.ID, res.Channel)
+	}
+	for _, warning := range res.Warnings {
+		if err == nil {
+			_, err = fmt.Fprintf(w, "Warning: %s\n", warning)
+		}
+	}
+	return err
+}
+
+// outboxPreview returns the start of the first line of text.
+func outboxPreview(text string) string {
+	line, _, cut := strings.Cut(text, "\n")
+	if r := []rune(line); len(r) > 60 {
+		line, cut = string(r[:60]), true
+	}
+	if cut {
+		line += "…"
+	}
+	return line
+}
+
+var outboxCmd = &cobra.Command{
+	Use:   "outbox",
+	Short: "Manage messages queued with --queue",
+}
+
+var outboxListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List queued messages",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		box, err := state.OpenOutbox()
+		if err != nil {
+			return err
+		}
+		entries, err := box.List()
+		if err != nil {
+			return err
+		}
+
+		return render(output.View{
+			Data:    entries,
+			Columns: []string{"id", "channel", "thread_ts", "attempts", "next_attempt_at", "dead", "last_error"},
+			Text: func(w io.Writer) error {
+				if len(entries) == 0 {
+					fmt.Fprintln(w, "Outbox is empty")
+					return nil
+				}
+				for _, e := range entries {
+					dead := ""
+					if e.Dead {
+						dead = "  dead"
+					}
+					fmt.Fprintf(w, "%s  %s  %d attempt(s)%s  %s\n", e.ID, e.Channel, e.Attempts, dead, outboxPreview(e.Text))
+					if e.LastError != "" {
+						fmt.Fprintf(w, "    last error: %s\n", e.LastError)
+					}
+				}
+				return nil
+			},
+		})
+	},
+}
+
+var outboxFlushCmd = &cobra.Command{
+	Use:   "flush",
+	Short: "Post queued messages that are due",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		channel, err := cmd.Flags().GetString("channel")
+		if err != nil {
+			return fmt.Errorf("failed to get channel flag: %w", err)
+		}
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			return fmt.Errorf("failed to get force flag: %w", err)
+		}
+
+		box, err := state.OpenOutbox()
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			results, err := previewOutboxFlush(box, channel, force)
+			if err != nil {
+				return err
+			}
+			return render(outboxResultsView(results))
+		}
+
+		client, err := slackutil.NewClient()
+		if err != nil {
+			return err
+		}
+		results, err := flushOutbox(client.User, box, channel, force)
+		if err != nil {
+			return err
+		}
+
+		return render(outboxResultsView(results))
+	},
+}
+
+var outboxDropCmd = &cobra.Command{
+	Use:   "drop [id...]",
+	Short: "Remove queued messages without posting them",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			return fmt.Errorf("failed to get all flag: %w", err)
+		}
+		if all == (len(args) > 0) {
+			return fmt.Errorf("specify outbox entry IDs or --all")
+		}
+
+		box, err := state.OpenOutbox()
+		if err != nil {
+			return err
+		}
+		ids := args
+		if all {
+			entries, err := box.List()
+			if err != nil {
+				return err
+			}
+			ids = nil
+			for _, e := range entries {
+				ids = append(ids, e.ID)
+			}
+		}
+		if dryRun {
+			return render(output.View{
+				Data:    map[string]any{"dropped": ids, "dry_run": true},
+				Columns: []string{"dropped"},
+				Text: func(w io.Writer) error {
+					_, err := fmt.Fprintf(w, "Dry run: would drop %d queued message(s)\n", len(ids))
+					return err
+				},
+			})
+		}
+		for _, id := range ids {
+			if err := box.Remove(id); err != nil {
+				return err
+			}
+		}
+
+		return render(output.View{
+			Data:    map[string]any{"dropped": ids},
+			Columns: []string{"dropped"},
+			Text: func(w io.Writer) error {
+				_, err := fmt.Fprintf(w, "Dropped %d queued message(s)\n", len(ids))
+				return err
+			},
+		})
+	},
+}
+
+func init() {
+	outboxFlushCmd.Flags().String("channel", "", "Only flush messages for this channel")
+	outboxFlushCmd.Flags().Bool("force", false, "Retry failed and dead messages now instead of waiting for their retry time")
+	outboxDropCmd.Flags().Bool("all", false, "Drop every queued message")
+
+	outboxCmd.AddCommand(outboxListCmd)
+	outboxCmd.AddCommand(outboxFlushCmd)
+	outboxCmd.AddCommand(outboxDropCmd)
+	rootCmd.AddCommand(outboxCmd)
+}
@@ -0,0 +1,460 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	slackapi "github.com/slack-go/slack"
+
+	"github.com/tackeyy/slamy/internal/safeguard"
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+	"github.com/tackeyy/slamy/internal/state"
+)
+
+// outboxAPI returns a mock that records posted texts as "channel:text" and
+// fails posts to the channels in failing.
+func outboxAPI(t *testing.T, posted *[]string, failing map[string]error) *slackutil.MockSlackAPI {
+	t.Helper()
+	return &slackutil.MockSlackAPI{
+		AuthTestFunc: func() (*slackapi.AuthTestResponse, error) {
+			return &slackapi.AuthTestResponse{UserID: "U001"}, nil
+		},
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			return &slackapi.GetConversationHistoryResponse{}, nil
+		},
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			if err := failing[channelID]; err != nil {
+				return "", "", err
+			}
+			*posted = append(*posted, channelID+":"+msgValues(t, options...)["text"])
+			return channelID, fmt.Sprintf("1675382400.%06d", len(*posted)), nil
+		},
+	}
+}
+
+func openTestOutbox(t *testing.T) *state.Outbox {
+	t.Helper()
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	box, err := state.OpenOutbox()
+	if err != nil {
+		t.Fatalf("OpenOutbox: %v", err)
+	}
+	return box
+}
+
+func addEntries(t *testing.T, box *state.Outbox, entries ...state.OutboxEntry) []state.OutboxEntry {
+	t.Helper()
+	var added []state.OutboxEntry
+	for _, e := range entries {
+		e, err := box.Add(e)
+		if err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		added = append(added, e)
+	}
+	return added
+}
+
+func statuses(results []outboxResult) string {
+	var s []string
+	for _, res := range results {
+		s = append(s, res.Channel+":"+res.Status)
+	}
+	return strings.Join(s, ",")
+}
+
+func TestFlushOutbox_DeliversInOrder(t *testing.T) {
+	box := openTestOutbox(t)
+	addEntries(t, box,
+		state.OutboxEntry{Channel: "C001", Text: "one"},
+		state.OutboxEntry{Channel: "C002", Text: "two"},
+		state.OutboxEntry{Channel: "C001", Text: "three"},
+	)
+	var posted []string
+
+	results, err := flushOutbox(outboxAPI(t, &posted, nil), box, "", false)
+
+	if err != nil {
+		t.Fatalf("flushOutbox: %v", err)
+	}
+	if got := strings.Join(posted, ","); got != "C001:one,C002:two,C001:three" {
+		t.Errorf("posted = %s", got)
+	}
+	if got := statuses(results); got != "C001:sent,C002:sent,C001:sent" {
+		t.Errorf("statuses = %s", got)
+	}
+	if entries, _ := box.List(); len(entries) != 0 {
+		t.Errorf("outbox not empty: %+v", entries)
+	}
+}
+
+func TestFlushOutbox_FailureHoldsBackChannel(t *testing.T) {
+	box := openTestOutbox(t)
+	added := addEntries(t, box,
+		state.OutboxEntry{Channel: "C001", Text: "one"},
+		state.OutboxEntry{Channel: "C002", Text: "two"},
+		state.OutboxEntry{Channel: "C001", Text: "three"},
+	)
+	var posted []string
+	api := outboxAPI(t, &posted, map[string]error{"C001": errors.New("network down")})
+
+	results, err := flushOutbox(api, box, "", false)
+
+	if err != nil {
+		t.Fatalf("flushOutbox: %v", err)
+	}
+	if got := strings.Join(posted, ","); got != "C002:two" {
+		t.Errorf("posted = %s", got)
+	}
+	if got := statuses(results); got != "C001:failed,C002:sent,C001:waiting" {
+		t.Errorf("statuses = %s", got)
+	}
+	entries, _ := box.List()
+	if len(entries) != 2 || entries[0].ID != added[0].ID || entries[1].ID != added[2].ID {
+		t.Fatalf("entries = %+v", entries)
+	}
+	first := entries[0]
+	if first.Attempts != 1 || first.LastError == "" || first.NextAttemptAt == nil || first.LastAttemptAt == nil {
+		t.Errorf("failed entry = %+v", first)
+	}
+	if entries[1].Attempts != 0 {
+		t.Errorf("waiting entry was attempted: %+v", entries[1])
+	}
+}
+
+func TestFlushOutbox_PermanentFailureMarksDead(t *testing.T) {
+	box := openTestOutbox(t)
+	addEntries(t, box,
+		state.OutboxEntry{Channel: "C001", Text: "one"},
+		state.OutboxEntry{Channel: "C001", Text: "two"},
+	)
+	var posted []string
+	api := outboxAPI(t, &posted, map[string]error{"C001": slackapi.SlackErrorResponse{Err: "not_in_channel"}})
+
+	results, err := flushOutbox(api, box, "", false)
+
+	if err != nil {
+		t.Fatalf("flushOutbox: %v", err)
+	}
+	if got := statuses(results); got != "C001:dead,C001:dead" {
+		t.Errorf("statuses = %s", got)
+	}
+	entries, _ := box.List()
+	if len(entries) != 2 || !entries[0].Dead || entries[0].NextAttemptAt != nil {
+		t.Fatalf("entries = %+v", entries)
+	}
+	results, err = flushOutbox(api, box, "", false)
+	if err != nil || len(results) != 0 {
+		t.Errorf("dead entries attempted again: %+v, %v", results, err)
+	}
+}
+
+func TestFlushOutbox_DeadEntryDoesNotHoldBackChannel(t *testing.T) {
+	box := openTestOutbox(t)
+	added := addEntries(t, box,
+		state.OutboxEntry{Channel: "C001", Text: "one"},
+		state.OutboxEntry{Channel: "C001", Text: "two"},
+	)
+	added[0].Dead = true
+	if err := box.Update(added[0]); err != nil {
+		t.Fatal(err)
+	}
+	var posted []string
+
+	results, err := flushOutbox(outboxAPI(t, &posted, nil), box, "", false)
+
+	if err != nil {
+		t.Fatalf("flushOutbox: %v", err)
+	}
+	if got := strings.Join(posted, ","); got != "C001:two" || statuses(results) != "C001:sent" {
+		t.Errorf("posted = %s, statuses = %s", got, statuses(results))
+	}
+}
+
+func TestFlushOutbox_MaxAttemptsMarksDead(t *testing.T) {
+	box := openTestOutbox(t)
+	added := addEntries(t, box, state.OutboxEntry{Channel: "C001", Text: "one"})
+	added[0].Attempts = outboxMaxAttempts - 1
+	if err := box.Update(added[0]); err != nil {
+		t.Fatal(err)
+	}
+	var posted []string
+	api := outboxAPI(t, &posted, map[string]error{"C001": errors.New("network down")})
+
+	results, err := flushOutbox(api, box, "", true)
+
+	if err != nil {
+		t.Fatalf("flushOutbox: %v", err)
+	}
+	if statuses(results) != "C001:dead" || results[0].Attempts != outboxMaxAttempts {
+		t.Errorf("results = %+v", results)
+	}
+}
+
+func TestFlushOutbox_PartialKeepsRemainder(t *testing.T) {
+	box := openTestOutbox(t)
+	text := strings.Repeat("a", 3000) + "\n\n" + strings.Repeat("b", 3000) + "\n\n" + strings.Repeat("c", 3000)
+	addEntries(t, box, state.OutboxEntry{Channel: "C001", Text: text})
+	var threads []string
+	fail := true
+	api := outboxAPI(t, nil, nil)
+	api.PostMessageFunc = func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+		if len(threads) == 1 && fail {
+			fail = false
+			return "", "", errors.New("network down")
+		}
+		threads = append(threads, msgValues(t, options...)["thread_ts"])
+		return channelID, fmt.Sprintf("1675382400.%06d", len(threads)), nil
+	}
+
+	results, err := flushOutbox(api, box, "", false)
+	if err != nil {
+		t.Fatalf("flushOutbox: %v", err)
+	}
+	entries, _ := box.List()
+	if statuses(results) != "C001:partial" || len(entries) != 1 || len(entries[0].Posted) != 1 {
+		t.Fatalf("results = %+v, entries = %+v", results, entries)
+	}
+
+	results, err = flushOutbox(api, box, "", true)
+
+	if err != nil {
+		t.Fatalf("flushOutbox: %v", err)
+	}
+	if statuses(results) != "C001:sent" || len(results[0].Timestamps) != 3 {
+		t.Errorf("results = %+v", results)
+	}
+	if got := strings.Join(threads, ","); got != ",1675382400.000001,1675382400.000001" {
+		t.Errorf("thread_ts of posts = %s", got)
+	}
+	if entries, _ := box.List(); len(entries) != 0 {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestIsPermanentPostError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("failed to post message: %w", slackapi.SlackErrorResponse{Err: "channel_not_found"}), true},
+		{slackapi.SlackErrorResponse{Err: "is_archived"}, true},
+		{slackapi.SlackErrorResponse{Err: "internal_error"}, false},
+		{&slackapi.RateLimitedError{RetryAfter: time.Second}, false},
+		{&safeguard.BlockedError{Channel: "C001"}, true},
+		{errors.New("network down"), false},
+	}
+	for _, tt := range tests {
+		if got := isPermanentPostError(tt.err); got != tt.want {
+			t.Errorf("isPermanentPostError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestFlushOutbox_DefersUntilRetryTime(t *testing.T) {
+	box := openTestOutbox(t)
+	later := time.Now().Add(time.Hour)
+	added := addEntries(t, box, state.OutboxEntry{Channel: "C001", Text: "one"})
+	added[0].Attempts = 1
+	added[0].NextAttemptAt = &later
+	if err := box.Update(added[0]); err != nil {
+		t.Fatal(err)
+	}
+	var posted []string
+
+	results, err := flushOutbox(outboxAPI(t, &posted, nil), box, "", false)
+	if err != nil {
+		t.Fatalf("flushOutbox: %v", err)
+	}
+	if len(posted) != 0 || statuses(results) != "C001:deferred" {
+		t.Errorf("posted = %v, statuses = %s", posted, statuses(results))
+	}
+
+	results, err = flushOutbox(outboxAPI(t, &posted, nil), box, "", true)
+	if err != nil {
+		t.Fatalf("flushOutbox: %v", err)
+	}
+	if len(posted) != 1 || statuses(results) != "C001:sent" || results[0].Attempts != 2 {
+		t.Errorf("posted = %v, results = %+v", posted, results)
+	}
+}
+
+func TestFlushOutbox_OnlyChannel(t *testing.T) {
+	box := openTestOutbox(t)
+	addEntries(t, box,
+		state.OutboxEntry{Channel: "C001", Text: "one"},
+		state.OutboxEntry{Channel: "C002", Text: "two"},
+	)
+	var posted []string
+
+	_, err := flushOutbox(outboxAPI(t, &posted, nil), box, "C002", false)
+
+	if err != nil {
+		t.Fatalf("flushOutbox: %v", err)
+	}
+	if got := strings.Join(posted, ","); got != "C002:two" {
+		t.Errorf("posted = %s", got)
+	}
+	if entries, _ := box.List(); len(entries) != 1 || entries[0].Channel != "C001" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestFlushOutbox_Locked(t *testing.T) {
+	box := openTestOutbox(t)
+	unlock, err := box.Lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	_, err = flushOutbox(&slackutil.MockSlackAPI{}, box, "", false)
+
+	if !errors.Is(err, state.ErrOutboxLocked) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestPreviewOutboxFlush(t *testing.T) {
+	box := openTestOutbox(t)
+	later := time.Now().Add(time.Hour)
+	added := addEntries(t, box,
+		state.OutboxEntry{Channel: "C001", Text: "one"},
+		state.OutboxEntry{Channel: "C002", Text: "two"},
+		state.OutboxEntry{Channel: "C002", Text: "three"},
+	)
+	added[1].NextAttemptAt = &later
+	if err := box.Update(added[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := previewOutboxFlush(box, "", false)
+
+	if err != nil {
+		t.Fatalf("previewOutboxFlush: %v", err)
+	}
+	if got := statuses(results); got != "C001:due,C002:deferred,C002:waiting" {
+		t.Errorf("statuses = %s", got)
+	}
+	if entries, _ := box.List(); len(entries) != 3 {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestOutboxRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		err      error
+		want     time.Duration
+	}{
+		{1, errors.New("boom"), 30 * time.Second},
+		{2, errors.New("boom"), time.Minute},
+		{4, errors.New("boom"), 4 * time.Minute},
+		{20, errors.New("boom"), time.Hour},
+		{1, &slackapi.RateLimitedError{RetryAfter: 7 * time.Second}, 7 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := outboxRetryDelay(tt.attempts, tt.err); got != tt.want {
+			t.Errorf("outboxRetryDelay(%d, %v) = %v, want %v", tt.attempts, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestHandlePostMessage_QueueDeliversNow(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	var posted []string
+	cleanup := setMockClient(outboxAPI(t, &posted, nil))
+	defer cleanup()
+
+	req := makeRequest(map[string]any{"channel_id": "C001", "text": "hello", "queue": true})
+	result, err := handlePostMessage(context.Background(), req)
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got outboxResult
+	if err := json.Unmarshal([]byte(resultText(t, result)), &got); err != nil {
+		t.Fatalf("failed to parse result JSON: %v", err)
+	}
+	if got.Status != outboxSent || got.TS == "" || len(posted) != 1 {
+		t.Errorf("result = %+v, posted = %v", got, posted)
+	}
+}
+
+func TestHandlePostMessage_QueueKeepsFailedMessage(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	var posted []string
+	cleanup := setMockClient(outboxAPI(t, &posted, map[string]error{"C001": errors.New("network down")}))
+	defer cleanup()
+
+	req := makeRequest(map[string]any{"channel_id": "C001", "text": "hello", "queue": true})
+	result, err := handlePostMessage(context.Background(), req)
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, result); !strings.Contains(text, `"status": "failed"`) {
+		t.Errorf("unexpected result: %s", text)
+	}
+	box, _ := state.OpenOutbox()
+	if entries, _ := box.List(); len(entries) != 1 || entries[0].Text != "hello" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestQueueMessage_DedupOnlyWithKey(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	var posted []string
+	api := outboxAPI(t, &posted, nil)
+	// Every history read finds "hello" already posted by the current user.
+	api.GetConversationHistoryFunc = func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+		return &slackapi.GetConversationHistoryResponse{Messages: []slackapi.Message{watchMsg("1675382400.000001", "", "hello")}}, nil
+	}
+
+	res, err := queueMessage(api, "C001", "hello", slackutil.PostOptions{}, "", time.Hour)
+	if err != nil || res.Status != outboxSent || len(posted) != 1 {
+		t.Fatalf("without key: result = %+v, posted = %v, err = %v", res, posted, err)
+	}
+
+	res, err = queueMessage(api, "C001", "hello", slackutil.PostOptions{}, "retry-1", time.Hour)
+	if err != nil || res.Status != outboxSent || res.TS != "1675382400.000001" || len(posted) != 1 {
+		t.Errorf("with key: result = %+v, posted = %v, err = %v", res, posted, err)
+	}
+}
+
+func TestQueueMessage_StoresDedupWindow(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	var posted []string
+	api := outboxAPI(t, &posted, map[string]error{"C001": errors.New("network down")})
+
+	for _, key := range []string{"", "key-1"} {
+		if _, err := queueMessage(api, "C001", "hello "+key, slackutil.PostOptions{}, key, 5*time.Minute); err != nil {
+			t.Fatalf("queueMessage: %v", err)
+		}
+	}
+
+	box, _ := state.OpenOutbox()
+	entries, _ := box.List()
+	if len(entries) != 2 || entries[0].DedupWindow != 0 || entries[1].DedupWindow != 5*time.Minute {
+		t.Errorf("entries = %+v", entries)
+	}
+	if !strings.HasPrefix(entries[0].IdempotencyKey, "outbox-") {
+		t.Errorf("key = %q", entries[0].IdempotencyKey)
+	}
+}
+
+func TestQueueMessage_ReturnsWarnings(t *testing.T) {
+	writeSafeguardConfig(t, `{"action": "warn"}`)
+	var posted []string
+
+	res, err := queueMessage(outboxAPI(t, &posted, nil), "C001", "token: "+leakedToken, slackutil.PostOptions{}, "", 0)
+
+	if err != nil || len(res.Warnings) != 1 || !strings.HasPrefix(res.Warnings[0], "possible slack_token") {
+		t.Errorf("result = %+v, err = %v", res, err)
+	}
+}
@@ -1,11 +1,12 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
+	"io"
 
+	"github.com/tackeyy/slamy/internal/output"
 	slackutil "github.com/tackeyy/slamy/internal/slack"
+	"github.com/tackeyy/slamy/internal/state"
 
 	"github.com/slack-go/slack"
 	"github.com/spf13/cobra"
@@ -37,30 +38,34 @@ var reactionsAddCmd = &cobra.Command{
 			return fmt.Errorf("--name is required")
 		}
 
+		data := map[string]any{"channel": channelID, "ts": timestamp, "reaction": name}
+		if dryRun {
+			data["dry_run"] = true
+			return render(output.View{
+				Data:    data,
+				Columns: []string{"channel", "ts", "reaction"},
+				Text: func(w io.Writer) error {
+					_, err := fmt.Fprintf(w, "Dry run: would add reaction :%s: to %s at %s\n", name, channelID, timestamp)
+					return err
+				},
+			})
+		}
+
 		ref := slack.NewRefToMessage(channelID, timestamp)
 		err = client.User.AddReaction(name, ref)
 		if err != nil {
 			return fmt.Errorf("failed to add reaction: %w", err)
 		}
+		recordWrite(state.AuditEntry{Action: state.AuditReactionAdd, Channel: channelID, TS: timestamp, Reaction: name}, "")
 
-		if outputJSON {
-			out := map[string]string{
-				"channel":  channelID,
-				"ts":       timestamp,
-				"reaction": name,
-			}
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(out)
-		}
-
-		if outputPlain {
-			fmt.Printf("%s\t%s\t%s\n", channelID, timestamp, name)
-			return nil
-		}
-
-		fmt.Printf("Reaction :%s: added to %s at %s\n", name, channelID, timestamp)
-		return nil
+		return render(output.View{
+			Data:    data,
+			Columns: []string{"channel", "ts", "reaction"},
+			Text: func(w io.Writer) error {
+				_, err := fmt.Fprintf(w, "Reaction :%s: added to %s at %s\n", name, channelID, timestamp)
+				return err
+			},
+		})
 	},
 }
 
@@ -3,8 +3,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/tackeyy/slamy/internal/output"
 )
 
 var (
@@ -12,6 +15,17 @@ var (
 )
 
 var (
+	outputFormat   string
+	outputTemplate string
+	outputFields   string
+	outputHeader   bool
+	outputQuery    string
+
+	// dryRun makes write commands print what they would send instead of
+	// calling Slack.
+	dryRun bool
+
+	// Deprecated aliases for --output json / --output tsv.
 	outputJSON  bool
 	outputPlain bool
 )
@@ -21,6 +35,9 @@ var rootCmd = &cobra.Command{
 	Short:   "Slack CLI tool",
 	Long:    "slamy — A CLI tool for Slack operations. Designed for both human use and AI agent integration.",
 	Version: version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return outputOptions().Validate()
+	},
 }
 
 func Execute() {
@@ -30,7 +47,45 @@ func Execute() {
 	}
 }
 
+// outputOptions resolves the global output flags, including the deprecated
+// --json/--plain aliases and --template implying --output template.
+func outputOptions() output.Options {
+	format := outputFormat
+	switch {
+	case format != "":
+	case outputJSON:
+		format = output.FormatJSON
+	case outputPlain:
+		format = output.FormatTSV
+	case outputTemplate != "":
+		format = output.FormatTemplate
+	}
+	return output.Options{
+		Format:   format,
+		Template: outputTemplate,
+		Fields:   output.ParseFields(outputFields),
+		Header:   outputHeader,
+		Query:    outputQuery,
+	}
+}
+
+// render writes a command result to stdout using the global output flags.
+func render(v output.View) error {
+	return output.Render(os.Stdout, outputOptions(), v)
+}
+
 func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "",
+		"Output format: "+strings.Join(output.Formats, "|")+" (default text)")
+	rootCmd.PersistentFlags().StringVar(&outputTemplate, "template", "", "Go template applied to each result row, e.g. '{{.ts}} {{.text}}'")
+	rootCmd.PersistentFlags().StringVar(&outputFields, "fields", "", "Comma-separated fields to output, e.g. ts,user,text")
+	rootCmd.PersistentFlags().BoolVar(&outputHeader, "header", false, "Print a header line in TSV output")
+	rootCmd.PersistentFlags().StringVarP(&outputQuery, "query", "q", "", "jq expression applied to the JSON output before rendering, e.g. '.[] | select(.reply_count > 0)'")
+
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Show what write commands would send without calling Slack")
+
 	rootCmd.PersistentFlags().BoolVar(&outputJSON, "json", false, "Output in JSON format")
 	rootCmd.PersistentFlags().BoolVar(&outputPlain, "plain", false, "Output in TSV format")
+	_ = rootCmd.PersistentFlags().MarkDeprecated("json", "use --output json")
+	_ = rootCmd.PersistentFlags().MarkDeprecated("plain", "use --output tsv")
 }
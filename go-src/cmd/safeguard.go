@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"github.com/tackeyy/slamy/internal/safeguard"
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+	"github.com/tackeyy/slamy/internal/state"
+)
+
+// safeguardFile is the safeguard policy in the data directory.
+const safeguardFile = "safeguards.json"
+
+// guardText scans text bound for channelID with the configured safeguard
+// policy. It returns the text to post (redacted when the channel's action is
+// redact) and warnings describing the findings, or an error when the message
+// is blocked.
+func guardText(channelID, text string) (string, []string, error) {
+	cfg, err := loadSafeguardConfig()
+	if err != nil {
+		return "", nil, err
+	}
+	scanner, err := safeguard.NewScanner(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	result, err := scanner.Check(channelID, text)
+	if err != nil {
+		return "", nil, err
+	}
+	return result.Text, result.Warnings(), nil
+}
+
+// loadSafeguardConfig reads safeguards.json from the data directory.
+func loadSafeguardConfig() (safeguard.Config, error) {
+	path, err := state.Path(safeguardFile)
+	if err != nil {
+		return safeguard.Config{}, err
+	}
+	return safeguard.LoadConfig(path)
+}
+
+// outputRedactor returns the redactor for MCP tool output.
+func outputRedactor() (*safeguard.Redactor, error) {
+	cfg, err := loadSafeguardConfig()
+	if err != nil {
+		return nil, err
+	}
+	return safeguard.NewRedactor(cfg.Inbound)
+}
+
+// checkChannelExposed returns an error when channelID is marked never to be
+// exposed to MCP clients.
+func checkChannelExposed(channelID string) error {
+	redactor, err := outputRedactor()
+	if err != nil {
+		return err
+	}
+	return redactor.CheckChannel(channelID)
+}
+
+// planMessage returns the dry-run plan for text after applying the
+// safeguards, so a blocked message fails the dry run too.
+func planMessage(channelID, text string, opts slackutil.PostOptions) (*slackutil.PostPlan, error) {
+	text, warnings, err := guardText(channelID, text)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := slackutil.PlanPost(channelID, text, opts)
+	if err != nil {
+		return nil, err
+	}
+	plan.Warnings = warnings
+	return plan, nil
+}
@@ -1,11 +1,10 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
-	"strings"
+	"io"
 
+	"github.com/tackeyy/slamy/internal/output"
 	slackutil "github.com/tackeyy/slamy/internal/slack"
 
 	"github.com/slack-go/slack"
@@ -62,57 +61,25 @@ var searchMessagesCmd = &cobra.Command{
 			return fmt.Errorf("search failed: %w", err)
 		}
 
-		if outputJSON {
-			type matchOut struct {
-				Ts        string `json:"ts"`
-				Channel   string `json:"channel"`
-				ChannelID string `json:"channel_id"`
-				User      string `json:"user"`
-				Text      string `json:"text"`
-				Permalink string `json:"permalink"`
-			}
-			out := struct {
-				Matches []matchOut `json:"matches"`
-				Total   int        `json:"total"`
-				Page    int        `json:"page"`
-			}{
-				Total: result.Total,
-				Page:  result.Paging.Page,
-			}
-			for _, m := range result.Matches {
-				out.Matches = append(out.Matches, matchOut{
-					Ts:        m.Timestamp,
-					Channel:   m.Channel.Name,
-					ChannelID: m.Channel.ID,
-					User:      m.User,
-					Text:      m.Text,
-					Permalink: m.Permalink,
-				})
-			}
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(out)
-		}
-
-		if outputPlain {
-			for _, m := range result.Matches {
-				text := strings.ReplaceAll(m.Text, "\n", "\\n")
-				fmt.Printf("%s\t%s\t%s\t%s\t%s\t%s\n",
-					m.Timestamp, m.Channel.ID, m.Channel.Name, m.User, text, m.Permalink)
-			}
-			return nil
-		}
+		out := slackutil.NewSearchResult(result)
 
-		fmt.Printf("Found %d results (page %d)\n\n", result.Total, result.Paging.Page)
-		for _, m := range result.Matches {
-			ts := formatTimestamp(m.Timestamp)
-			text := m.Text
-			if len(text) > 200 {
-				text = text[:200] + "..."
-			}
-			fmt.Printf("[%s] #%s %s:\n  %s\n  %s\n\n", ts, m.Channel.Name, m.User, text, m.Permalink)
-		}
-		return nil
+		return render(output.View{
+			Data:    out,
+			Rows:    out.Matches,
+			Columns: []string{"ts", "channel_id", "channel", "user", "text", "permalink"},
+			Text: func(w io.Writer) error {
+				fmt.Fprintf(w, "Found %d results (page %d)\n\n", out.Total, out.Page)
+				for _, m := range out.Matches {
+					ts := formatTimestamp(m.Ts)
+					text := m.Text
+					if len(text) > 200 {
+						text = text[:200] + "..."
+					}
+					fmt.Fprintf(w, "[%s] #%s %s:\n  %s\n  %s\n\n", ts, m.Channel, m.Author(), text, m.Permalink)
+				}
+				return nil
+			},
+		})
 	},
 }
 
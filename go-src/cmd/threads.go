@@ -1,10 +1,7 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
-	"strings"
 
 	slackutil "github.com/tackeyy/slamy/internal/slack"
 
@@ -46,38 +43,7 @@ var threadsRepliesCmd = &cobra.Command{
 			return fmt.Errorf("failed to get replies: %w", err)
 		}
 
-		if outputJSON {
-			type msgOut struct {
-				Ts   string `json:"ts"`
-				User string `json:"user"`
-				Text string `json:"text"`
-			}
-			out := make([]msgOut, len(msgs))
-			for i, msg := range msgs {
-				out[i] = msgOut{
-					Ts:   msg.Timestamp,
-					User: msg.User,
-					Text: msg.Text,
-				}
-			}
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(out)
-		}
-
-		if outputPlain {
-			for _, msg := range msgs {
-				text := strings.ReplaceAll(msg.Text, "\n", "\\n")
-				fmt.Printf("%s\t%s\t%s\n", msg.Timestamp, msg.User, text)
-			}
-			return nil
-		}
-
-		for _, msg := range msgs {
-			ts := formatTimestamp(msg.Timestamp)
-			fmt.Printf("[%s] %s: %s\n", ts, msg.User, msg.Text)
-		}
-		return nil
+		return render(messagesView(slackutil.NewMessages(msgs)))
 	},
 }
 
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/tackeyy/slamy/internal/output"
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+	"github.com/tackeyy/slamy/internal/state"
+
+	"github.com/slack-go/slack"
+	"github.com/spf13/cobra"
+)
+
+// Undo statuses.
+const (
+	undoDone    = "undone"
+	undoFailed  = "failed"
+	undoPlanned = "planned"
+)
+
+// undoResult reports what undo did for one audit log entry.
+type undoResult struct {
+	Seq      int      `json:"seq"`
+	Action   string   `json:"action"`
+	Channel  string   `json:"channel"`
+	Deleted  []string `json:"deleted,omitempty"`
+	FileID   string   `json:"file_id,omitempty"`
+	Reaction string   `json:"reaction,omitempty"`
+	TS       string   `json:"ts,omitempty"`
+	Status   string   `json:"status"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// alreadyGone lists the Slack errors that mean the target of an undo no
+// longer exists, so there is nothing left to revert.
+var alreadyGone = []string{"message_not_found", "no_reaction", "file_not_found", "file_deleted"}
+
+// undoWrites reverts slamy's own writes recorded in the audit log: the last
+// n writes, or the writes that posted the given timestamps. With dry set it
+// only reports what it would do.
+func undoWrites(api slackutil.SlackAPI, n int, timestamps []string, dry bool) ([]undoResult, error) {
+	auditLog, err := state.OpenAuditLog()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := auditLog.Entries()
+	if err != nil {
+		return nil, err
+	}
+	targets, err := undoTargets(entries, n, timestamps)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []undoResult{}
+	for _, e := range targets {
+		res := undoResult{Seq: e.Seq, Action: e.Action, Channel: e.Channel, FileID: e.FileID, Reaction: e.Reaction, Status: undoPlanned}
+		if e.Action == state.AuditReactionAdd {
+			res.TS = e.TS
+		} else {
+			res.Deleted = entryTimestamps(e)
+		}
+		if !dry {
+			res = undoEntry(api, e, res)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// undoable lists the audit actions undo can revert.
+var undoable = []string{state.AuditPost, state.AuditReply, state.AuditReactionAdd}
+
+// undoTargets picks the entries to undo, newest first. Entries that were
+// already undone, and entries undo cannot revert (undo entries themselves and
+// channel management), are never picked. Timestamps that slamy did not post
+// are refused.
+func undoTargets(entries []state.AuditEntry, n int, timestamps []string) ([]state.AuditEntry, error) {
+	undone := map[int]bool{}
+	for _, e := range entries {
+		if e.Action == state.AuditUndo {
+			undone[e.Undoes] = true
+		}
+	}
+	var candidates []state.AuditEntry
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if slices.Contains(undoable, e.Action) && !undone[e.Seq] {
+			candidates = append(candidates, e)
+		}
+	}
+
+	if len(timestamps) == 0 {
+		if n < 1 {
+			return nil, fmt.Errorf("--last must be at least 1")
+		}
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("nothing to undo: the audit log has no writes that have not been undone")
+		}
+		return candidates[:min(n, len(candidates))], nil
+	}
+
+	var targets []state.AuditEntry
+	for _, ts := range timestamps {
+		i := slices.IndexFunc(candidates, func(e state.AuditEntry) bool {
+			return e.Action != state.AuditReactionAdd && slices.Contains(entryTimestamps(e), ts)
+		})
+		if i < 0 {
+			for _, e := range entries {
+				if undone[e.Seq] && slices.Contains(entryTimestamps(e), ts) {
+					return nil, fmt.Errorf("message %s was already undone", ts)
+				}
+			}
+			return nil, fmt.Errorf("message %s was not posted by slamy; refusing to delete it", ts)
+		}
+		if !slices.ContainsFunc(targets, func(e state.AuditEntry) bool { return e.Seq == candidates[i].Seq }) {
+			targets = append(targets, candidates[i])
+		}
+	}
+	return targets, nil
+}
+
+// entryTimestamps returns every message posted by a post or reply entry.
+func entryTimestamps(e state.AuditEntry) []string {
+	if len(e.Timestamps) > 0 {
+		return e.Timestamps
+	}
+	if e.TS != "" {
+		return []string{e.TS}
+	}
+	return nil
+}
+
+// undoEntry reverts e and records the undo in the audit log when it
+// succeeds. Continuation messages are deleted before the message they are
+// threaded under.
+func undoEntry(api slackutil.SlackAPI, e state.AuditEntry, res undoResult) undoResult {
+	var err error
+	switch {
+	case e.Action == state.AuditReactionAdd:
+		err = ignoreGone(api.RemoveReaction(e.Reaction, slack.NewRefToMessage(e.Channel, e.TS)))
+		if err != nil {
+			err = fmt.Errorf("failed to remove reaction: %w", err)
+		}
+	case e.FileID != "":
+		err = ignoreGone(api.DeleteFile(e.FileID))
+		if err != nil {
+			err = fmt.Errorf("failed to delete file: %w", err)
+		}
+	default:
+		timestamps := res.Deleted
+		for i := len(timestamps) - 1; i >= 0 && err == nil; i-- {
+			_, _, err = api.DeleteMessage(e.Channel, timestamps[i])
+			if err = ignoreGone(err); err != nil {
+				err = fmt.Errorf("failed to delete message %s: %w", timestamps[i], err)
+			}
+		}
+	}
+
+	if err != nil {
+		res.Status = undoFailed
+		res.Error = err.Error()
+		return res
+	}
+	res.Status = undoDone
+	recordWrite(state.AuditEntry{Action: state.AuditUndo, Channel: e.Channel, TS: e.TS, Undoes: e.Seq}, "")
+	return res
+}
+
+// ignoreGone treats errors meaning the target no longer exists as success.
+func ignoreGone(err error) error {
+	if err != nil && slices.Contains(alreadyGone, err.Error()) {
+		return nil
+	}
+	return err
+}
+
+// undoResultsView renders the results of undo.
+func undoResultsView(results []undoResult) output.View {
+	return output.View{
+		Data:    results,
+		Columns: []string{"seq", "action", "channel", "status", "error"},
+		Text: func(w io.Writer) error {
+			for _, res := range results {
+				var what string
+				switch {
+				case res.Reaction != "":
+					what = fmt.Sprintf("remove :%s: from %s in %s", res.Reaction, res.TS, res.Channel)
+				case res.FileID != "":
+					what = fmt.Sprintf("delete file %s in %s", res.FileID, res.Channel)
+				default:
+					what = fmt.Sprintf("delete %s in %s", strings.Join(res.Deleted, ", "), res.Channel)
+				}
+				switch res.Status {
+				case undoPlanned:
+					fmt.Fprintf(w, "Dry run: would %s (#%d %s)\n", what, res.Seq, res.Action)
+				case undoFailed:
+					fmt.Fprintf(w, "Failed to %s (#%d %s): %s\n", what, res.Seq, res.Action, res.Error)
+				default:
+					fmt.Fprintf(w, "Undone #%d %s: %s\n", res.Seq, res.Action, what)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert recent writes made by slamy",
+	Long: "Revert writes recorded in slamy's audit log: delete posted messages (every message of a split post)\n" +
+		"and uploaded snippets, and remove added reactions. Messages slamy did not post are never touched.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		last, err := cmd.Flags().GetInt("last")
+		if err != nil {
+			return fmt.Errorf("failed to get last flag: %w", err)
+		}
+		timestamps, err := cmd.Flags().GetStringSlice("ts")
+		if err != nil {
+			return fmt.Errorf("failed to get ts flag: %w", err)
+		}
+		if cmd.Flags().Changed("last") && len(timestamps) > 0 {
+			return fmt.Errorf("--last and --ts cannot be used together")
+		}
+
+		var api slackutil.SlackAPI
+		if !dryRun {
+			client, err := slackutil.NewClient()
+			if err != nil {
+				return err
+			}
+			api = client.User
+		}
+
+		results, err := undoWrites(api, last, timestamps, dryRun)
+		if err != nil {
+			return err
+		}
+		if err := render(undoResultsView(results)); err != nil {
+			return err
+		}
+		for _, res := range results {
+			if res.Status == undoFailed {
+				return fmt.Errorf("some writes could not be undone")
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	undoCmd.Flags().Int("last", 1, "Undo the last N writes")
+	undoCmd.Flags().StringSlice("ts", nil, "Undo the posts that created these message timestamps")
+
+	rootCmd.AddCommand(undoCmd)
+}
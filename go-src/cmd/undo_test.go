@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	slackapi "github.com/slack-go/slack"
+
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+	"github.com/tackeyy/slamy/internal/state"
+)
+
+// seedAuditLog appends entries to a fresh audit log.
+func seedAuditLog(t *testing.T, entries ...state.AuditEntry) {
+	t.Helper()
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	auditLog, err := state.OpenAuditLog()
+	if err != nil {
+		t.Fatalf("OpenAuditLog: %v", err)
+	}
+	for _, e := range entries {
+		if e.Origin == "" {
+			e.Origin = state.OriginCLI
+		}
+		if _, err := auditLog.Append(e); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+}
+
+// deletingAPI records deleted messages as "channel:ts".
+func deletingAPI(deleted *[]string) *slackutil.MockSlackAPI {
+	return &slackutil.MockSlackAPI{
+		DeleteMessageFunc: func(channelID, timestamp string) (string, string, error) {
+			*deleted = append(*deleted, channelID+":"+timestamp)
+			return channelID, timestamp, nil
+		},
+	}
+}
+
+func TestUndoWrites_DeletesEveryChunkNewestFirst(t *testing.T) {
+	seedAuditLog(t,
+		state.AuditEntry{Action: state.AuditPost, Channel: "C001", TS: "1.0"},
+		state.AuditEntry{Action: state.AuditPost, Channel: "C002", TS: "2.0", Timestamps: []string{"2.0", "2.1", "2.2"}},
+	)
+	var deleted []string
+
+	results, err := undoWrites(deletingAPI(&deleted), 1, nil, false)
+
+	if err != nil {
+		t.Fatalf("undoWrites: %v", err)
+	}
+	if got := strings.Join(deleted, ","); got != "C002:2.2,C002:2.1,C002:2.0" {
+		t.Errorf("deleted = %s", got)
+	}
+	if len(results) != 1 || results[0].Seq != 2 || results[0].Status != undoDone {
+		t.Errorf("results = %+v", results)
+	}
+	entries := auditEntries(t)
+	if last := entries[len(entries)-1]; last.Action != state.AuditUndo || last.Undoes != 2 {
+		t.Errorf("last audit entry = %+v", last)
+	}
+
+	// The next undo moves on to the earlier post.
+	deleted = nil
+	if _, err := undoWrites(deletingAPI(&deleted), 1, nil, false); err != nil {
+		t.Fatalf("undoWrites: %v", err)
+	}
+	if got := strings.Join(deleted, ","); got != "C001:1.0" {
+		t.Errorf("deleted = %s", got)
+	}
+	if _, err := undoWrites(deletingAPI(&deleted), 1, nil, false); err == nil || !strings.Contains(err.Error(), "nothing to undo") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestUndoWrites_ByTimestamp(t *testing.T) {
+	seedAuditLog(t,
+		state.AuditEntry{Action: state.AuditPost, Channel: "C001", TS: "1.0", Timestamps: []string{"1.0", "1.1"}},
+		state.AuditEntry{Action: state.AuditReply, Channel: "C001", TS: "3.0", ThreadTS: "1.0"},
+	)
+	var deleted []string
+
+	_, err := undoWrites(deletingAPI(&deleted), 0, []string{"1.1"}, false)
+
+	if err != nil {
+		t.Fatalf("undoWrites: %v", err)
+	}
+	if got := strings.Join(deleted, ","); got != "C001:1.1,C001:1.0" {
+		t.Errorf("deleted = %s", got)
+	}
+	if _, err := undoWrites(deletingAPI(&deleted), 0, []string{"1.0"}, false); err == nil || !strings.Contains(err.Error(), "already undone") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestUndoWrites_RefusesForeignMessages(t *testing.T) {
+	seedAuditLog(t, state.AuditEntry{Action: state.AuditPost, Channel: "C001", TS: "1.0"})
+
+	_, err := undoWrites(&slackutil.MockSlackAPI{}, 0, []string{"9.9"}, false)
+
+	if err == nil || !strings.Contains(err.Error(), "not posted by slamy") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestUndoWrites_Reaction(t *testing.T) {
+	seedAuditLog(t, state.AuditEntry{Action: state.AuditReactionAdd, Channel: "C001", TS: "1.0", Reaction: "eyes"})
+	var removed string
+	api := &slackutil.MockSlackAPI{
+		RemoveReactionFunc: func(name string, ref slackapi.ItemRef) error {
+			removed = name + "@" + ref.Channel + ":" + ref.Timestamp
+			return nil
+		},
+	}
+
+	results, err := undoWrites(api, 1, nil, false)
+
+	if err != nil {
+		t.Fatalf("undoWrites: %v", err)
+	}
+	if removed != "eyes@C001:1.0" || results[0].Status != undoDone {
+		t.Errorf("removed = %q, results = %+v", removed, results)
+	}
+}
+
+func TestUndoWrites_AlreadyDeleted(t *testing.T) {
+	seedAuditLog(t, state.AuditEntry{Action: state.AuditPost, Channel: "C001", TS: "1.0"})
+	api := &slackutil.MockSlackAPI{
+		DeleteMessageFunc: func(channelID, timestamp string) (string, string, error) {
+			return "", "", errors.New("message_not_found")
+		},
+	}
+
+	results, err := undoWrites(api, 1, nil, false)
+
+	if err != nil || results[0].Status != undoDone {
+		t.Errorf("results = %+v, err = %v", results, err)
+	}
+}
+
+func TestUndoWrites_FailureNotRecorded(t *testing.T) {
+	seedAuditLog(t, state.AuditEntry{Action: state.AuditPost, Channel: "C001", TS: "1.0"})
+	api := &slackutil.MockSlackAPI{
+		DeleteMessageFunc: func(channelID, timestamp string) (string, string, error) {
+			return "", "", errors.New("cant_delete_message")
+		},
+	}
+
+	results, err := undoWrites(api, 1, nil, false)
+
+	if err != nil {
+		t.Fatalf("undoWrites: %v", err)
+	}
+	if results[0].Status != undoFailed || !strings.Contains(results[0].Error, "cant_delete_message") {
+		t.Errorf("results = %+v", results)
+	}
+	if entries := auditEntries(t); len(entries) != 1 {
+		t.Errorf("undo of a failed delete was recorded: %+v", entries)
+	}
+}
+
+func TestHandleUndo_DryRun(t *testing.T) {
+	seedAuditLog(t, state.AuditEntry{Action: state.AuditPost, Channel: "C001", TS: "1.0", Timestamps: []string{"1.0", "1.1"}})
+	// Any Slack call panics: the mock has no functions set.
+	cleanup := setMockClient(&slackutil.MockSlackAPI{})
+	defer cleanup()
+
+	result, err := handleUndo(context.Background(), makeRequest(map[string]any{"dry_run": true}))
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []undoResult
+	if err := json.Unmarshal([]byte(resultText(t, result)), &got); err != nil {
+		t.Fatalf("failed to parse result JSON: %v", err)
+	}
+	if len(got) != 1 || got[0].Status != undoPlanned || strings.Join(got[0].Deleted, ",") != "1.0,1.1" {
+		t.Errorf("results = %+v", got)
+	}
+	if entries := auditEntries(t); len(entries) != 1 {
+		t.Errorf("dry run was recorded: %+v", entries)
+	}
+}
+
+func TestHandleUndo_PostedMessage(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	var deleted []string
+	mock := deletingAPI(&deleted)
+	mock.PostMessageFunc = func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+		return channelID, "1675382400.000000", nil
+	}
+	cleanup := setMockClient(mock)
+	defer cleanup()
+
+	if _, err := handlePostMessage(context.Background(), makeRequest(map[string]any{"channel_id": "C001", "text": "oops"})); err != nil {
+		t.Fatal(err)
+	}
+	result, err := handleUndo(context.Background(), makeRequest(map[string]any{"ts": "1675382400.000000"}))
+
+	if err != nil || isErrorResult(result) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(deleted, ",") != "C001:1675382400.000000" {
+		t.Errorf("deleted = %v", deleted)
+	}
+}
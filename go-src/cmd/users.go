@@ -1,10 +1,10 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
+	"io"
 
+	"github.com/tackeyy/slamy/internal/output"
 	slackutil "github.com/tackeyy/slamy/internal/slack"
 
 	"github.com/spf13/cobra"
@@ -39,16 +39,7 @@ var usersListCmd = &cobra.Command{
 			return fmt.Errorf("failed to get include-bots flag: %w", err)
 		}
 
-		var filtered []struct {
-			ID          string `json:"id"`
-			Name        string `json:"name"`
-			RealName    string `json:"real_name"`
-			DisplayName string `json:"display_name"`
-			Email       string `json:"email,omitempty"`
-			IsBot       bool   `json:"is_bot"`
-			Deleted     bool   `json:"deleted"`
-		}
-
+		filtered := []slackutil.User{}
 		for _, u := range users {
 			if !includeBots && u.IsBot {
 				continue
@@ -56,46 +47,19 @@ var usersListCmd = &cobra.Command{
 			if !includeDeactivated && u.Deleted {
 				continue
 			}
-			filtered = append(filtered, struct {
-				ID          string `json:"id"`
-				Name        string `json:"name"`
-				RealName    string `json:"real_name"`
-				DisplayName string `json:"display_name"`
-				Email       string `json:"email,omitempty"`
-				IsBot       bool   `json:"is_bot"`
-				Deleted     bool   `json:"deleted"`
-			}{
-				ID:          u.ID,
-				Name:        u.Name,
-				RealName:    u.RealName,
-				DisplayName: u.Profile.DisplayName,
-				Email:       u.Profile.Email,
-				IsBot:       u.IsBot,
-				Deleted:     u.Deleted,
-			})
-		}
-
-		if outputJSON {
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(filtered)
-		}
-
-		if outputPlain {
-			for _, u := range filtered {
-				fmt.Printf("%s\t%s\t%s\t%s\t%s\n", u.ID, u.Name, u.RealName, u.DisplayName, u.Email)
-			}
-			return nil
-		}
-
-		for _, u := range filtered {
-			display := u.DisplayName
-			if display == "" {
-				display = u.RealName
-			}
-			fmt.Printf("%-12s @%-20s %s\n", u.ID, u.Name, display)
-		}
-		return nil
+			filtered = append(filtered, slackutil.NewUser(u))
+		}
+
+		return render(output.View{
+			Data:    filtered,
+			Columns: []string{"id", "name", "real_name", "display_name", "email"},
+			Text: func(w io.Writer) error {
+				for _, u := range filtered {
+					fmt.Fprintf(w, "%-12s @%-20s %s\n", u.ID, u.Name, u.DisplayNameOrReal())
+				}
+				return nil
+			},
+		})
 	},
 }
 
@@ -116,50 +80,31 @@ var usersProfileCmd = &cobra.Command{
 			return fmt.Errorf("failed to get user profile: %w", err)
 		}
 
-		if outputJSON {
-			out := map[string]interface{}{
-				"id":           user.ID,
-				"name":         user.Name,
-				"real_name":    user.RealName,
-				"display_name": user.Profile.DisplayName,
-				"email":        user.Profile.Email,
-				"title":        user.Profile.Title,
-				"phone":        user.Profile.Phone,
-				"status_text":  user.Profile.StatusText,
-				"status_emoji": user.Profile.StatusEmoji,
-				"tz":           user.TZ,
-				"is_admin":     user.IsAdmin,
-				"is_bot":       user.IsBot,
-				"deleted":      user.Deleted,
-			}
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(out)
-		}
-
-		if outputPlain {
-			fmt.Printf("%s\t%s\t%s\t%s\t%s\t%s\n",
-				user.ID, user.Name, user.RealName, user.Profile.DisplayName, user.Profile.Email, user.Profile.Title)
-			return nil
-		}
-
-		display := user.Profile.DisplayName
-		if display == "" {
-			display = user.RealName
-		}
-		fmt.Printf("User: %s (@%s)\n", display, user.Name)
-		fmt.Printf("ID: %s\n", user.ID)
-		if user.Profile.Title != "" {
-			fmt.Printf("Title: %s\n", user.Profile.Title)
-		}
-		if user.Profile.Email != "" {
-			fmt.Printf("Email: %s\n", user.Profile.Email)
-		}
-		if user.Profile.StatusText != "" {
-			fmt.Printf("Status: %s %s\n", user.Profile.StatusEmoji, user.Profile.StatusText)
-		}
-		fmt.Printf("Timezone: %s\n", user.TZ)
-		return nil
+		profile := slackutil.NewUserProfile(user)
+
+		return render(output.View{
+			Data:    profile,
+			Columns: []string{"id", "name", "real_name", "display_name", "email", "title"},
+			Text: func(w io.Writer) error {
+				display := profile.DisplayName
+				if display == "" {
+					display = profile.RealName
+				}
+				fmt.Fprintf(w, "User: %s (@%s)\n", display, profile.Name)
+				fmt.Fprintf(w, "ID: %s\n", profile.ID)
+				if profile.Title != "" {
+					fmt.Fprintf(w, "Title: %s\n", profile.Title)
+				}
+				if profile.Email != "" {
+					fmt.Fprintf(w, "Email: %s\n", profile.Email)
+				}
+				if profile.StatusText != "" {
+					fmt.Fprintf(w, "Status: %s %s\n", profile.StatusEmoji, profile.StatusText)
+				}
+				fmt.Fprintf(w, "Timezone: %s\n", profile.TZ)
+				return nil
+			},
+		})
 	},
 }
 
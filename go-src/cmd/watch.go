@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tackeyy/slamy/internal/output"
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+	"github.com/tackeyy/slamy/internal/state"
+
+	"github.com/slack-go/slack"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// watchMaxInterval caps the polling interval while backing off.
+	watchMaxInterval = 10 * time.Minute
+	// watchMaxThreads caps the threads followed per channel; the ones with
+	// the oldest activity are dropped first.
+	watchMaxThreads = 20
+)
+
+// watchedChannel is a conversation followed by `slamy watch`.
+type watchedChannel struct {
+	slackutil.Channel
+	cp state.WatchCheckpoint
+}
+
+// resolveWatchChannels looks up the conversations refs refer to: IDs, or
+// names (with or without "#") of channels the authenticated user is in.
+func resolveWatchChannels(api slackutil.SlackAPI, refs []string) ([]*watchedChannel, error) {
+	var byName map[string]slack.Channel
+	out := make([]*watchedChannel, 0, len(refs))
+	for _, ref := range refs {
+		if reConversationID.MatchString(ref) {
+			ch, err := api.GetConversationInfo(&slack.GetConversationInfoInput{ChannelID: ref})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get channel info for %s: %w", ref, err)
+			}
+			out = append(out, &watchedChannel{Channel: slackutil.NewChannel(*ch)})
+			continue
+		}
+
+		if byName == nil {
+			authResp, err := api.AuthTest()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get auth info: %w", err)
+			}
+			channels, err := memberChannels(api, authResp.UserID, channelTypes, 0, false)
+			if err != nil {
+				return nil, err
+			}
+			byName = make(map[string]slack.Channel, len(channels))
+			for _, ch := range channels {
+				byName[ch.Name] = ch
+			}
+		}
+		ch, ok := byName[strings.TrimPrefix(ref, "#")]
+		if !ok {
+			return nil, fmt.Errorf("you are not a member of a channel named %s", ref)
+		}
+		out = append(out, &watchedChannel{Channel: slackutil.NewChannel(ch)})
+	}
+	return out, nil
+}
+
+// pollChannel returns the messages posted in c since its checkpoint and,
+// when threads is set, the new replies in the threads it follows, oldest
+// first. c's checkpoint is only advanced when the whole poll succeeds.
+func pollChannel(api slackutil.SlackAPI, c *watchedChannel, threads bool) ([]slackutil.Message, error) {
+	cp := state.WatchCheckpoint{TS: c.cp.TS, Threads: map[string]string{}}
+	for thread, ts := range c.cp.Threads {
+		cp.Threads[thread] = ts
+	}
+
+	var msgs []slackutil.Message
+	params := &slack.GetConversationHistoryParameters{ChannelID: c.ID, Oldest: c.cp.TS, Limit: unreadPageSize}
+	for {
+		resp, err := api.GetConversationHistory(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get history of %s: %w", c.Label(), err)
+		}
+		for _, msg := range resp.Messages {
+			if slackutil.CompareTS(msg.Timestamp, cp.TS) > 0 {
+				cp.TS = msg.Timestamp
+			}
+			isReply := msg.ThreadTimestamp != "" && msg.ThreadTimestamp != msg.Timestamp
+			if !threads {
+				msgs = append(msgs, slackutil.NewMessage(msg))
+				continue
+			}
+			if isReply {
+				// A reply also sent to the channel is printed with its thread.
+				if _, ok := cp.Threads[msg.ThreadTimestamp]; !ok {
+					cp.Threads[msg.ThreadTimestamp] = c.cp.TS
+				}
+				continue
+			}
+			msgs = append(msgs, slackutil.NewMessage(msg))
+			cp.Threads[msg.Timestamp] = msg.Timestamp
+		}
+		if !resp.HasMore || resp.ResponseMetaData.NextCursor == "" {
+			break
+		}
+		params.Cursor = resp.ResponseMetaData.NextCursor
+	}
+
+	if threads {
+		trimThreads(cp.Threads)
+		for thread, last := range cp.Threads {
+			replies, err := newReplies(api, c.ID, thread, last)
+			if err != nil {
+				return nil, err
+			}
+			for _, reply := range replies {
+				msgs = append(msgs, slackutil.NewMessage(reply))
+				if slackutil.CompareTS(reply.Timestamp, cp.Threads[thread]) > 0 {
+					cp.Threads[thread] = reply.Timestamp
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(msgs, func(i, j int) bool { return slackutil.CompareTS(msgs[i].Ts, msgs[j].Ts) < 0 })
+	for i := range msgs {
+		msgs[i].ChannelID = c.ID
+		msgs[i].Channel = c.Name
+	}
+	c.cp = cp
+	return msgs, nil
+}
+
+// newReplies returns the replies in thread posted after the ts last.
+func newReplies(api slackutil.SlackAPI, channelID, thread, last string) ([]slack.Message, error) {
+	var out []slack.Message
+	params := &slack.GetConversationRepliesParameters{ChannelID: channelID, Timestamp: thread, Oldest: last, Limit: unreadPageSize}
+	for {
+		msgs, hasMore, cursor, err := api.GetConversationReplies(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get replies to %s in %s: %w", thread, channelID, err)
+		}
+		for _, msg := range msgs {
+			if msg.Timestamp != thread && slackutil.CompareTS(msg.Timestamp, last) > 0 {
+				out = append(out, msg)
+			}
+		}
+		if !hasMore || cursor == "" {
+			return out, nil
+		}
+		params.Cursor = cursor
+	}
+}
+
+// trimThreads drops the threads with the oldest activity beyond
+// watchMaxThreads.
+func trimThreads(threads map[string]string) {
+	if len(threads) <= watchMaxThreads {
+		return
+	}
+	roots := make([]string, 0, len(threads))
+	for thread := range threads {
+		roots = append(roots, thread)
+	}
+	sort.Slice(roots, func(i, j int) bool { return slackutil.CompareTS(threads[roots[i]], threads[roots[j]]) > 0 })
+	for _, thread := range roots[watchMaxThreads:] {
+		delete(threads, thread)
+	}
+}
+
+// watchInterval returns the delay before the next poll. It doubles the
+// current delay after a failed poll, waiting at least as long as Slack's
+// Retry-After when rate limited, and halves it back towards base after a
+// successful one.
+func watchInterval(base, current time.Duration, err error) time.Duration {
+	if err == nil {
+		return max(base, current/2)
+	}
+	next := min(current*2, max(base, watchMaxInterval))
+	var rateLimited *slack.RateLimitedError
+	if errors.As(err, &rateLimited) {
+		next = max(next, rateLimited.RetryAfter)
+	}
+	return next
+}
+
+// watchView renders new messages, prefixed with their conversation.
+func watchView(c *watchedChannel, msgs []slackutil.Message) output.View {
+	return output.View{
+		Data:    msgs,
+		Columns: []string{"channel_id", "ts", "thread_ts", "user", "text"},
+		Text: func(w io.Writer) error {
+			for _, msg := range msgs {
+				label := c.Label()
+				if msg.ThreadTs != "" && msg.ThreadTs != msg.Ts {
+					label += " (reply)"
+				}
+				fmt.Fprintf(w, "%s %s\n", label, formatMessageLine(msg))
+			}
+			return nil
+		},
+	}
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch <channel...>",
+	Short: "Print new messages in channels as they arrive, by polling",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interval, err := cmd.Flags().GetDuration("interval")
+		if err != nil {
+			return fmt.Errorf("failed to get interval flag: %w", err)
+		}
+		if interval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+		threads, err := cmd.Flags().GetBool("threads")
+		if err != nil {
+			return fmt.Errorf("failed to get threads flag: %w", err)
+		}
+
+		client, err := slackutil.NewClient()
+		if err != nil {
+			return err
+		}
+		channels, err := resolveWatchChannels(client.User, args)
+		if err != nil {
+			return err
+		}
+		store, err := state.OpenWatchCheckpoints()
+		if err != nil {
+			return err
+		}
+		start := fmt.Sprintf("%d.000000", time.Now().Unix())
+		for _, c := range channels {
+			cp, err := store.Get(c.ID)
+			if err != nil {
+				return err
+			}
+			if cp != nil {
+				c.cp = *cp
+			} else {
+				c.cp.TS = start
+			}
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		delay := interval
+		for {
+			var pollErr error
+			for _, c := range channels {
+				msgs, err := pollChannel(client.User, c, threads)
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", err)
+					pollErr = err
+					var rateLimited *slack.RateLimitedError
+					if errors.As(err, &rateLimited) {
+						break
+					}
+					continue
+				}
+				if len(msgs) > 0 {
+					if err := render(watchView(c, msgs)); err != nil {
+						return err
+					}
+				}
+				if err := store.Put(c.ID, c.cp); err != nil {
+					return err
+				}
+			}
+			delay = watchInterval(interval, delay, pollErr)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(delay):
+			}
+		}
+	},
+}
+
+func init() {
+	watchCmd.Flags().Duration("interval", 30*time.Second, "Time between polls (backs off under rate limiting)")
+	watchCmd.Flags().Bool("threads", false, "Also print new replies in threads started or active while watching")
+
+	rootCmd.AddCommand(watchCmd)
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	slackapi "github.com/slack-go/slack"
+
+	slackutil "github.com/tackeyy/slamy/internal/slack"
+	"github.com/tackeyy/slamy/internal/state"
+)
+
+func watchMsg(ts, threadTS, text string) slackapi.Message {
+	return slackapi.Message{Msg: slackapi.Msg{Timestamp: ts, ThreadTimestamp: threadTS, User: "U001", Text: text}}
+}
+
+func TestPollChannel(t *testing.T) {
+	var historyOldest []string
+	api := &slackutil.MockSlackAPI{
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			historyOldest = append(historyOldest, params.Oldest)
+			if params.Cursor == "" {
+				resp := &slackapi.GetConversationHistoryResponse{HasMore: true, Messages: []slackapi.Message{watchMsg("300.0", "", "third")}}
+				resp.ResponseMetaData.NextCursor = "next"
+				return resp, nil
+			}
+			return &slackapi.GetConversationHistoryResponse{Messages: []slackapi.Message{watchMsg("200.0", "", "second")}}, nil
+		},
+	}
+	c := &watchedChannel{cp: state.WatchCheckpoint{TS: "100.0"}}
+	c.ID, c.Name = "C001", "dev"
+
+	msgs, err := pollChannel(api, c, false)
+
+	if err != nil {
+		t.Fatalf("pollChannel: %v", err)
+	}
+	if len(msgs) != 2 || msgs[0].Text != "second" || msgs[1].Text != "third" || msgs[0].ChannelID != "C001" || msgs[0].Channel != "dev" {
+		t.Errorf("msgs = %+v", msgs)
+	}
+	if strings.Join(historyOldest, ",") != "100.0,100.0" || c.cp.TS != "300.0" {
+		t.Errorf("oldest = %v, checkpoint = %+v", historyOldest, c.cp)
+	}
+}
+
+func TestPollChannel_Threads(t *testing.T) {
+	var repliesTo []string
+	api := &slackutil.MockSlackAPI{
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			return &slackapi.GetConversationHistoryResponse{Messages: []slackapi.Message{
+				watchMsg("300.0", "50.0", "broadcast reply"),
+				watchMsg("200.0", "", "new root"),
+			}}, nil
+		},
+		GetConversationRepliesFunc: func(params *slackapi.GetConversationRepliesParameters) ([]slackapi.Message, bool, string, error) {
+			repliesTo = append(repliesTo, params.Timestamp+">"+params.Oldest)
+			switch params.Timestamp {
+			case "50.0":
+				return []slackapi.Message{watchMsg("50.0", "50.0", "old root"), watchMsg("300.0", "50.0", "broadcast reply")}, false, "", nil
+			case "80.0":
+				return []slackapi.Message{watchMsg("80.0", "80.0", "followed root"), watchMsg("90.0", "80.0", "seen"), watchMsg("250.0", "80.0", "new reply")}, false, "", nil
+			}
+			return []slackapi.Message{watchMsg("200.0", "200.0", "new root")}, false, "", nil
+		},
+	}
+	c := &watchedChannel{cp: state.WatchCheckpoint{TS: "100.0", Threads: map[string]string{"80.0": "90.0"}}}
+	c.ID, c.Name = "C001", "dev"
+
+	msgs, err := pollChannel(api, c, true)
+
+	if err != nil {
+		t.Fatalf("pollChannel: %v", err)
+	}
+	var got []string
+	for _, m := range msgs {
+		got = append(got, m.Text)
+	}
+	if strings.Join(got, ",") != "new root,new reply,broadcast reply" {
+		t.Errorf("msgs = %v", got)
+	}
+	if len(repliesTo) != 3 {
+		t.Errorf("replies fetched = %v", repliesTo)
+	}
+	want := map[string]string{"50.0": "300.0", "80.0": "250.0", "200.0": "200.0"}
+	for thread, ts := range want {
+		if c.cp.Threads[thread] != ts {
+			t.Errorf("Threads = %v, want %v", c.cp.Threads, want)
+			break
+		}
+	}
+}
+
+func TestPollChannel_ErrorKeepsCheckpoint(t *testing.T) {
+	api := &slackutil.MockSlackAPI{
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			return &slackapi.GetConversationHistoryResponse{Messages: []slackapi.Message{watchMsg("200.0", "", "root")}}, nil
+		},
+		GetConversationRepliesFunc: func(params *slackapi.GetConversationRepliesParameters) ([]slackapi.Message, bool, string, error) {
+			return nil, false, "", errors.New("boom")
+		},
+	}
+	c := &watchedChannel{cp: state.WatchCheckpoint{TS: "100.0"}}
+	c.ID = "C001"
+
+	if _, err := pollChannel(api, c, true); err == nil {
+		t.Fatal("expected error")
+	}
+	if c.cp.TS != "100.0" || len(c.cp.Threads) != 0 {
+		t.Errorf("checkpoint = %+v", c.cp)
+	}
+}
+
+func TestTrimThreads(t *testing.T) {
+	threads := map[string]string{}
+	for i := 0; i < watchMaxThreads+2; i++ {
+		ts := fmt.Sprintf("%d.0", 100+i)
+		threads[ts] = ts
+	}
+
+	trimThreads(threads)
+
+	if len(threads) != watchMaxThreads {
+		t.Fatalf("len = %d", len(threads))
+	}
+	if _, ok := threads["100.0"]; ok {
+		t.Errorf("oldest thread kept: %v", threads)
+	}
+}
+
+func TestWatchInterval(t *testing.T) {
+	base := 30 * time.Second
+	tests := []struct {
+		name    string
+		current time.Duration
+		err     error
+		want    time.Duration
+	}{
+		{"success at base", base, nil, base},
+		{"recovering", 4 * base, nil, 2 * base},
+		{"failure doubles", base, errors.New("boom"), 2 * base},
+		{"capped", watchMaxInterval, errors.New("boom"), watchMaxInterval},
+		{"retry-after", base, &slackapi.RateLimitedError{RetryAfter: 5 * time.Minute}, 5 * time.Minute},
+		{"retry-after below backoff", 4 * base, &slackapi.RateLimitedError{RetryAfter: time.Second}, 8 * base},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := watchInterval(base, tt.current, tt.err); got != tt.want {
+				t.Errorf("watchInterval = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveWatchChannels(t *testing.T) {
+	dev := slackapi.Channel{}
+	dev.ID, dev.Name = "C001", "dev"
+	api := &slackutil.MockSlackAPI{
+		GetConversationInfoFunc: func(input *slackapi.GetConversationInfoInput) (*slackapi.Channel, error) {
+			ch := slackapi.Channel{}
+			ch.ID, ch.IsIM, ch.User = input.ChannelID, true, "U0BOB"
+			return &ch, nil
+		},
+		AuthTestFunc: func() (*slackapi.AuthTestResponse, error) {
+			return &slackapi.AuthTestResponse{UserID: "UME"}, nil
+		},
+		GetConversationsForUserFunc: func(params *slackapi.GetConversationsForUserParameters) ([]slackapi.Channel, string, error) {
+			return []slackapi.Channel{dev}, "", nil
+		},
+	}
+
+	channels, err := resolveWatchChannels(api, []string{"#dev", "D001"})
+
+	if err != nil {
+		t.Fatalf("resolveWatchChannels: %v", err)
+	}
+	if len(channels) != 2 || channels[0].Label() != "#dev" || channels[1].Label() != "@U0BOB" {
+		t.Errorf("channels = %+v", channels)
+	}
+	if _, err := resolveWatchChannels(api, []string{"random"}); err == nil || !strings.Contains(err.Error(), "random") {
+		t.Errorf("err = %v", err)
+	}
+}
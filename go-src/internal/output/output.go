@@ -0,0 +1,545 @@
+// Package output renders command results in the formats selected with
+// --output: human-readable text, JSON, NDJSON, TSV, CSV, YAML, aligned tables
+// and Go templates.
+//
+// Commands hand a View built from the shared view models to Render instead of
+// formatting each output mode themselves, so column selection, headers and
+// escaping behave the same for every command.
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported output formats.
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatNDJSON   = "ndjson"
+	FormatTSV      = "tsv"
+	FormatCSV      = "csv"
+	FormatYAML     = "yaml"
+	FormatTable    = "table"
+	FormatTemplate = "template"
+)
+
+// Formats lists every supported output format in help-text order.
+var Formats = []string{FormatText, FormatJSON, FormatNDJSON, FormatTSV, FormatCSV, FormatYAML, FormatTable, FormatTemplate}
+
+// Options holds the user-selected rendering options.
+type Options struct {
+	// Format is one of Formats. Empty means FormatText.
+	Format string
+	// Template is the text/template source used with FormatTemplate.
+	// It is executed once per row with the row's JSON fields as a map.
+	Template string
+	// Fields restricts and orders the columns of row-oriented output.
+	// When set, JSON and YAML output also contain only the selected fields.
+	Fields []string
+	// Header prints a header line in TSV output. CSV and table output
+	// always include a header.
+	Header bool
+	// Query is a jq expression applied to the JSON view model before
+	// rendering. Its results replace both Data and Rows.
+	Query string
+}
+
+// View describes a command result.
+type View struct {
+	// Data is the complete result, rendered as-is by JSON and YAML.
+	Data any
+	// Rows is the value rendered by row-oriented formats (TSV, CSV, table,
+	// NDJSON, template). It must marshal to a JSON array or object.
+	// Defaults to Data.
+	Rows any
+	// Columns are the default columns for TSV, CSV and table output.
+	Columns []string
+	// Text renders the human-readable default output. When nil, text
+	// output falls back to an aligned table.
+	Text func(w io.Writer) error
+}
+
+// Validate reports whether opts describes a supported configuration.
+func (opts Options) Validate() error {
+	switch opts.format() {
+	case FormatText, FormatJSON, FormatNDJSON, FormatTSV, FormatCSV, FormatYAML, FormatTable:
+	case FormatTemplate:
+		if opts.Template == "" {
+			return fmt.Errorf("--output template requires --template")
+		}
+		if _, err := parseTemplate(opts.Template); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown output format %q (supported: %s)", opts.Format, strings.Join(Formats, ", "))
+	}
+	if opts.Query != "" {
+		if _, err := compileQuery(opts.Query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (opts Options) format() string {
+	if opts.Format == "" {
+		return FormatText
+	}
+	return opts.Format
+}
+
+// Render writes v to w in the format selected by opts.
+func Render(w io.Writer, opts Options, v View) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	format := opts.format()
+	if opts.Query != "" {
+		results, err := applyQuery(opts.Query, v.Data)
+		if err != nil {
+			return err
+		}
+		// Query results have their own shape: the command's text renderer
+		// and default columns no longer apply, and text output becomes JSON.
+		if format == FormatText {
+			opts.Format = FormatJSON
+		}
+		return renderResults(w, opts, results)
+	}
+	if format == FormatText && v.Text != nil && len(opts.Fields) == 0 {
+		return v.Text(w)
+	}
+
+	rowsValue := v.Rows
+	if rowsValue == nil {
+		rowsValue = v.Data
+	}
+	rows, list, err := toRows(rowsValue)
+	if err != nil {
+		return err
+	}
+	columns := opts.Fields
+	if len(columns) == 0 {
+		columns = v.Columns
+	}
+	if len(columns) == 0 {
+		columns = inferColumns(rows)
+	}
+
+	switch format {
+	case FormatJSON, FormatYAML:
+		data := v.Data
+		if len(opts.Fields) > 0 {
+			data = project(rows, list, opts.Fields)
+		}
+		if format == FormatJSON {
+			return writeJSON(w, data)
+		}
+		return writeYAML(w, data)
+	case FormatNDJSON:
+		for _, row := range rows {
+			fields := row.keys
+			if len(opts.Fields) > 0 {
+				fields = opts.Fields
+			}
+			b, err := json.Marshal(row.project(fields))
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "%s\n", b); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatTSV:
+		return writeTSV(w, rows, columns, opts.Header)
+	case FormatCSV:
+		return writeCSV(w, rows, columns)
+	case FormatTemplate:
+		return writeTemplate(w, rows, opts.Template)
+	default: // FormatTable, or FormatText without a Text renderer
+		return writeTable(w, rows, columns)
+	}
+}
+
+// ParseFields splits a comma-separated --fields value, dropping blanks.
+func ParseFields(s string) []string {
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// EscapeTSV escapes backslashes, tabs, carriage returns and newlines so a
+// value always occupies exactly one TSV cell.
+func EscapeTSV(s string) string {
+	return tsvEscaper.Replace(s)
+}
+
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// row is one record of row-oriented output: its JSON object in field order.
+type row struct {
+	keys   []string
+	values map[string]any
+}
+
+func (r row) project(fields []string) orderedMap {
+	m := orderedMap{}
+	for _, f := range fields {
+		m.keys = append(m.keys, f)
+		m.values = append(m.values, r.values[f])
+	}
+	return m
+}
+
+// orderedMap marshals to a JSON object preserving key order.
+type orderedMap struct {
+	keys   []string
+	values []any
+}
+
+func (m orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		vb, err := json.Marshal(m.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// toRows converts v into rows via its JSON encoding. A JSON array yields one
+// row per element and reports list as true; a single object yields one row;
+// null yields none.
+func toRows(v any) (rows []row, list bool, err error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode output: %w", err)
+	}
+	if string(b) == "null" {
+		return nil, true, nil
+	}
+	if b[0] != '[' {
+		r, err := decodeRow(b)
+		if err != nil {
+			return nil, false, err
+		}
+		return []row{r}, false, nil
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(b, &items); err != nil {
+		return nil, true, err
+	}
+	rows = make([]row, 0, len(items))
+	for _, item := range items {
+		r, err := decodeRow(item)
+		if err != nil {
+			return nil, true, err
+		}
+		rows = append(rows, r)
+	}
+	return rows, true, nil
+}
+
+func decodeRow(b []byte) (row, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var values any
+	if err := dec.Decode(&values); err != nil {
+		return row{}, err
+	}
+	obj, ok := values.(map[string]any)
+	if !ok {
+		// Scalars render as a single "value" column.
+		return row{keys: []string{"value"}, values: map[string]any{"value": values}}, nil
+	}
+	return row{keys: objectKeys(b), values: obj}, nil
+}
+
+// objectKeys returns the top-level keys of a JSON object in document order.
+func objectKeys(b []byte) []string {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if _, err := dec.Token(); err != nil { // opening brace
+		return nil
+	}
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return keys
+		}
+		key, _ := tok.(string)
+		keys = append(keys, key)
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return keys
+		}
+	}
+	return keys
+}
+
+// inferColumns returns the union of row keys in first-seen order.
+func inferColumns(rows []row) []string {
+	seen := map[string]bool{}
+	var cols []string
+	for _, r := range rows {
+		for _, k := range r.keys {
+			if !seen[k] {
+				seen[k] = true
+				cols = append(cols, k)
+			}
+		}
+	}
+	return cols
+}
+
+// project applies field selection to rows, keeping the array vs
+// single-object shape of the original value.
+func project(rows []row, list bool, fields []string) any {
+	if !list && len(rows) == 1 {
+		return rows[0].project(fields)
+	}
+	out := make([]orderedMap, len(rows))
+	for i, r := range rows {
+		out[i] = r.project(fields)
+	}
+	return out
+}
+
+// cell formats a JSON value for a single-cell output format.
+func cell(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		if val {
+			return "true"
+		}
+		return "false"
+	default:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(b)
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeYAML(w io.Writer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	node, err := jsonToYAML(json.NewDecoder(bytes.NewReader(b)))
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// jsonToYAML builds a YAML node tree from a JSON token stream so that object
+// keys keep the order defined by the view model's struct fields.
+func jsonToYAML(dec *json.Decoder) (*yaml.Node, error) {
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ := keyTok.(string)
+				val, err := jsonToYAML(dec)
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, val)
+			}
+			_, err := dec.Token() // closing brace
+			return node, err
+		}
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for dec.More() {
+			val, err := jsonToYAML(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, val)
+		}
+		_, err := dec.Token() // closing bracket
+		return node, err
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: t}, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(t.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: cell(t)}, nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+}
+
+func writeTSV(w io.Writer, rows []row, columns []string, header bool) error {
+	if header {
+		if _, err := fmt.Fprintln(w, strings.Join(columns, "\t")); err != nil {
+			return err
+		}
+	}
+	for _, r := range rows {
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			cells[i] = EscapeTSV(cell(r.values[c]))
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(cells, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, rows []row, columns []string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			cells[i] = cell(r.values[c])
+		}
+		if err := cw.Write(cells); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeTable(w io.Writer, rows []row, columns []string) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = strings.ToUpper(c)
+	}
+	if _, err := fmt.Fprintln(tw, strings.Join(headers, "\t")); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			cells[i] = EscapeTSV(cell(r.values[c]))
+		}
+		if _, err := fmt.Fprintln(tw, strings.Join(cells, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+func writeTemplate(w io.Writer, rows []row, src string) error {
+	tmpl, err := parseTemplate(src)
+	if err != nil {
+		return err
+	}
+	// Missing and null fields render as empty strings rather than "<no value>".
+	keys := inferColumns(rows)
+	for _, r := range rows {
+		values := make(map[string]any, len(keys))
+		for _, k := range keys {
+			values[k] = ""
+		}
+		for k, v := range r.values {
+			if v != nil {
+				values[k] = v
+			}
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, values); err != nil {
+			return fmt.Errorf("template execution failed: %w", err)
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseTemplate(src string) (*template.Template, error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --template: %w", err)
+	}
+	return tmpl, nil
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"tsv": func(v any) string { return EscapeTSV(cell(v)) },
+	"keys": func(m map[string]any) []string {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return keys
+	},
+}
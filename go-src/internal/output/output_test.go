@@ -0,0 +1,263 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type testMsg struct {
+	Ts      string   `json:"ts"`
+	User    string   `json:"user"`
+	Text    string   `json:"text"`
+	Replies int      `json:"reply_count,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+var testMsgs = []testMsg{
+	{Ts: "1.0", User: "U001", Text: "hello\tworld", Replies: 2},
+	{Ts: "2.0", User: "U002", Text: "line1\nline2", Tags: []string{"a", "b"}},
+}
+
+func renderString(t *testing.T, opts Options, v View) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Render(&buf, opts, v); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	return buf.String()
+}
+
+func TestRender_TextUsesTextRenderer(t *testing.T) {
+	v := View{
+		Data: testMsgs,
+		Text: func(w io.Writer) error {
+			_, err := io.WriteString(w, "custom\n")
+			return err
+		},
+	}
+
+	got := renderString(t, Options{}, v)
+
+	if got != "custom\n" {
+		t.Errorf("got %q, want custom text", got)
+	}
+}
+
+func TestRender_TextRendererError(t *testing.T) {
+	v := View{Data: testMsgs, Text: func(w io.Writer) error { return errors.New("boom") }}
+
+	err := Render(io.Discard, Options{}, v)
+
+	if err == nil || err.Error() != "boom" {
+		t.Errorf("err = %v, want boom", err)
+	}
+}
+
+func TestRender_JSON(t *testing.T) {
+	got := renderString(t, Options{Format: FormatJSON}, View{Data: testMsgs[:1]})
+
+	want := "[\n  {\n    \"ts\": \"1.0\",\n    \"user\": \"U001\",\n    \"text\": \"hello\\tworld\",\n    \"reply_count\": 2\n  }\n]\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRender_JSONWithFieldsKeepsOrder(t *testing.T) {
+	got := renderString(t, Options{Format: FormatJSON, Fields: []string{"text", "ts"}}, View{Data: testMsgs[:1]})
+
+	want := "[\n  {\n    \"text\": \"hello\\tworld\",\n    \"ts\": \"1.0\"\n  }\n]\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRender_JSONWithFieldsSingleObject(t *testing.T) {
+	got := renderString(t, Options{Format: FormatJSON, Fields: []string{"ts"}}, View{Data: testMsgs[0]})
+
+	want := "{\n  \"ts\": \"1.0\"\n}\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRender_NDJSON(t *testing.T) {
+	got := renderString(t, Options{Format: FormatNDJSON}, View{Data: testMsgs})
+
+	want := `{"ts":"1.0","user":"U001","text":"hello\tworld","reply_count":2}` + "\n" +
+		`{"ts":"2.0","user":"U002","text":"line1\nline2","tags":["a","b"]}` + "\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRender_NDJSONUsesRows(t *testing.T) {
+	envelope := struct {
+		Matches []testMsg `json:"matches"`
+		Total   int       `json:"total"`
+	}{Matches: testMsgs, Total: 2}
+
+	got := renderString(t, Options{Format: FormatNDJSON, Fields: []string{"ts"}}, View{Data: envelope, Rows: envelope.Matches})
+
+	want := "{\"ts\":\"1.0\"}\n{\"ts\":\"2.0\"}\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRender_TSVEscapesTabsAndNewlines(t *testing.T) {
+	got := renderString(t, Options{Format: FormatTSV}, View{Data: testMsgs, Columns: []string{"ts", "user", "text"}})
+
+	want := "1.0\tU001\thello\\tworld\n2.0\tU002\tline1\\nline2\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRender_TSVHeader(t *testing.T) {
+	got := renderString(t, Options{Format: FormatTSV, Header: true}, View{Data: testMsgs, Columns: []string{"ts", "user"}})
+
+	want := "ts\tuser\n1.0\tU001\n2.0\tU002\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRender_TSVFieldsOverrideColumns(t *testing.T) {
+	got := renderString(t, Options{Format: FormatTSV, Fields: []string{"user", "reply_count", "tags"}}, View{Data: testMsgs, Columns: []string{"ts"}})
+
+	want := "U001\t2\t\nU002\t\t[\"a\",\"b\"]\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRender_TSVInfersColumns(t *testing.T) {
+	got := renderString(t, Options{Format: FormatTSV, Header: true}, View{Data: testMsgs})
+
+	firstLine := strings.SplitN(got, "\n", 2)[0]
+	if firstLine != "ts\tuser\ttext\treply_count\ttags" {
+		t.Errorf("header = %q", firstLine)
+	}
+}
+
+func TestRender_CSV(t *testing.T) {
+	got := renderString(t, Options{Format: FormatCSV}, View{Data: testMsgs, Columns: []string{"ts", "text"}})
+
+	want := "ts,text\n1.0,hello\tworld\n2.0,\"line1\nline2\"\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRender_YAMLKeepsFieldOrder(t *testing.T) {
+	got := renderString(t, Options{Format: FormatYAML}, View{Data: testMsgs[1]})
+
+	want := "ts: \"2.0\"\nuser: U002\ntext: |-\n  line1\n  line2\ntags:\n  - a\n  - b\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRender_Table(t *testing.T) {
+	got := renderString(t, Options{Format: FormatTable}, View{Data: testMsgs, Columns: []string{"ts", "user"}})
+
+	want := "TS   USER\n1.0  U001\n2.0  U002\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRender_TextWithoutRendererFallsBackToTable(t *testing.T) {
+	got := renderString(t, Options{}, View{Data: testMsgs[:1], Columns: []string{"ts"}})
+
+	if got != "TS\n1.0\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestRender_Template(t *testing.T) {
+	got := renderString(t, Options{Format: FormatTemplate, Template: "{{.ts}} {{.user}} {{.reply_count}}"}, View{Data: testMsgs})
+
+	want := "1.0 U001 2\n2.0 U002 \n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRender_TemplateFuncs(t *testing.T) {
+	got := renderString(t, Options{Format: FormatTemplate, Template: `{{tsv .text}}|{{json .tags}}`}, View{Data: testMsgs[1:]})
+
+	want := "line1\\nline2|[\"a\",\"b\"]\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRender_SingleObjectRow(t *testing.T) {
+	got := renderString(t, Options{Format: FormatTSV}, View{Data: map[string]string{"channel": "C001", "ts": "1.0"}, Columns: []string{"channel", "ts"}})
+
+	if got != "C001\t1.0\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestRender_NilRows(t *testing.T) {
+	var msgs []testMsg
+
+	got := renderString(t, Options{Format: FormatTSV}, View{Data: msgs, Columns: []string{"ts"}})
+
+	if got != "" {
+		t.Errorf("got %q, want empty", got)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr string
+	}{
+		{name: "Default", opts: Options{}},
+		{name: "AllFormats", opts: Options{Format: FormatNDJSON}},
+		{name: "Unknown", opts: Options{Format: "xml"}, wantErr: `unknown output format "xml"`},
+		{name: "TemplateMissing", opts: Options{Format: FormatTemplate}, wantErr: "requires --template"},
+		{name: "TemplateInvalid", opts: Options{Format: FormatTemplate, Template: "{{.ts"}, wantErr: "invalid --template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	got := ParseFields(" ts, user,,text ")
+
+	if strings.Join(got, "|") != "ts|user|text" {
+		t.Errorf("got %v", got)
+	}
+	if ParseFields("") != nil {
+		t.Error("expected nil for empty input")
+	}
+}
+
+func TestEscapeTSV(t *testing.T) {
+	got := EscapeTSV("a\\b\tc\nd\re")
+
+	if want := `a\\b\tc\nd\re`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/itchyny/gojq"
+)
+
+// compileQuery parses and compiles a jq expression for --query.
+// Parse errors point at the offending position in the expression.
+func compileQuery(src string) (*gojq.Code, error) {
+	q, err := gojq.Parse(src)
+	if err != nil {
+		var perr *gojq.ParseError
+		if errors.As(err, &perr) {
+			return nil, fmt.Errorf("invalid --query: %v\n  %s\n  %s^", err, src, strings.Repeat(" ", caretOffset(src, perr)))
+		}
+		return nil, fmt.Errorf("invalid --query: %w", err)
+	}
+	code, err := gojq.Compile(q)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --query: %w", err)
+	}
+	return code, nil
+}
+
+// caretOffset returns the column (in runes) of the token that caused perr.
+func caretOffset(src string, perr *gojq.ParseError) int {
+	offset := perr.Offset - len(perr.Token)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(src) {
+		offset = len(src)
+	}
+	return len([]rune(src[:offset]))
+}
+
+// applyQuery runs a jq expression against the JSON form of v and returns
+// its results in order, like the stream jq prints. An expression with no
+// results returns an empty slice.
+func applyQuery(src string, v any) ([]any, error) {
+	code, err := compileQuery(src)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode output: %w", err)
+	}
+	var input any
+	if err := json.Unmarshal(b, &input); err != nil {
+		return nil, fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	results := []any{}
+	iter := code.Run(input)
+	for {
+		result, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := result.(error); ok {
+			var herr *gojq.HaltError
+			if errors.As(err, &herr) && herr.Value() == nil {
+				break
+			}
+			return nil, fmt.Errorf("--query failed: %w", err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// renderResults renders the results of --query. JSON and YAML output have
+// one document per result, as jq prints them; the other formats take the
+// rows of each result in turn, so an array result contributes its elements.
+func renderResults(w io.Writer, opts Options, results []any) error {
+	opts.Query = ""
+	if format := opts.format(); format == FormatJSON || format == FormatYAML {
+		for i, result := range results {
+			if format == FormatYAML && i > 0 {
+				if _, err := fmt.Fprintln(w, "---"); err != nil {
+					return err
+				}
+			}
+			if err := Render(w, opts, View{Data: result}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	items := []any{}
+	for _, result := range results {
+		if list, ok := result.([]any); ok {
+			items = append(items, list...)
+		} else {
+			items = append(items, result)
+		}
+	}
+	return Render(w, opts, View{Data: items})
+}
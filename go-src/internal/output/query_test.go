@@ -0,0 +1,201 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadFixture decodes a JSON view-model fixture from testdata.
+func loadFixture(t *testing.T, name string) any {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		t.Fatalf("parse fixture: %v", err)
+	}
+	return v
+}
+
+func TestRender_Query(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		opts    Options
+		want    string
+	}{
+		{
+			name:    "SelectToNDJSON",
+			fixture: "history.json",
+			opts:    Options{Format: FormatNDJSON, Query: `.[] | select(.reply_count > 0) | {ts, text}`},
+			want:    `{"text":"Deploy finished","ts":"1675382400.000100"}` + "\n",
+		},
+		{
+			name:    "MultipleScalarsToTSV",
+			fixture: "history.json",
+			opts:    Options{Format: FormatTSV, Query: `.[] | .subtype // "message"`},
+			want:    "message\nbot_message\nchannel_join\n",
+		},
+		{
+			name:    "ArrayResultToTSVWithFields",
+			fixture: "history.json",
+			opts:    Options{Format: FormatTSV, Fields: []string{"ts", "username"}, Query: `map(select(.bot_id))`},
+			want:    "1675382500.000200\talert-bot\n",
+		},
+		{
+			name:    "TextBecomesJSON",
+			fixture: "search.json",
+			opts:    Options{Query: `.matches | map(.channel)`},
+			want:    "[\n  \"general\",\n  \"ops\"\n]\n",
+		},
+		{
+			name:    "ScalarResult",
+			fixture: "search.json",
+			opts:    Options{Format: FormatJSON, Query: `.total`},
+			want:    "2\n",
+		},
+		{
+			name:    "ReactionCountsToCSV",
+			fixture: "history.json",
+			opts:    Options{Format: FormatCSV, Query: `[.[] | select(.reactions) | {ts, reactions: (.reactions | map(.count) | add)}]`},
+			// Objects built by a query have their keys sorted.
+			want: "reactions,ts\n3,1675382400.000100\n",
+		},
+		{
+			name:    "TemplateOverQuery",
+			fixture: "search.json",
+			opts:    Options{Format: FormatTemplate, Template: "#{{.channel}} {{.text}}", Query: `.matches[] | select(.text | test("blocked"))`},
+			want:    "#ops release blocked\n",
+		},
+		{
+			name:    "NoResults",
+			fixture: "history.json",
+			opts:    Options{Format: FormatJSON, Query: `.[] | select(.user == "U999")`},
+			want:    "",
+		},
+		{
+			name:    "MultipleResultsToJSON",
+			fixture: "search.json",
+			opts:    Options{Format: FormatJSON, Query: `.matches[].channel`},
+			want:    "\"general\"\n\"ops\"\n",
+		},
+		{
+			name:    "MultipleResultsToYAML",
+			fixture: "search.json",
+			opts:    Options{Format: FormatYAML, Query: `.matches[].channel`},
+			want:    "general\n---\nops\n",
+		},
+		{
+			name:    "ArrayResultsToTSV",
+			fixture: "search.json",
+			opts:    Options{Format: FormatTSV, Query: `.matches | map(.channel), ["extra"]`},
+			want:    "general\nops\nextra\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := loadFixture(t, tt.fixture)
+			v := View{
+				Data:    data,
+				Columns: []string{"ts", "user", "text"},
+				Text: func(w io.Writer) error {
+					_, err := io.WriteString(w, "text renderer must not run\n")
+					return err
+				},
+			}
+
+			var buf bytes.Buffer
+			if err := Render(&buf, tt.opts, v); err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+
+			if buf.String() != tt.want {
+				t.Errorf("got\n%q\nwant\n%q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyQuery_ResultCount(t *testing.T) {
+	data := loadFixture(t, "search.json")
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`.matches[] | select(.channel == "none")`, `[]`},
+		{`.total`, `[2]`},
+		{`.matches | map(.channel)`, `[["general","ops"]]`},
+		{`.matches[].channel`, `["general","ops"]`},
+	}
+	for _, tt := range tests {
+		results, err := applyQuery(tt.query, data)
+		if err != nil {
+			t.Fatalf("applyQuery(%q): %v", tt.query, err)
+		}
+		if b, _ := json.Marshal(results); string(b) != tt.want {
+			t.Errorf("applyQuery(%q) = %s, want %s", tt.query, b, tt.want)
+		}
+	}
+}
+
+func TestRender_QueryErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr []string
+	}{
+		{
+			name:    "ParseErrorShowsPosition",
+			query:   `.[] | select(.user == )`,
+			wantErr: []string{"invalid --query", `unexpected token ")"`, ".[] | select(.user == )\n                        ^"},
+		},
+		{
+			name:    "UnterminatedString",
+			query:   `.[] | select(.text == "abc)`,
+			wantErr: []string{"invalid --query", "unterminated string literal"},
+		},
+		{
+			name:    "UnknownFunction",
+			query:   `.[] | nosuchfn`,
+			wantErr: []string{"invalid --query", "function not defined: nosuchfn/0"},
+		},
+		{
+			name:    "RuntimeError",
+			query:   `.[] | .text + 1`,
+			wantErr: []string{"--query failed", "cannot add"},
+		},
+	}
+
+	data := loadFixture(t, "history.json")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Render(io.Discard, Options{Format: FormatJSON, Query: tt.query}, View{Data: data})
+
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err.Error(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestOptionsValidate_Query(t *testing.T) {
+	if err := (Options{Query: ".[0]"}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := (Options{Query: ".[0"}).Validate(); err == nil {
+		t.Error("expected error for invalid query")
+	}
+}
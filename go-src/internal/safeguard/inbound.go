@@ -0,0 +1,212 @@
+package safeguard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// InboundConfig controls redaction of data slamy returns to MCP clients.
+type InboundConfig struct {
+	// Off disables redaction of sensitive strings; never-exposed channels
+	// stay hidden.
+	Off bool `json:"off,omitempty"`
+	// Enabled turns on the high_entropy detector. Unlike outbound checks,
+	// inbound redaction runs the email and phone detectors by default.
+	Enabled []string `json:"enabled,omitempty"`
+	// Disabled lists built-in detectors to skip, including email and phone.
+	Disabled []string `json:"disabled,omitempty"`
+	// Patterns adds detectors for organisation-specific data.
+	Patterns []Pattern `json:"patterns,omitempty"`
+	// NeverExpose lists channel IDs whose content is never returned.
+	NeverExpose []string `json:"never_expose,omitempty"`
+	// KeepFields lists JSON field names, such as "email", whose values are
+	// returned unredacted.
+	KeepFields []string `json:"keep_fields,omitempty"`
+}
+
+// identifierFields hold Slack IDs, timestamps and similar values that are
+// never redacted: redacting them would only break follow-up tool calls.
+var identifierFields = []string{
+	"id", "ts", "thread_ts", "time", "user", "users", "bot_id", "channel_id",
+	"file_id", "subtype", "mimetype", "tz", "permalink", "timestamps",
+}
+
+// inboundDefaultDetectors are the opt-in detectors that inbound redaction
+// runs by default: tool output goes to a third-party LLM, so personal data
+// is removed unless a field is kept or the detector disabled.
+var inboundDefaultDetectors = []string{DetectorEmail, DetectorPhone}
+
+// Redactor removes sensitive data from tool output.
+type Redactor struct {
+	cfg     InboundConfig
+	scanner *Scanner
+}
+
+// NewRedactor compiles cfg.
+func NewRedactor(cfg InboundConfig) (*Redactor, error) {
+	scanner := &Scanner{}
+	enabled := append(slices.Clone(inboundDefaultDetectors), cfg.Enabled...)
+	if err := scanner.setDetectors(enabled, cfg.Disabled, cfg.Patterns); err != nil {
+		return nil, err
+	}
+	return &Redactor{cfg: cfg, scanner: scanner}, nil
+}
+
+// Hidden reports whether channelID is marked never to be exposed.
+func (r *Redactor) Hidden(channelID string) bool {
+	return slices.Contains(r.cfg.NeverExpose, channelID)
+}
+
+// HiddenError is returned for requests that read a never-exposed channel.
+type HiddenError struct {
+	Channel string
+}
+
+func (e *HiddenError) Error() string {
+	return fmt.Sprintf("channel %s is not exposed to MCP clients", e.Channel)
+}
+
+// CheckChannel returns a *HiddenError if channelID is never exposed.
+func (r *Redactor) CheckChannel(channelID string) error {
+	if r.Hidden(channelID) {
+		return &HiddenError{Channel: channelID}
+	}
+	return nil
+}
+
+// Redact returns v encoded as JSON and decoded again with sensitive strings
+// redacted and items belonging to never-exposed channels dropped from
+// lists. An item belongs to a channel when its "channel_id" or "id" field is
+// the channel's ID; if v itself is such an item, Redact returns a
+// *HiddenError.
+func (r *Redactor) Redact(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	tree, err := decodeValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if channel := r.hiddenChannel(tree); channel != "" {
+		return nil, &HiddenError{Channel: channel}
+	}
+	return r.redactValue("", tree), nil
+}
+
+func (r *Redactor) redactValue(field string, v any) any {
+	switch v := v.(type) {
+	case string:
+		if r.cfg.Off || slices.Contains(identifierFields, field) || slices.Contains(r.cfg.KeepFields, field) {
+			return v
+		}
+		if findings := r.scanner.Scan(v); len(findings) > 0 {
+			return redact(v, findings)
+		}
+		return v
+	case *object:
+		for _, k := range v.keys {
+			v.values[k] = r.redactValue(k, v.values[k])
+		}
+		return v
+	case []any:
+		out := make([]any, 0, len(v))
+		for _, item := range v {
+			if r.hiddenChannel(item) != "" {
+				continue
+			}
+			out = append(out, r.redactValue(field, item))
+		}
+		return out
+	}
+	return v
+}
+
+// hiddenChannel returns the channel ID when item is an object belonging to a
+// never-exposed channel, and "" otherwise.
+func (r *Redactor) hiddenChannel(item any) string {
+	obj, ok := item.(*object)
+	if !ok {
+		return ""
+	}
+	for _, key := range []string{"channel_id", "id"} {
+		if id, ok := obj.values[key].(string); ok && r.Hidden(id) {
+			return id
+		}
+	}
+	return ""
+}
+
+// object is a decoded JSON object that keeps its fields in their original
+// order when encoded again.
+type object struct {
+	keys   []string
+	values map[string]any
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(o.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeValue reads the next JSON value from dec, decoding objects as
+// *object and arrays as []any.
+func decodeValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := &object{values: map[string]any{}}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyTok.(string)
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.keys = append(obj.keys, key)
+			obj.values[key] = value
+		}
+		_, err = dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []any{}
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err = dec.Token()
+		return arr, err
+	}
+	return tok, nil
+}
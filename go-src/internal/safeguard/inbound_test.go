@@ -0,0 +1,161 @@
+package safeguard
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newRedactor(t *testing.T, cfg InboundConfig) *Redactor {
+	t.Helper()
+	r, err := NewRedactor(cfg)
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+	return r
+}
+
+func redactJSON(t *testing.T, r *Redactor, v any) string {
+	t.Helper()
+	out, err := r.Redact(v)
+	if err != nil {
+		t.Fatalf("Redact: %v", err)
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return string(b)
+}
+
+type testMessage struct {
+	Ts        string `json:"ts"`
+	User      string `json:"user"`
+	Text      string `json:"text"`
+	ChannelID string `json:"channel_id,omitempty"`
+	Count     int    `json:"count"`
+}
+
+func TestRedact_StringsKeepOrderAndIdentifiers(t *testing.T) {
+	got := redactJSON(t, newRedactor(t, InboundConfig{}), []testMessage{
+		{Ts: "1675382400.000000", User: "U001", Text: "call +1 415-555-0132 or mail bob@example.com", Count: 9007199254740993},
+	})
+
+	want := `[{"ts":"1675382400.000000","user":"U001","text":"call [REDACTED:phone] or mail [REDACTED:email]","count":9007199254740993}]`
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestRedact_Fixtures(t *testing.T) {
+	r := newRedactor(t, InboundConfig{})
+	for _, name := range []string{"slack_token", "github_token", "private_key"} {
+		t.Run(name, func(t *testing.T) {
+			got := redactJSON(t, r, map[string]string{"text": fixture(t, name+".txt")})
+
+			if !json.Valid([]byte(got)) || !strings.Contains(got, "[REDACTED:"+name+"]") {
+				t.Errorf("got %s", got)
+			}
+		})
+	}
+}
+
+func TestRedact_KeepFieldsAndOff(t *testing.T) {
+	profile := map[string]string{"email": "bob@example.com", "phone": "03-1234-5678"}
+
+	got := redactJSON(t, newRedactor(t, InboundConfig{KeepFields: []string{"email"}}), profile)
+	if got != `{"email":"bob@example.com","phone":"[REDACTED:phone]"}` {
+		t.Errorf("keep_fields: got %s", got)
+	}
+
+	got = redactJSON(t, newRedactor(t, InboundConfig{Off: true}), profile)
+	if got != `{"email":"bob@example.com","phone":"03-1234-5678"}` {
+		t.Errorf("off: got %s", got)
+	}
+}
+
+func TestRedact_CustomPatternAndDisabled(t *testing.T) {
+	r := newRedactor(t, InboundConfig{
+		Disabled: []string{DetectorEmail},
+		Patterns: []Pattern{{Name: "ticket", Regex: `SEC-\d+`}},
+	})
+
+	got := redactJSON(t, r, map[string]string{"text": "SEC-42 from bob@example.com"})
+
+	if got != `{"text":"[REDACTED:ticket] from bob@example.com"}` {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestRedact_DefaultsRedactEmailAndPhone(t *testing.T) {
+	r := newRedactor(t, InboundConfig{})
+
+	got := redactJSON(t, r, map[string]string{"text": "mail bob@example.com, call 03-1234-5678, session Qm9x7Lp2Vr8sK1tZ4wN6yB3cF5hJ0dGa"})
+
+	if got != `{"text":"mail [REDACTED:email], call [REDACTED:phone], session Qm9x7Lp2Vr8sK1tZ4wN6yB3cF5hJ0dGa"}` {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestRedact_HighEntropyOptIn(t *testing.T) {
+	r := newRedactor(t, InboundConfig{Enabled: []string{DetectorHighEntropy}})
+
+	got := redactJSON(t, r, map[string]string{"text": "session Qm9x7Lp2Vr8sK1tZ4wN6yB3cF5hJ0dGa"})
+
+	if got != `{"text":"session [REDACTED:high_entropy]"}` {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestRedact_LeavesURLsAndNumbers(t *testing.T) {
+	r := newRedactor(t, InboundConfig{Enabled: optInDetectors})
+	for _, text := range []string{
+		"see https://example.slack.com/archives/C024BE91L/p1700000000123456",
+		"doc: <https://docs.google.com/document/d/1aB2cD3eF4gH5iJ6kL7mN8oP9qR0sT1uV2wX3yZ4aB5c/edit|spec>",
+		"ssh to 192.168.100.1",
+		"fixed in 1.23.456",
+	} {
+		msg := map[string]string{"text": text}
+		want, _ := json.Marshal(msg)
+
+		if got := redactJSON(t, r, msg); got != string(want) {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+
+	got := redactJSON(t, r, map[string]any{"timestamps": []string{"1700000000.000100"}, "permalink": "https://example.slack.com/archives/C024BE91L/p1700000000000100"})
+	if !strings.Contains(got, "1700000000.000100") || strings.Contains(got, "REDACTED") {
+		t.Errorf("identifiers changed: %s", got)
+	}
+}
+
+func TestRedact_NeverExpose(t *testing.T) {
+	r := newRedactor(t, InboundConfig{NeverExpose: []string{"CSECRET"}, Off: true})
+
+	got := redactJSON(t, r, []testMessage{
+		{Ts: "1.0", Text: "public", ChannelID: "C001"},
+		{Ts: "2.0", Text: "secret", ChannelID: "CSECRET"},
+	})
+	if got != `[{"ts":"1.0","user":"","text":"public","channel_id":"C001","count":0}]` {
+		t.Errorf("got %s", got)
+	}
+
+	_, err := r.Redact(map[string]string{"id": "CSECRET", "name": "secret"})
+	var hidden *HiddenError
+	if !errors.As(err, &hidden) || hidden.Channel != "CSECRET" {
+		t.Errorf("err = %v", err)
+	}
+	if err := r.CheckChannel("CSECRET"); err == nil {
+		t.Error("CheckChannel: expected error")
+	}
+	if err := r.CheckChannel("C001"); err != nil {
+		t.Errorf("CheckChannel: %v", err)
+	}
+}
+
+func TestRedact_Unmarshalable(t *testing.T) {
+	if _, err := newRedactor(t, InboundConfig{}).Redact(make(chan int)); err == nil {
+		t.Error("expected error")
+	}
+}
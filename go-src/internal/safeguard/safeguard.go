@@ -0,0 +1,349 @@
+// Package safeguard scans outgoing message text for secrets and personal
+// data before slamy posts it, and blocks, redacts or warns about what it
+// finds according to a per-channel policy. It also redacts the same kinds of
+// data from what the MCP server returns to clients.
+package safeguard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Actions taken when a scan finds something.
+const (
+	// ActionBlock refuses to post the message (the default).
+	ActionBlock = "block"
+	// ActionRedact replaces each finding with a [REDACTED:<detector>] marker.
+	ActionRedact = "redact"
+	// ActionWarn posts the message unchanged and reports the findings.
+	ActionWarn = "warn"
+)
+
+// Built-in detector names.
+const (
+	DetectorSlackToken   = "slack_token"
+	DetectorAWSAccessKey = "aws_access_key"
+	DetectorAWSSecretKey = "aws_secret_key"
+	DetectorGitHubToken  = "github_token"
+	DetectorPrivateKey   = "private_key"
+	DetectorEmail        = "email"
+	DetectorPhone        = "phone"
+	DetectorHighEntropy  = "high_entropy"
+)
+
+// detector finds matches of one kind of sensitive data.
+type detector struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// optInDetectors find personal data and random-looking strings. They also
+// match plenty of ordinary text, so they only run when listed in
+// Config.Enabled, and never inside URLs.
+var optInDetectors = []string{DetectorEmail, DetectorPhone, DetectorHighEntropy}
+
+// builtinDetectors are checked in order; an earlier detector wins where
+// matches overlap.
+var builtinDetectors = []detector{
+	{DetectorPrivateKey, regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY-----(?:[\s\S]*?-----END (?:[A-Z0-9]+ )*PRIVATE KEY-----|[\s\S]*)`)},
+	{DetectorSlackToken, regexp.MustCompile(`\bxox[abposr]-[0-9A-Za-z-]{10,}|\bxapp-\d-[0-9A-Za-z-]{10,}|https://hooks\.slack\.com/services/[0-9A-Za-z/]+`)},
+	{DetectorAWSAccessKey, regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{DetectorAWSSecretKey, regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?[A-Za-z0-9/+]{40}\b`)},
+	{DetectorGitHubToken, regexp.MustCompile(`\bgh[pousr]_[0-9A-Za-z]{36,}\b|\bgithub_pat_[0-9A-Za-z_]{22,}\b`)},
+	{DetectorEmail, regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`)},
+	{DetectorPhone, regexp.MustCompile(`(?:\+\d{1,3}[ -]?)?(?:\(\d{1,4}\)[ -]?|\b\d{1,4}[ -])\d{2,4}[ -]\d{3,4}\b`)},
+}
+
+// High-entropy strings: runs of token characters at least
+// minEntropyLength long, mixing upper case, lower case and digits, with at
+// least minEntropy bits of Shannon entropy per character.
+var reEntropyCandidate = regexp.MustCompile(`[A-Za-z0-9+/_=-]{24,}`)
+
+// reURL matches URLs, such as permalinks and document links, whose paths
+// and IDs look like personal data or random tokens.
+var reURL = regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^\s<>|]+`)
+
+const (
+	minEntropyLength = 24
+	minEntropy       = 4.0
+)
+
+// Pattern is a user-defined detector.
+type Pattern struct {
+	Name  string `json:"name"`
+	Regex string `json:"regex"`
+}
+
+// Config is the safeguard policy, read from safeguards.json in the data
+// directory.
+type Config struct {
+	// Action is the default action; empty means ActionBlock.
+	Action string `json:"action,omitempty"`
+	// Channels overrides Action for individual channel IDs.
+	Channels map[string]string `json:"channels,omitempty"`
+	// Enabled turns on the opt-in detectors: email, phone and high_entropy.
+	Enabled []string `json:"enabled,omitempty"`
+	// Disabled lists built-in detectors to skip.
+	Disabled []string `json:"disabled,omitempty"`
+	// Patterns adds detectors for organisation-specific data.
+	Patterns []Pattern `json:"patterns,omitempty"`
+	// Inbound configures redaction of MCP tool output.
+	Inbound InboundConfig `json:"inbound,omitempty"`
+}
+
+// LoadConfig reads the config at path. A missing file gives the default
+// policy: the credential detectors, blocking.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read safeguard config: %w", err)
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse safeguard config: %w", err)
+	}
+	return cfg, nil
+}
+
+// Scanner applies a Config to outgoing text.
+type Scanner struct {
+	cfg       Config
+	detectors []detector
+	entropy   bool
+}
+
+// NewScanner validates cfg and compiles its patterns.
+func NewScanner(cfg Config) (*Scanner, error) {
+	if err := validAction(cfg.Action, "action"); err != nil {
+		return nil, err
+	}
+	for channel, action := range cfg.Channels {
+		if err := validAction(action, "action for channel "+channel); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Scanner{cfg: cfg}
+	if err := s.setDetectors(cfg.Enabled, cfg.Disabled, cfg.Patterns); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// setDetectors enables the built-in detectors not listed in disabled, except
+// for opt-in detectors not listed in enabled, plus patterns.
+func (s *Scanner) setDetectors(enabled, disabled []string, patterns []Pattern) error {
+	on := func(name string) bool {
+		if slices.Contains(disabled, name) {
+			return false
+		}
+		return !slices.Contains(optInDetectors, name) || slices.Contains(enabled, name)
+	}
+	s.entropy = on(DetectorHighEntropy)
+	for _, d := range builtinDetectors {
+		if on(d.name) {
+			s.detectors = append(s.detectors, d)
+		}
+	}
+	for _, p := range patterns {
+		if p.Name == "" {
+			return fmt.Errorf("safeguard pattern %q has no name", p.Regex)
+		}
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid safeguard pattern %s: %w", p.Name, err)
+		}
+		s.detectors = append(s.detectors, detector{p.Name, re})
+	}
+	return nil
+}
+
+func validAction(action, what string) error {
+	switch action {
+	case "", ActionBlock, ActionRedact, ActionWarn:
+		return nil
+	}
+	return fmt.Errorf("invalid safeguard %s %q (valid: %s, %s, %s)", what, action, ActionBlock, ActionRedact, ActionWarn)
+}
+
+// Finding is a match of one detector.
+type Finding struct {
+	Detector string `json:"detector"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	// Preview shows the start of the match with the rest masked.
+	Preview string `json:"preview"`
+}
+
+// Scan returns the findings in text, in order of position. Overlapping
+// matches are reported once, for the first detector that found them.
+func (s *Scanner) Scan(text string) []Finding {
+	var findings []Finding
+	urls := reURL.FindAllStringIndex(text, -1)
+	inURL := func(start, end int) bool {
+		for _, u := range urls {
+			if start < u[1] && u[0] < end {
+				return true
+			}
+		}
+		return false
+	}
+	overlaps := func(start, end int) bool {
+		for _, f := range findings {
+			if start < f.End && f.Start < end {
+				return true
+			}
+		}
+		return false
+	}
+	add := func(name string, start, end int) {
+		if !overlaps(start, end) {
+			findings = append(findings, Finding{Detector: name, Start: start, End: end, Preview: preview(text[start:end])})
+		}
+	}
+
+	for _, d := range s.detectors {
+		optIn := slices.Contains(optInDetectors, d.name)
+		for _, loc := range d.re.FindAllStringIndex(text, -1) {
+			if !optIn || !inURL(loc[0], loc[1]) {
+				add(d.name, loc[0], loc[1])
+			}
+		}
+	}
+	if s.entropy {
+		for _, loc := range reEntropyCandidate.FindAllStringIndex(text, -1) {
+			if !inURL(loc[0], loc[1]) && isHighEntropy(text[loc[0]:loc[1]]) {
+				add(DetectorHighEntropy, loc[0], loc[1])
+			}
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Start < findings[j].Start })
+	return findings
+}
+
+// Result is the outcome of Check.
+type Result struct {
+	// Text is the text to post: redacted with ActionRedact, unchanged
+	// otherwise.
+	Text     string
+	Action   string
+	Findings []Finding
+}
+
+// Warnings describes the findings for display next to the posted message.
+func (r Result) Warnings() []string {
+	var warnings []string
+	for _, f := range r.Findings {
+		verb := "possible"
+		if r.Action == ActionRedact {
+			verb = "redacted"
+		}
+		warnings = append(warnings, fmt.Sprintf("%s %s: %s", verb, f.Detector, f.Preview))
+	}
+	return warnings
+}
+
+// BlockedError is returned by Check when the policy blocks the message.
+type BlockedError struct {
+	Channel  string
+	Findings []Finding
+}
+
+func (e *BlockedError) Error() string {
+	var found []string
+	for _, f := range e.Findings {
+		found = append(found, fmt.Sprintf("%s (%s)", f.Detector, f.Preview))
+	}
+	return fmt.Sprintf("message to %s blocked by safeguards: found %s", e.Channel, strings.Join(found, ", "))
+}
+
+// Action returns the action configured for channelID.
+func (s *Scanner) Action(channelID string) string {
+	if action := s.cfg.Channels[channelID]; action != "" {
+		return action
+	}
+	if s.cfg.Action != "" {
+		return s.cfg.Action
+	}
+	return ActionBlock
+}
+
+// Check scans text bound for channelID and applies the channel's action.
+// A blocked message returns a *BlockedError.
+func (s *Scanner) Check(channelID, text string) (Result, error) {
+	result := Result{Text: text, Action: s.Action(channelID), Findings: s.Scan(text)}
+	if len(result.Findings) == 0 {
+		return result, nil
+	}
+
+	switch result.Action {
+	case ActionBlock:
+		return result, &BlockedError{Channel: channelID, Findings: result.Findings}
+	case ActionRedact:
+		result.Text = redact(text, result.Findings)
+	}
+	return result, nil
+}
+
+// redact replaces each finding in text with a [REDACTED:<detector>] marker.
+// findings must be ordered by position and must not overlap.
+func redact(text string, findings []Finding) string {
+	var b strings.Builder
+	last := 0
+	for _, f := range findings {
+		b.WriteString(text[last:f.Start])
+		b.WriteString("[REDACTED:" + f.Detector + "]")
+		last = f.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// preview returns the first few characters of a match followed by an
+// ellipsis, so reports do not repeat the secret.
+func preview(match string) string {
+	r := []rune(strings.TrimSpace(match))
+	n := min(4, len(r)/4)
+	return string(r[:n]) + "…"
+}
+
+// isHighEntropy reports whether s looks like a random token.
+func isHighEntropy(s string) bool {
+	if len(s) < minEntropyLength {
+		return false
+	}
+	var upper, lower, digit bool
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	if !upper || !lower || !digit {
+		return false
+	}
+
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / float64(len(s))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= minEntropy
+}
@@ -0,0 +1,203 @@
+package safeguard
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func fixture(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	return string(b)
+}
+
+func newScanner(t *testing.T, cfg Config) *Scanner {
+	t.Helper()
+	s, err := NewScanner(cfg)
+	if err != nil {
+		t.Fatalf("NewScanner: %v", err)
+	}
+	return s
+}
+
+func detectors(findings []Finding) string {
+	var names []string
+	for _, f := range findings {
+		names = append(names, f.Detector)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestScan_Fixtures(t *testing.T) {
+	for _, name := range []string{
+		DetectorSlackToken,
+		DetectorAWSAccessKey,
+		DetectorAWSSecretKey,
+		DetectorGitHubToken,
+		DetectorPrivateKey,
+		DetectorEmail,
+		DetectorPhone,
+		DetectorHighEntropy,
+	} {
+		t.Run(name, func(t *testing.T) {
+			findings := newScanner(t, Config{Enabled: optInDetectors}).Scan(fixture(t, name+".txt"))
+
+			if got := detectors(findings); got != name {
+				t.Errorf("detectors = %q, want %q", got, name)
+			}
+		})
+	}
+}
+
+func TestScan_CleanFixture(t *testing.T) {
+	findings := newScanner(t, Config{Enabled: optInDetectors}).Scan(fixture(t, "clean.txt"))
+
+	if len(findings) != 0 {
+		t.Errorf("findings = %+v", findings)
+	}
+}
+
+func TestScan_OptInDetectorsOffByDefault(t *testing.T) {
+	s := newScanner(t, Config{})
+
+	for _, name := range optInDetectors {
+		if findings := s.Scan(fixture(t, name+".txt")); len(findings) != 0 {
+			t.Errorf("%s: findings = %+v", name, findings)
+		}
+	}
+}
+
+func TestScan_NoFalsePositives(t *testing.T) {
+	s := newScanner(t, Config{Enabled: optInDetectors})
+	for _, text := range []string{
+		"https://example.slack.com/archives/C024BE91L/p1700000000123456?thread_ts=1700000000.000100&cid=C024BE91L",
+		"<https://docs.google.com/document/d/1aB2cD3eF4gH5iJ6kL7mN8oP9qR0sT1uV2wX3yZ4aB5c/edit|design doc>",
+		"profile: https://example.com/u/alice@example.com",
+		"the host is 192.168.100.1",
+		"upgrade to 1.23.456 or v10.2.3-4",
+		"released on 2024-01-15",
+	} {
+		if findings := s.Scan(text); len(findings) != 0 {
+			t.Errorf("Scan(%q) = %+v", text, findings)
+		}
+	}
+}
+
+func TestScan_PhoneFormats(t *testing.T) {
+	s := newScanner(t, Config{Enabled: []string{DetectorPhone}})
+
+	for _, text := range []string{"+81 90-1234-5678", "03-1234-5678", "(555) 123-4567", "555 123 4567"} {
+		if got := detectors(s.Scan(text)); got != DetectorPhone {
+			t.Errorf("Scan(%q) = %q", text, got)
+		}
+	}
+}
+
+func TestScan_PrivateKeyCoversWholeBlock(t *testing.T) {
+	text := fixture(t, "private_key.txt")
+
+	findings := newScanner(t, Config{}).Scan(text)
+
+	if len(findings) != 1 || !strings.HasSuffix(text[findings[0].Start:findings[0].End], "-----END OPENSSH PRIVATE KEY-----") {
+		t.Errorf("findings = %+v", findings)
+	}
+}
+
+func TestScan_DisabledDetector(t *testing.T) {
+	s := newScanner(t, Config{Enabled: []string{DetectorEmail}, Disabled: []string{DetectorEmail, DetectorSlackToken}})
+
+	if findings := s.Scan(fixture(t, "email.txt") + fixture(t, "slack_token.txt")); len(findings) != 0 {
+		t.Errorf("findings = %+v", findings)
+	}
+}
+
+func TestScan_CustomPattern(t *testing.T) {
+	s := newScanner(t, Config{Patterns: []Pattern{{Name: "customer_id", Regex: `CUST-\d{6}`}}})
+
+	findings := s.Scan("refund CUST-004211 today")
+
+	if detectors(findings) != "customer_id" || findings[0].Preview != "CU…" {
+		t.Errorf("findings = %+v", findings)
+	}
+}
+
+func TestNewScanner_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"Action", Config{Action: "drop"}},
+		{"ChannelAction", Config{Channels: map[string]string{"C001": "ignore"}}},
+		{"Regex", Config{Patterns: []Pattern{{Name: "bad", Regex: "("}}}},
+		{"Name", Config{Patterns: []Pattern{{Regex: "x"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewScanner(tt.cfg); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestCheck_Actions(t *testing.T) {
+	s := newScanner(t, Config{Channels: map[string]string{"CREDACT": ActionRedact, "CWARN": ActionWarn}})
+	text := fixture(t, "github_token.txt")
+
+	_, err := s.Check("CBLOCK", text)
+	var blocked *BlockedError
+	if !errors.As(err, &blocked) || !strings.Contains(err.Error(), "github_token (ghp_…)") {
+		t.Errorf("block: err = %v", err)
+	}
+	if strings.Contains(err.Error(), "0123456789") {
+		t.Errorf("error repeats the secret: %v", err)
+	}
+
+	result, err := s.Check("CREDACT", text)
+	if err != nil || result.Text != "export GITHUB_TOKEN=[REDACTED:github_token]\n" {
+		t.Errorf("redact: text = %q, err = %v", result.Text, err)
+	}
+	if w := result.Warnings(); len(w) != 1 || w[0] != "redacted github_token: ghp_…" {
+		t.Errorf("redact: warnings = %v", w)
+	}
+
+	result, err = s.Check("CWARN", text)
+	if err != nil || result.Text != text {
+		t.Errorf("warn: text = %q, err = %v", result.Text, err)
+	}
+	if w := result.Warnings(); len(w) != 1 || w[0] != "possible github_token: ghp_…" {
+		t.Errorf("warn: warnings = %v", w)
+	}
+}
+
+func TestCheck_CleanText(t *testing.T) {
+	result, err := newScanner(t, Config{}).Check("C001", "hello")
+
+	if err != nil || result.Text != "hello" || len(result.Findings) != 0 {
+		t.Errorf("result = %+v, err = %v", result, err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "safeguards.json")
+
+	cfg, err := LoadConfig(path)
+	if err != nil || cfg.Action != "" {
+		t.Errorf("missing file: cfg = %+v, err = %v", cfg, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"action":"warn","channels":{"C001":"redact"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadConfig(path)
+	if err != nil || cfg.Action != ActionWarn || cfg.Channels["C001"] != ActionRedact {
+		t.Errorf("cfg = %+v, err = %v", cfg, err)
+	}
+}
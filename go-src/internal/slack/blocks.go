@@ -0,0 +1,342 @@
+package slack
+
+import (
+	"strings"
+
+	slackapi "github.com/slack-go/slack"
+	"github.com/yuin/goldmark/ast"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Block Kit limits enforced by MarkdownToBlocks.
+const (
+	// MaxBlocksPerMessage is the maximum number of blocks in a single message.
+	MaxBlocksPerMessage = 50
+	// MaxSectionTextLength is the maximum rune count of a section block's text.
+	MaxSectionTextLength = 3000
+	// MaxHeaderTextLength is the maximum rune count of a header block's text.
+	MaxHeaderTextLength = 150
+	// maxSectionFields is the maximum number of fields in a section block.
+	maxSectionFields = 10
+)
+
+// BlockMessage is one message worth of Block Kit blocks, with the plain
+// mrkdwn fallback Slack shows in notifications and clients without blocks.
+type BlockMessage struct {
+	Blocks []slackapi.Block
+	Text   string
+}
+
+// MarkdownToBlocks converts agent-written Markdown into Block Kit messages.
+//
+// Headings become header blocks, thematic breaks become dividers, fenced
+// code becomes rich_text_preformatted, lists become rich_text_list and
+// small two-column tables become section fields (wider tables are
+// preformatted). Everything else is emitted as mrkdwn sections using the
+// same conversion as FixSlackMrkdwn. Output exceeding MaxBlocksPerMessage
+// is spread over several messages.
+func MarkdownToBlocks(src string) []BlockMessage {
+	source := []byte(src)
+	doc := markdown.Parser().Parse(text.NewReader(source))
+	b := &blockBuilder{c: &mrkdwnConverter{source: source}}
+
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		b.node(n)
+	}
+	b.flushSection()
+
+	return b.messages()
+}
+
+// blockItem is a single block together with its mrkdwn fallback text.
+type blockItem struct {
+	block    slackapi.Block
+	fallback string
+}
+
+type blockBuilder struct {
+	c     *mrkdwnConverter
+	items []blockItem
+	// section accumulates consecutive mrkdwn blocks into one section.
+	section []string
+}
+
+func (b *blockBuilder) add(block slackapi.Block, fallback string) {
+	b.items = append(b.items, blockItem{block: block, fallback: fallback})
+}
+
+func (b *blockBuilder) node(n ast.Node) {
+	switch node := n.(type) {
+	case *ast.Heading:
+		b.flushSection()
+		b.c.plain = true
+		title := strings.TrimSpace(b.c.inlines(node))
+		b.c.plain = false
+		if title == "" {
+			return
+		}
+		header := truncateRunes(title, MaxHeaderTextLength)
+		b.add(slackapi.NewHeaderBlock(slackapi.NewTextBlockObject(slackapi.PlainTextType, header, true, false)), "*"+title+"*")
+	case *ast.ThematicBreak:
+		b.flushSection()
+		b.add(slackapi.NewDividerBlock(), "────────")
+	case *ast.FencedCodeBlock:
+		b.flushSection()
+		code := strings.TrimSuffix(b.c.lines(node), "\n")
+		if code == "" {
+			return
+		}
+		for _, chunk := range SplitMessage(code, MaxSectionTextLength) {
+			b.add(preformattedBlock(chunk), "```\n"+chunk+"\n```")
+		}
+	case *ast.List:
+		if isEmptyList(node) {
+			b.appendSection(b.c.block(node, 0))
+			return
+		}
+		b.flushSection()
+		var elements []slackapi.RichTextElement
+		b.list(node, 0, &elements)
+		b.add(slackapi.NewRichTextBlock("", elements...), b.c.block(node, 0))
+	case *extast.Table:
+		b.flushSection()
+		b.table(node)
+	default:
+		b.appendSection(fixBoldSpacing(b.c.block(n, 0)))
+	}
+}
+
+// appendSection queues mrkdwn text for the current section block, starting
+// a new section when the text would exceed MaxSectionTextLength.
+func (b *blockBuilder) appendSection(s string) {
+	if s == "" {
+		return
+	}
+	if len(b.section) > 0 && runeLen(strings.Join(append(b.section, s), "\n\n")) > MaxSectionTextLength {
+		b.flushSection()
+	}
+	b.section = append(b.section, s)
+}
+
+func (b *blockBuilder) flushSection() {
+	if len(b.section) == 0 {
+		return
+	}
+	joined := strings.Join(b.section, "\n\n")
+	b.section = nil
+	for _, chunk := range SplitMessage(joined, MaxSectionTextLength) {
+		b.add(slackapi.NewSectionBlock(slackapi.NewTextBlockObject(slackapi.MarkdownType, chunk, false, false), nil, nil), chunk)
+	}
+}
+
+// list flattens a (possibly nested) Markdown list into rich_text_list
+// elements, one per run of items at the same nesting level.
+func (b *blockBuilder) list(list *ast.List, indent int, out *[]slackapi.RichTextElement) {
+	style := slackapi.RTEListBullet
+	offset := 0
+	if list.IsOrdered() {
+		style = slackapi.RTEListOrdered
+		offset = list.Start - 1
+	}
+
+	var current *slackapi.RichTextList
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		var section []slackapi.RichTextSectionElement
+		var nested []*ast.List
+		for child := item.FirstChild(); child != nil; child = child.NextSibling() {
+			switch node := child.(type) {
+			case *ast.List:
+				nested = append(nested, node)
+			case *ast.Paragraph, *ast.TextBlock:
+				if len(section) > 0 {
+					section = append(section, slackapi.NewRichTextSectionTextElement("\n", nil))
+				}
+				section = append(section, b.richText(node, slackapi.RichTextSectionTextStyle{})...)
+			default:
+				if len(section) > 0 {
+					section = append(section, slackapi.NewRichTextSectionTextElement("\n", nil))
+				}
+				section = append(section, slackapi.NewRichTextSectionTextElement(b.c.block(node, 0), nil))
+			}
+		}
+
+		if current == nil {
+			current = slackapi.NewRichTextList(style, indent)
+			current.Offset = offset
+			*out = append(*out, current)
+		}
+		current.Elements = append(current.Elements, slackapi.NewRichTextSection(section...))
+		offset++
+
+		for _, n := range nested {
+			b.list(n, indent+1, out)
+			current = nil
+		}
+	}
+}
+
+func (b *blockBuilder) table(table *extast.Table) {
+	b.c.plain = true
+	var rows [][]string
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		var cells []string
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			cells = append(cells, strings.TrimSpace(b.c.inlines(cell)))
+		}
+		rows = append(rows, cells)
+	}
+	b.c.plain = false
+	fallback := b.c.table(table)
+
+	// Two-column tables that fit become label/value section fields.
+	if len(rows[0]) == 2 && len(rows)*2 <= maxSectionFields {
+		var fields []*slackapi.TextBlockObject
+		for i, row := range rows {
+			for _, cell := range row {
+				if i == 0 && cell != "" {
+					cell = "*" + cell + "*"
+				}
+				fields = append(fields, slackapi.NewTextBlockObject(slackapi.MarkdownType, truncateRunes(cell, 2000), false, false))
+			}
+		}
+		b.add(slackapi.NewSectionBlock(nil, fields, nil), fallback)
+		return
+	}
+
+	body := strings.TrimSuffix(strings.TrimPrefix(fallback, "```\n"), "\n```")
+	for _, chunk := range SplitMessage(body, MaxSectionTextLength) {
+		b.add(preformattedBlock(chunk), "```\n"+chunk+"\n```")
+	}
+}
+
+// messages groups the built blocks into messages of at most
+// MaxBlocksPerMessage blocks, each with its own fallback text.
+func (b *blockBuilder) messages() []BlockMessage {
+	var msgs []BlockMessage
+	for start := 0; start < len(b.items); start += MaxBlocksPerMessage {
+		end := start + MaxBlocksPerMessage
+		if end > len(b.items) {
+			end = len(b.items)
+		}
+		var msg BlockMessage
+		var fallbacks []string
+		for _, item := range b.items[start:end] {
+			msg.Blocks = append(msg.Blocks, item.block)
+			fallbacks = append(fallbacks, item.fallback)
+		}
+		msg.Text = truncateRunes(strings.Join(fallbacks, "\n\n"), MaxMessageLength)
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// richText converts the inline children of n into rich text elements.
+func (b *blockBuilder) richText(n ast.Node, style slackapi.RichTextSectionTextStyle) []slackapi.RichTextSectionElement {
+	var out []slackapi.RichTextSectionElement
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		out = append(out, b.richTextInline(child, style)...)
+	}
+	return out
+}
+
+func (b *blockBuilder) richTextInline(n ast.Node, style slackapi.RichTextSectionTextStyle) []slackapi.RichTextSectionElement {
+	switch node := n.(type) {
+	case *ast.Emphasis:
+		if b.c.intraword(node) {
+			marker := slackapi.NewRichTextSectionTextElement("*", styleOrNil(style))
+			return append(append([]slackapi.RichTextSectionElement{marker}, b.richText(node, style)...), marker)
+		}
+		if node.Level >= 2 || b.c.emphasisMarker(node) == '*' {
+			style.Bold = true
+		} else {
+			style.Italic = true
+		}
+		return b.richText(node, style)
+	case *extast.Strikethrough:
+		style.Strike = true
+		return b.richText(node, style)
+	case *ast.CodeSpan:
+		style.Code = true
+		b.c.plain = true
+		code := b.c.inline(node)
+		b.c.plain = false
+		return []slackapi.RichTextSectionElement{slackapi.NewRichTextSectionTextElement(code, styleOrNil(style))}
+	case *ast.Link:
+		return []slackapi.RichTextSectionElement{b.linkElement(string(node.Destination), node, style)}
+	case *ast.Image:
+		return []slackapi.RichTextSectionElement{b.linkElement(string(node.Destination), node, style)}
+	case *ast.AutoLink:
+		return []slackapi.RichTextSectionElement{slackapi.NewRichTextSectionLinkElement(string(node.URL(b.c.source)), "", styleOrNil(style))}
+	case *ast.RawHTML:
+		return []slackapi.RichTextSectionElement{slackTokenElement(b.c.inline(node), style)}
+	default:
+		s := b.c.inline(n)
+		if s == "" {
+			return nil
+		}
+		return []slackapi.RichTextSectionElement{slackapi.NewRichTextSectionTextElement(s, styleOrNil(style))}
+	}
+}
+
+func (b *blockBuilder) linkElement(url string, n ast.Node, style slackapi.RichTextSectionTextStyle) slackapi.RichTextSectionElement {
+	b.c.plain = true
+	label := b.c.inlines(n)
+	b.c.plain = false
+	if label == url {
+		label = ""
+	}
+	return slackapi.NewRichTextSectionLinkElement(url, label, styleOrNil(style))
+}
+
+// slackTokenElement converts Slack's <...> syntax into the matching rich
+// text element, falling back to plain text for anything unrecognized.
+func slackTokenElement(token string, style slackapi.RichTextSectionTextStyle) slackapi.RichTextSectionElement {
+	inner := strings.TrimSuffix(strings.TrimPrefix(token, "<"), ">")
+	id, label, _ := strings.Cut(inner, "|")
+	switch {
+	case strings.HasPrefix(id, "@"):
+		return slackapi.NewRichTextSectionUserElement(id[1:], styleOrNil(style))
+	case strings.HasPrefix(id, "#"):
+		// slack-go's NewRichTextSectionChannelElement sets the wrong type.
+		return &slackapi.RichTextSectionChannelElement{Type: slackapi.RTSEChannel, ChannelID: id[1:], Style: styleOrNil(style)}
+	case strings.HasPrefix(id, "!subteam^"):
+		return slackapi.NewRichTextSectionUserGroupElement(strings.TrimPrefix(id, "!subteam^"))
+	case id == "!here" || id == "!channel" || id == "!everyone":
+		return slackapi.NewRichTextSectionBroadcastElement(id[1:])
+	case strings.HasPrefix(id, "!"):
+		return slackapi.NewRichTextSectionTextElement(token, styleOrNil(style))
+	default:
+		return slackapi.NewRichTextSectionLinkElement(id, label, styleOrNil(style))
+	}
+}
+
+func preformattedBlock(code string) *slackapi.RichTextBlock {
+	pre := &slackapi.RichTextPreformatted{
+		RichTextSection: slackapi.RichTextSection{
+			Type:     slackapi.RTEPreformatted,
+			Elements: []slackapi.RichTextSectionElement{slackapi.NewRichTextSectionTextElement(code, nil)},
+		},
+	}
+	return slackapi.NewRichTextBlock("", pre)
+}
+
+func styleOrNil(style slackapi.RichTextSectionTextStyle) *slackapi.RichTextSectionTextStyle {
+	if style == (slackapi.RichTextSectionTextStyle{}) {
+		return nil
+	}
+	return &style
+}
+
+func runeLen(s string) int {
+	return len([]rune(s))
+}
+
+// truncateRunes cuts s to at most maxLen runes, ending with "…" when cut.
+func truncateRunes(s string, maxLen int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	return string(runes[:maxLen-1]) + "…"
+}
@@ -0,0 +1,206 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	slackapi "github.com/slack-go/slack"
+)
+
+// blockTypes returns the type of every block in msg.
+func blockTypes(msg BlockMessage) []string {
+	var types []string
+	for _, b := range msg.Blocks {
+		types = append(types, string(b.BlockType()))
+	}
+	return types
+}
+
+func blocksJSON(t *testing.T, blocks []slackapi.Block) string {
+	t.Helper()
+	b, err := json.Marshal(blocks)
+	if err != nil {
+		t.Fatalf("marshal blocks: %v", err)
+	}
+	return string(b)
+}
+
+func TestMarkdownToBlocks_BlockTypes(t *testing.T) {
+	src := "# Release notes\n\nShipped **today**.\n\n---\n\n```go\nfmt.Println(1)\n```\n\n- one\n- two"
+
+	msgs := MarkdownToBlocks(src)
+
+	if len(msgs) != 1 {
+		t.Fatalf("got %d messages, want 1", len(msgs))
+	}
+	got := strings.Join(blockTypes(msgs[0]), ",")
+	if want := "header,section,divider,rich_text,rich_text"; got != want {
+		t.Errorf("block types = %s, want %s", got, want)
+	}
+
+	js := blocksJSON(t, msgs[0].Blocks)
+	for _, want := range []string{
+		`"text":"Release notes"`,
+		`"text":"Shipped *today*."`,
+		`"type":"rich_text_preformatted","elements":[{"type":"text","text":"fmt.Println(1)"}]`,
+		`"type":"rich_text_list","elements":[{"type":"rich_text_section","elements":[{"type":"text","text":"one"}]}`,
+	} {
+		if !strings.Contains(js, want) {
+			t.Errorf("blocks missing %s\n%s", want, js)
+		}
+	}
+}
+
+func TestMarkdownToBlocks_FallbackText(t *testing.T) {
+	msgs := MarkdownToBlocks("## 概要\n\n**結果**（成功）")
+
+	if want := "*概要*\n\n*結果* （成功）"; msgs[0].Text != want {
+		t.Errorf("fallback = %q, want %q", msgs[0].Text, want)
+	}
+}
+
+func TestMarkdownToBlocks_HeaderTruncated(t *testing.T) {
+	msgs := MarkdownToBlocks("# " + strings.Repeat("a", 200))
+
+	header := msgs[0].Blocks[0].(*slackapi.HeaderBlock)
+	if n := runeLen(header.Text.Text); n != MaxHeaderTextLength {
+		t.Errorf("header length = %d, want %d", n, MaxHeaderTextLength)
+	}
+}
+
+func TestMarkdownToBlocks_NestedOrderedList(t *testing.T) {
+	msgs := MarkdownToBlocks("3. first **bold**\n   - child <@U123>\n4. second")
+
+	rt := msgs[0].Blocks[0].(*slackapi.RichTextBlock)
+	if len(rt.Elements) != 3 {
+		t.Fatalf("got %d list elements, want 3", len(rt.Elements))
+	}
+	first := rt.Elements[0].(*slackapi.RichTextList)
+	nested := rt.Elements[1].(*slackapi.RichTextList)
+	rest := rt.Elements[2].(*slackapi.RichTextList)
+	if first.Style != slackapi.RTEListOrdered || first.Offset != 2 || first.Indent != 0 {
+		t.Errorf("first list = %+v", first)
+	}
+	if nested.Style != slackapi.RTEListBullet || nested.Indent != 1 {
+		t.Errorf("nested list = %+v", nested)
+	}
+	if rest.Offset != 3 || rest.Indent != 0 {
+		t.Errorf("continued list = %+v", rest)
+	}
+
+	js := blocksJSON(t, msgs[0].Blocks)
+	for _, want := range []string{
+		`{"type":"text","text":"bold","style":{"bold":true}}`,
+		`{"type":"user","user_id":"U123"}`,
+	} {
+		if !strings.Contains(js, want) {
+			t.Errorf("blocks missing %s\n%s", want, js)
+		}
+	}
+}
+
+func TestMarkdownToBlocks_InlineRichText(t *testing.T) {
+	msgs := MarkdownToBlocks("- [docs](https://example.com) `code` ~~old~~ _it_ *em* <#C1|general> <!here>")
+
+	js := blocksJSON(t, msgs[0].Blocks)
+	for _, want := range []string{
+		`{"type":"link","url":"https://example.com","text":"docs"}`,
+		`{"type":"text","text":"code","style":{"code":true}}`,
+		`{"type":"text","text":"old","style":{"strike":true}}`,
+		`{"type":"text","text":"it","style":{"italic":true}}`,
+		`{"type":"text","text":"em","style":{"bold":true}}`,
+		`{"type":"channel","channel_id":"C1"}`,
+		`{"type":"broadcast","range":"here"}`,
+	} {
+		if !strings.Contains(js, want) {
+			t.Errorf("blocks missing %s\n%s", want, js)
+		}
+	}
+}
+
+func TestMarkdownToBlocks_LiteralAsterisks(t *testing.T) {
+	msgs := MarkdownToBlocks("2*3=6 and 4*5=20\n\n*")
+
+	if got := blockTypes(msgs[0]); len(got) != 1 || got[0] != "section" {
+		t.Fatalf("blocks = %v, want one section", got)
+	}
+	js := blocksJSON(t, msgs[0].Blocks)
+	if !strings.Contains(js, `"text":"2*3=6 and 4*5=20\n\n*"`) {
+		t.Errorf("literal text changed:\n%s", js)
+	}
+}
+
+func TestMarkdownToBlocks_IntrawordAsterisksInList(t *testing.T) {
+	msgs := MarkdownToBlocks("- 2*3=6 and 4*5=20")
+
+	js := blocksJSON(t, msgs[0].Blocks)
+	if strings.Contains(js, `"bold":true`) {
+		t.Errorf("intraword asterisks formatted:\n%s", js)
+	}
+}
+
+func TestMarkdownToBlocks_TwoColumnTableAsFields(t *testing.T) {
+	msgs := MarkdownToBlocks("| Key | Value |\n|---|---|\n| env | prod |")
+
+	section := msgs[0].Blocks[0].(*slackapi.SectionBlock)
+	var fields []string
+	for _, f := range section.Fields {
+		fields = append(fields, f.Text)
+	}
+	if got := strings.Join(fields, "|"); got != "*Key*|*Value*|env|prod" {
+		t.Errorf("fields = %s", got)
+	}
+	if !strings.HasPrefix(msgs[0].Text, "```\nKey") {
+		t.Errorf("fallback = %q", msgs[0].Text)
+	}
+}
+
+func TestMarkdownToBlocks_WideTablePreformatted(t *testing.T) {
+	msgs := MarkdownToBlocks("| a | b | c |\n|---|---|---|\n| 1 | 2 | 3 |")
+
+	js := blocksJSON(t, msgs[0].Blocks)
+	if !strings.Contains(js, `"type":"rich_text_preformatted","elements":[{"type":"text","text":"a | b | c\n--+---+--\n1 | 2 | 3"}]`) {
+		t.Errorf("unexpected blocks %s", js)
+	}
+}
+
+func TestMarkdownToBlocks_LongTextSplitIntoSections(t *testing.T) {
+	para := strings.Repeat("x", 2000)
+	msgs := MarkdownToBlocks(para + "\n\n" + para + "\n\n" + strings.Repeat("y", 3500))
+
+	if got := strings.Join(blockTypes(msgs[0]), ","); got != "section,section,section,section" {
+		t.Fatalf("block types = %s", got)
+	}
+	for i, b := range msgs[0].Blocks {
+		if n := runeLen(b.(*slackapi.SectionBlock).Text.Text); n > MaxSectionTextLength {
+			t.Errorf("section %d has %d runes", i, n)
+		}
+	}
+}
+
+func TestMarkdownToBlocks_BlockLimitSplitsMessages(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 60; i++ {
+		fmt.Fprintf(&sb, "# Heading %d\n", i)
+	}
+
+	msgs := MarkdownToBlocks(sb.String())
+
+	if len(msgs) != 2 {
+		t.Fatalf("got %d messages, want 2", len(msgs))
+	}
+	if len(msgs[0].Blocks) != MaxBlocksPerMessage || len(msgs[1].Blocks) != 10 {
+		t.Errorf("block counts = %d, %d", len(msgs[0].Blocks), len(msgs[1].Blocks))
+	}
+	if !strings.HasPrefix(msgs[1].Text, "*Heading 50*") {
+		t.Errorf("second fallback = %q", msgs[1].Text)
+	}
+}
+
+func TestMarkdownToBlocks_Empty(t *testing.T) {
+	if msgs := MarkdownToBlocks(""); len(msgs) != 0 {
+		t.Errorf("got %d messages, want 0", len(msgs))
+	}
+}
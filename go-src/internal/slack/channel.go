@@ -0,0 +1,113 @@
+package slack
+
+import (
+	"time"
+
+	slackapi "github.com/slack-go/slack"
+)
+
+// Channel is the shared view model for a Slack conversation.
+type Channel struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Topic      string `json:"topic"`
+	Purpose    string `json:"purpose"`
+	NumMembers int    `json:"num_members"`
+	IsPrivate  bool   `json:"is_private"`
+	IsArchived bool   `json:"is_archived"`
+	IsIM       bool   `json:"is_im,omitempty"`
+	IsMpIM     bool   `json:"is_mpim,omitempty"`
+	// User is the other member of a direct message.
+	User        string `json:"user,omitempty"`
+	UnreadCount int    `json:"unread_count,omitempty"`
+}
+
+// NewChannel converts a Slack API conversation into the shared view model.
+func NewChannel(ch slackapi.Channel) Channel {
+	return Channel{
+		ID:         ch.ID,
+		Name:       ch.Name,
+		Topic:      ch.Topic.Value,
+		Purpose:    ch.Purpose.Value,
+		NumMembers: ch.NumMembers,
+		IsPrivate:  ch.IsPrivate,
+		IsArchived: ch.IsArchived,
+		IsIM:       ch.IsIM,
+		IsMpIM:     ch.IsMpIM,
+		User:       ch.User,
+	}
+}
+
+// Label returns "@user" for a direct message and "#name" otherwise.
+func (c Channel) Label() string {
+	if c.IsIM {
+		return "@" + c.User
+	}
+	return "#" + c.Name
+}
+
+// NewChannels converts a slice of Slack API conversations into view models.
+func NewChannels(channels []slackapi.Channel) []Channel {
+	out := make([]Channel, len(channels))
+	for i, ch := range channels {
+		out[i] = NewChannel(ch)
+	}
+	return out
+}
+
+// UnreadChannel is the view model for a conversation's unread state.
+type UnreadChannel struct {
+	Channel
+	MentionCount int `json:"mention_count"`
+	// More is set when the conversation has more unread messages than were
+	// counted.
+	More bool `json:"more,omitempty"`
+	// Error reports why the unread state could not be determined.
+	Error string `json:"error,omitempty"`
+	// Messages holds unread messages, newest first, when requested.
+	Messages []Message `json:"messages,omitempty"`
+}
+
+// DirectMessage is the view model for a direct message or group DM.
+type DirectMessage struct {
+	ID     string `json:"id"`
+	IsMpIM bool   `json:"is_mpim,omitempty"`
+	// Users are the other members of the conversation.
+	Users []string `json:"users"`
+	// Names are the users' display names, in the same order as Users.
+	Names []string `json:"names,omitempty"`
+}
+
+// ChannelInfo is the detailed view model for a single conversation.
+type ChannelInfo struct {
+	Channel
+	Creator   string `json:"creator"`
+	Created   string `json:"created"`
+	IsMember  bool   `json:"is_member"`
+	IsGeneral bool   `json:"is_general"`
+}
+
+// NewChannelInfo converts a Slack API conversation into the detailed view
+// model.
+func NewChannelInfo(ch slackapi.Channel) ChannelInfo {
+	created := ""
+	if ch.Created != 0 {
+		created = time.Unix(int64(ch.Created), 0).Format("2006-01-02 15:04:05")
+	}
+	return ChannelInfo{
+		Channel:   NewChannel(ch),
+		Creator:   ch.Creator,
+		Created:   created,
+		IsMember:  ch.IsMember,
+		IsGeneral: ch.IsGeneral,
+	}
+}
+
+// ChannelMember is a member of a conversation. The names are only set when
+// they were resolved.
+type ChannelMember struct {
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	RealName    string `json:"real_name,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+}
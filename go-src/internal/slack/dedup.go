@@ -0,0 +1,76 @@
+package slack
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	slackapi "github.com/slack-go/slack"
+)
+
+// DefaultDedupWindow is how far back FindDuplicate looks by default.
+const DefaultDedupWindow = 10 * time.Minute
+
+// dedupHistoryLimit caps the number of messages FindDuplicate inspects.
+const dedupHistoryLimit = 200
+
+// FindDuplicate looks for a message identical to the first message PostText
+// would post, sent by the authenticated user to the same channel (or
+// thread) within window. It returns nil when there is none or when window
+// is not positive.
+func FindDuplicate(api SlackAPI, channelID, text string, opts PostOptions, window time.Duration) (*PostResult, error) {
+	if window <= 0 {
+		return nil, nil
+	}
+	chunks, _, err := planChunks(text, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := api.AuthTest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	oldest := fmt.Sprintf("%d.000000", time.Now().Add(-window).Unix())
+	var msgs []slackapi.Message
+	if opts.ThreadTS != "" {
+		msgs, _, _, err = api.GetConversationReplies(&slackapi.GetConversationRepliesParameters{
+			ChannelID: channelID,
+			Timestamp: opts.ThreadTS,
+			Oldest:    oldest,
+			Limit:     dedupHistoryLimit,
+		})
+	} else {
+		var resp *slackapi.GetConversationHistoryResponse
+		resp, err = api.GetConversationHistory(&slackapi.GetConversationHistoryParameters{
+			ChannelID: channelID,
+			Oldest:    oldest,
+			Limit:     dedupHistoryLimit,
+		})
+		if resp != nil {
+			msgs = resp.Messages
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check recent messages: %w", err)
+	}
+
+	want := normalizeForDedup(chunks[0].text)
+	for _, m := range msgs {
+		if m.User != auth.UserID || m.Timestamp == opts.ThreadTS {
+			continue
+		}
+		if normalizeForDedup(m.Text) == want {
+			return &PostResult{Channel: channelID, TS: m.Timestamp, ThreadTS: opts.ThreadTS, Duplicate: true}, nil
+		}
+	}
+	return nil, nil
+}
+
+// normalizeForDedup undoes Slack's &amp;/&lt;/&gt; escaping and surrounding
+// whitespace so posted text compares equal to what Slack stored.
+func normalizeForDedup(s string) string {
+	return html.UnescapeString(strings.TrimSpace(s))
+}
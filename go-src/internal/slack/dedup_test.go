@@ -0,0 +1,107 @@
+package slack
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	slackapi "github.com/slack-go/slack"
+)
+
+func dedupAPI(history []slackapi.Message) *MockSlackAPI {
+	return &MockSlackAPI{
+		AuthTestFunc: func() (*slackapi.AuthTestResponse, error) {
+			return &slackapi.AuthTestResponse{UserID: "U001"}, nil
+		},
+		GetConversationHistoryFunc: func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+			return &slackapi.GetConversationHistoryResponse{Messages: history}, nil
+		},
+		GetConversationRepliesFunc: func(params *slackapi.GetConversationRepliesParameters) ([]slackapi.Message, bool, string, error) {
+			return history, false, "", nil
+		},
+	}
+}
+
+func historyMessage(user, ts, text string) slackapi.Message {
+	return slackapi.Message{Msg: slackapi.Msg{User: user, Timestamp: ts, Text: text}}
+}
+
+func TestFindDuplicate(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []slackapi.Message
+		text    string
+		opts    PostOptions
+		wantTS  string
+	}{
+		{
+			name:    "SameUserSameText",
+			history: []slackapi.Message{historyMessage("U002", "3.0", "*done*"), historyMessage("U001", "2.0", "*done*")},
+			text:    "**done**",
+			wantTS:  "2.0",
+		},
+		{
+			name:    "OtherUserIgnored",
+			history: []slackapi.Message{historyMessage("U002", "3.0", "*done*")},
+			text:    "**done**",
+		},
+		{
+			name:    "DifferentText",
+			history: []slackapi.Message{historyMessage("U001", "3.0", "*done* already")},
+			text:    "**done**",
+		},
+		{
+			name:    "SlackEscaping",
+			history: []slackapi.Message{historyMessage("U001", "3.0", "a &lt; b &amp;&amp; c")},
+			text:    "a < b && c",
+			wantTS:  "3.0",
+		},
+		{
+			name:    "ThreadParentIgnored",
+			history: []slackapi.Message{historyMessage("U001", "1.0", "same"), historyMessage("U001", "4.0", "same")},
+			text:    "same",
+			opts:    PostOptions{ThreadTS: "1.0"},
+			wantTS:  "4.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := FindDuplicate(dedupAPI(tt.history), "C001", tt.text, tt.opts, time.Minute)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantTS == "" {
+				if result != nil {
+					t.Errorf("result = %+v, want none", result)
+				}
+				return
+			}
+			if result == nil || result.TS != tt.wantTS || !result.Duplicate || result.ThreadTS != tt.opts.ThreadTS {
+				t.Errorf("result = %+v, want ts %s", result, tt.wantTS)
+			}
+		})
+	}
+}
+
+func TestFindDuplicate_ZeroWindowSkipsLookup(t *testing.T) {
+	result, err := FindDuplicate(&MockSlackAPI{}, "C001", "hi", PostOptions{}, 0)
+
+	if err != nil || result != nil {
+		t.Errorf("result = %+v, err = %v", result, err)
+	}
+}
+
+func TestFindDuplicate_HistoryError(t *testing.T) {
+	api := dedupAPI(nil)
+	api.GetConversationHistoryFunc = func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error) {
+		return nil, errors.New("not_in_channel")
+	}
+
+	_, err := FindDuplicate(api, "C001", "hi", PostOptions{}, time.Minute)
+
+	if err == nil {
+		t.Error("expected error")
+	}
+}
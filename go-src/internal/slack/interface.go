@@ -10,9 +10,28 @@ type SlackAPI interface {
 	GetConversationInfo(input *slackapi.GetConversationInfoInput) (*slackapi.Channel, error)
 	GetConversationHistory(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error)
 	GetConversationReplies(params *slackapi.GetConversationRepliesParameters) ([]slackapi.Message, bool, string, error)
+	GetUsersInConversation(params *slackapi.GetUsersInConversationParameters) ([]string, string, error)
+	CreateConversation(params slackapi.CreateConversationParams) (*slackapi.Channel, error)
+	ArchiveConversation(channelID string) error
+	UnArchiveConversation(channelID string) error
+	RenameConversation(channelID, channelName string) (*slackapi.Channel, error)
+	SetTopicOfConversation(channelID, topic string) (*slackapi.Channel, error)
+	SetPurposeOfConversation(channelID, purpose string) (*slackapi.Channel, error)
+	InviteUsersToConversation(channelID string, users ...string) (*slackapi.Channel, error)
+	KickUserFromConversation(channelID string, user string) error
+	JoinConversation(channelID string) (*slackapi.Channel, string, []string, error)
+	LeaveConversation(channelID string) (bool, error)
+	MarkConversation(channel, ts string) error
+	OpenConversation(params *slackapi.OpenConversationParameters) (*slackapi.Channel, bool, bool, error)
 	PostMessage(channelID string, options ...slackapi.MsgOption) (string, string, error)
+	DeleteMessage(channelID, timestamp string) (string, string, error)
+	UploadFileV2(params slackapi.UploadFileV2Parameters) (*slackapi.FileSummary, error)
+	DeleteFile(fileID string) error
 	AddReaction(name string, ref slackapi.ItemRef) error
+	RemoveReaction(name string, ref slackapi.ItemRef) error
 	GetUsers(options ...slackapi.GetUsersOption) ([]slackapi.User, error)
 	GetUserInfo(userID string) (*slackapi.User, error)
+	GetUserByEmail(email string) (*slackapi.User, error)
+	GetUserGroups(options ...slackapi.GetUserGroupsOption) ([]slackapi.UserGroup, error)
 	SearchMessages(query string, params slackapi.SearchParameters) (*slackapi.SearchMessages, error)
 }
@@ -0,0 +1,340 @@
+package slack
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	slackapi "github.com/slack-go/slack"
+)
+
+// Message is the shared view model for a Slack message.
+//
+// Every command and MCP tool that returns messages renders this struct, so
+// bot posts, Block Kit content, attachments, reactions, files, edits and
+// subtypes (channel_join, channel_topic, ...) are represented consistently.
+type Message struct {
+	Ts         string            `json:"ts"`
+	Time       string            `json:"time"`
+	User       string            `json:"user"`
+	Username   string            `json:"username,omitempty"`
+	BotID      string            `json:"bot_id,omitempty"`
+	Subtype    string            `json:"subtype,omitempty"`
+	Text       string            `json:"text"`
+	ThreadTs   string            `json:"thread_ts,omitempty"`
+	ReplyCount int               `json:"reply_count,omitempty"`
+	Edited     *MessageEdit      `json:"edited,omitempty"`
+	Reactions  []MessageReaction `json:"reactions,omitempty"`
+	Files      []MessageFile     `json:"files,omitempty"`
+	ChannelID  string            `json:"channel_id,omitempty"`
+	Channel    string            `json:"channel,omitempty"`
+	Permalink  string            `json:"permalink,omitempty"`
+}
+
+// MessageEdit records who last edited a message and when.
+type MessageEdit struct {
+	User string `json:"user"`
+	Ts   string `json:"ts"`
+}
+
+// MessageReaction is an emoji reaction with its count and reacting users.
+type MessageReaction struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Users []string `json:"users,omitempty"`
+}
+
+// MessageFile is a reference to a file shared in a message.
+type MessageFile struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Title     string `json:"title,omitempty"`
+	Mimetype  string `json:"mimetype,omitempty"`
+	Size      int    `json:"size,omitempty"`
+	Permalink string `json:"permalink,omitempty"`
+}
+
+// SearchResult is the view model for a page of search.messages results.
+type SearchResult struct {
+	Matches []Message `json:"matches"`
+	Total   int       `json:"total"`
+	Page    int       `json:"page"`
+}
+
+// InboxItem is the view model for a message that may need the user's
+// response.
+type InboxItem struct {
+	Message
+	// Reason is why the message is in the inbox: dm, mention, group_dm,
+	// group_mention or broadcast.
+	Reason string `json:"reason"`
+	// Replied is set when the user has replied after the message, in its
+	// thread or, in a DM, in the conversation.
+	Replied bool `json:"replied"`
+	// Reacted is set when the user has reacted to the message.
+	Reacted bool `json:"reacted"`
+}
+
+// NewMessage converts a Slack API message into the shared view model.
+func NewMessage(msg slackapi.Message) Message {
+	out := Message{
+		Ts:         msg.Timestamp,
+		Time:       FormatTS(msg.Timestamp),
+		User:       msg.User,
+		Username:   msg.Username,
+		BotID:      msg.BotID,
+		Subtype:    msg.SubType,
+		Text:       messageText(msg.Text, msg.Blocks, msg.Attachments),
+		ThreadTs:   msg.ThreadTimestamp,
+		ReplyCount: msg.ReplyCount,
+		ChannelID:  msg.Channel,
+		Permalink:  msg.Permalink,
+	}
+	if out.Username == "" && msg.BotProfile != nil {
+		out.Username = msg.BotProfile.Name
+	}
+	if msg.Edited != nil {
+		out.Edited = &MessageEdit{User: msg.Edited.User, Ts: msg.Edited.Timestamp}
+	}
+	for _, r := range msg.Reactions {
+		out.Reactions = append(out.Reactions, MessageReaction{Name: r.Name, Count: r.Count, Users: r.Users})
+	}
+	for _, f := range msg.Files {
+		out.Files = append(out.Files, MessageFile{
+			ID:        f.ID,
+			Name:      f.Name,
+			Title:     f.Title,
+			Mimetype:  f.Mimetype,
+			Size:      f.Size,
+			Permalink: f.Permalink,
+		})
+	}
+	return out
+}
+
+// NewMessages converts a slice of Slack API messages into view models.
+func NewMessages(msgs []slackapi.Message) []Message {
+	out := make([]Message, len(msgs))
+	for i, msg := range msgs {
+		out[i] = NewMessage(msg)
+	}
+	return out
+}
+
+// NewSearchMatch converts a search.messages match into the shared view model.
+func NewSearchMatch(m slackapi.SearchMessage) Message {
+	return Message{
+		Ts:        m.Timestamp,
+		Time:      FormatTS(m.Timestamp),
+		User:      m.User,
+		Username:  m.Username,
+		Text:      messageText(m.Text, m.Blocks, m.Attachments),
+		ChannelID: m.Channel.ID,
+		Channel:   m.Channel.Name,
+		Permalink: m.Permalink,
+	}
+}
+
+// NewSearchResult converts a page of search.messages results into the view model.
+func NewSearchResult(result *slackapi.SearchMessages) SearchResult {
+	out := SearchResult{
+		Matches: make([]Message, 0, len(result.Matches)),
+		Total:   result.Total,
+		Page:    result.Paging.Page,
+	}
+	for _, m := range result.Matches {
+		out.Matches = append(out.Matches, NewSearchMatch(m))
+	}
+	return out
+}
+
+// Author returns the best available display identity for the message:
+// the user ID, then the bot username, then the bot ID.
+func (m Message) Author() string {
+	switch {
+	case m.User != "":
+		return m.User
+	case m.Username != "":
+		return m.Username
+	default:
+		return m.BotID
+	}
+}
+
+// FormatTS formats a Slack timestamp ("1675382400.123456") as local time.
+// Unparseable or zero timestamps are returned unchanged.
+func FormatTS(ts string) string {
+	sec, _, _ := strings.Cut(ts, ".")
+	var n int64
+	if _, err := fmt.Sscanf(sec, "%d", &n); err != nil || n == 0 {
+		return ts
+	}
+	return time.Unix(n, 0).Format("2006-01-02 15:04:05")
+}
+
+// CompareTS compares two Slack timestamps numerically, returning -1, 0 or +1.
+// Timestamps with different numbers of digits, such as "999.1" and
+// "1000.05", compare correctly; empty or unparseable ones count as zero.
+func CompareTS(a, b string) int {
+	as, af := splitTS(a)
+	bs, bf := splitTS(b)
+	switch {
+	case as < bs, as == bs && af < bf:
+		return -1
+	case as > bs, as == bs && af > bf:
+		return 1
+	}
+	return 0
+}
+
+// splitTS returns the seconds and microseconds of a Slack timestamp.
+func splitTS(ts string) (int64, int64) {
+	sec, frac, _ := strings.Cut(ts, ".")
+	s, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return 0, 0
+	}
+	frac = (frac + "000000")[:6]
+	f, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil {
+		return s, 0
+	}
+	return s, f
+}
+
+// messageText returns the message text, falling back to text extracted from
+// Block Kit blocks and then legacy attachments when the text field is empty.
+func messageText(text string, blocks slackapi.Blocks, attachments []slackapi.Attachment) string {
+	if text != "" {
+		return text
+	}
+	if t := BlocksText(blocks); t != "" {
+		return t
+	}
+	var parts []string
+	for _, a := range attachments {
+		if t := attachmentText(a); t != "" {
+			parts = append(parts, t)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func attachmentText(a slackapi.Attachment) string {
+	var parts []string
+	for _, s := range []string{a.Pretext, a.Title, a.Text} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	for _, f := range a.Fields {
+		parts = append(parts, f.Title+": "+f.Value)
+	}
+	if len(parts) == 0 {
+		if t := BlocksText(a.Blocks); t != "" {
+			return t
+		}
+		return a.Fallback
+	}
+	return strings.Join(parts, "\n")
+}
+
+// BlocksText extracts a plain mrkdwn text rendering of Block Kit blocks.
+func BlocksText(blocks slackapi.Blocks) string {
+	var parts []string
+	for _, b := range blocks.BlockSet {
+		if t := blockText(b); t != "" {
+			parts = append(parts, t)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func blockText(b slackapi.Block) string {
+	switch blk := b.(type) {
+	case *slackapi.SectionBlock:
+		var parts []string
+		if blk.Text != nil {
+			parts = append(parts, blk.Text.Text)
+		}
+		for _, f := range blk.Fields {
+			parts = append(parts, f.Text)
+		}
+		return strings.Join(parts, "\n")
+	case *slackapi.HeaderBlock:
+		if blk.Text != nil {
+			return blk.Text.Text
+		}
+	case *slackapi.ContextBlock:
+		var parts []string
+		for _, e := range blk.ContextElements.Elements {
+			if t, ok := e.(*slackapi.TextBlockObject); ok {
+				parts = append(parts, t.Text)
+			}
+		}
+		return strings.Join(parts, " ")
+	case *slackapi.RichTextBlock:
+		var sb strings.Builder
+		for _, e := range blk.Elements {
+			writeRichTextElement(&sb, e)
+		}
+		return strings.TrimRight(sb.String(), "\n")
+	}
+	return ""
+}
+
+func writeRichTextElement(sb *strings.Builder, e slackapi.RichTextElement) {
+	switch el := e.(type) {
+	case *slackapi.RichTextSection:
+		writeRichTextSection(sb, el.Elements)
+	case *slackapi.RichTextPreformatted:
+		sb.WriteString("```\n")
+		writeRichTextSection(sb, el.Elements)
+		sb.WriteString("\n```\n")
+	case *slackapi.RichTextQuote:
+		var inner strings.Builder
+		writeRichTextSection(&inner, el.Elements)
+		for _, line := range strings.Split(strings.TrimRight(inner.String(), "\n"), "\n") {
+			sb.WriteString("> " + line + "\n")
+		}
+	case *slackapi.RichTextList:
+		for i, item := range el.Elements {
+			sb.WriteString(strings.Repeat("    ", el.Indent))
+			if el.Style == slackapi.RTEListOrdered {
+				fmt.Fprintf(sb, "%d. ", el.Offset+i+1)
+			} else {
+				sb.WriteString("• ")
+			}
+			var inner strings.Builder
+			writeRichTextElement(&inner, item)
+			sb.WriteString(strings.TrimRight(inner.String(), "\n") + "\n")
+		}
+	}
+}
+
+func writeRichTextSection(sb *strings.Builder, elements []slackapi.RichTextSectionElement) {
+	for _, e := range elements {
+		switch el := e.(type) {
+		case *slackapi.RichTextSectionTextElement:
+			sb.WriteString(el.Text)
+		case *slackapi.RichTextSectionLinkElement:
+			if el.Text != "" {
+				sb.WriteString("<" + el.URL + "|" + el.Text + ">")
+			} else {
+				sb.WriteString("<" + el.URL + ">")
+			}
+		case *slackapi.RichTextSectionUserElement:
+			sb.WriteString("<@" + el.UserID + ">")
+		case *slackapi.RichTextSectionChannelElement:
+			sb.WriteString("<#" + el.ChannelID + ">")
+		case *slackapi.RichTextSectionUserGroupElement:
+			sb.WriteString("<!subteam^" + el.UsergroupID + ">")
+		case *slackapi.RichTextSectionBroadcastElement:
+			sb.WriteString("<!" + el.Range + ">")
+		case *slackapi.RichTextSectionEmojiElement:
+			sb.WriteString(":" + el.Name + ":")
+		}
+	}
+	sb.WriteString("\n")
+}
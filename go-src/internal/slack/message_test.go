@@ -0,0 +1,247 @@
+package slack
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	slackapi "github.com/slack-go/slack"
+)
+
+// jsonKeys marshals v and returns the sorted top-level keys of the resulting object.
+func jsonKeys(t *testing.T, v any) []string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestNewMessage_PlainUserMessageSchema(t *testing.T) {
+	msg := NewMessage(slackapi.Message{Msg: slackapi.Msg{
+		Timestamp: "1675382400.000000",
+		User:      "U001",
+		Text:      "hello",
+	}})
+
+	got := jsonKeys(t, msg)
+	want := []string{"text", "time", "ts", "user"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("keys = %v, want %v", got, want)
+	}
+}
+
+func TestNewMessage_FullSchema(t *testing.T) {
+	msg := NewMessage(slackapi.Message{Msg: slackapi.Msg{
+		Timestamp:       "1675382400.000000",
+		User:            "U001",
+		Text:            "report",
+		ThreadTimestamp: "1675382400.000000",
+		ReplyCount:      2,
+		SubType:         "bot_message",
+		BotID:           "B001",
+		Username:        "deploy-bot",
+		Edited:          &slackapi.Edited{User: "U001", Timestamp: "1675382500.000000"},
+		Reactions:       []slackapi.ItemReaction{{Name: "eyes", Count: 2, Users: []string{"U002", "U003"}}},
+		Files:           []slackapi.File{{ID: "F001", Name: "log.txt", Mimetype: "text/plain", Permalink: "https://example.com/F001"}},
+		Channel:         "C001",
+		Permalink:       "https://example.com/p1",
+	}})
+
+	got := jsonKeys(t, msg)
+	want := []string{
+		"bot_id", "channel_id", "edited", "files", "permalink", "reactions", "reply_count",
+		"subtype", "text", "thread_ts", "time", "ts", "user", "username",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("keys = %v, want %v", got, want)
+	}
+
+	if msg.Edited.Ts != "1675382500.000000" {
+		t.Errorf("edited.ts = %q", msg.Edited.Ts)
+	}
+	if len(msg.Reactions) != 1 || msg.Reactions[0].Name != "eyes" || msg.Reactions[0].Count != 2 {
+		t.Errorf("reactions = %+v", msg.Reactions)
+	}
+	if len(msg.Files) != 1 || msg.Files[0].ID != "F001" || msg.Files[0].Name != "log.txt" {
+		t.Errorf("files = %+v", msg.Files)
+	}
+}
+
+func TestNewMessage_SubtypeJoin(t *testing.T) {
+	msg := NewMessage(slackapi.Message{Msg: slackapi.Msg{
+		Timestamp: "1675382400.000000",
+		User:      "U001",
+		SubType:   "channel_join",
+		Text:      "<@U001> has joined the channel",
+	}})
+
+	if msg.Subtype != "channel_join" {
+		t.Errorf("subtype = %q, want channel_join", msg.Subtype)
+	}
+}
+
+func TestNewMessage_BotProfileName(t *testing.T) {
+	msg := NewMessage(slackapi.Message{Msg: slackapi.Msg{
+		BotID:      "B001",
+		BotProfile: &slackapi.BotProfile{Name: "CI"},
+		Text:       "build passed",
+	}})
+
+	if msg.Username != "CI" {
+		t.Errorf("username = %q, want CI", msg.Username)
+	}
+	if msg.Author() != "CI" {
+		t.Errorf("Author() = %q, want CI", msg.Author())
+	}
+}
+
+func TestNewMessage_TextFromBlocks(t *testing.T) {
+	raw := `{
+		"ts": "1675382400.000000",
+		"bot_id": "B001",
+		"text": "",
+		"blocks": [
+			{"type": "header", "text": {"type": "plain_text", "text": "Deploy"}},
+			{"type": "section", "text": {"type": "mrkdwn", "text": "*prod* is green"}},
+			{"type": "rich_text", "elements": [
+				{"type": "rich_text_section", "elements": [
+					{"type": "text", "text": "ping "},
+					{"type": "user", "user_id": "U001"},
+					{"type": "emoji", "name": "tada"}
+				]},
+				{"type": "rich_text_list", "style": "bullet", "indent": 0, "elements": [
+					{"type": "rich_text_section", "elements": [{"type": "text", "text": "one"}]},
+					{"type": "rich_text_section", "elements": [{"type": "text", "text": "two"}]}
+				]}
+			]}
+		]
+	}`
+	var apiMsg slackapi.Message
+	if err := json.Unmarshal([]byte(raw), &apiMsg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	msg := NewMessage(apiMsg)
+
+	want := "Deploy\n*prod* is green\nping <@U001>:tada:\n• one\n• two"
+	if msg.Text != want {
+		t.Errorf("text =\n%q\nwant\n%q", msg.Text, want)
+	}
+}
+
+func TestNewMessage_TextFromAttachments(t *testing.T) {
+	msg := NewMessage(slackapi.Message{Msg: slackapi.Msg{
+		BotID: "B001",
+		Attachments: []slackapi.Attachment{
+			{Pretext: "Alert", Title: "CPU high", Text: "95% on web-1"},
+			{Fallback: "fallback only"},
+		},
+	}})
+
+	want := "Alert\nCPU high\n95% on web-1\nfallback only"
+	if msg.Text != want {
+		t.Errorf("text = %q, want %q", msg.Text, want)
+	}
+}
+
+func TestNewMessage_TextTakesPrecedence(t *testing.T) {
+	msg := NewMessage(slackapi.Message{Msg: slackapi.Msg{
+		Text:        "original",
+		Attachments: []slackapi.Attachment{{Text: "attachment"}},
+	}})
+
+	if msg.Text != "original" {
+		t.Errorf("text = %q, want original", msg.Text)
+	}
+}
+
+func TestNewSearchResult_Schema(t *testing.T) {
+	result := NewSearchResult(&slackapi.SearchMessages{
+		Total:  1,
+		Paging: slackapi.Paging{Page: 2},
+		Matches: []slackapi.SearchMessage{{
+			Timestamp: "1675382400.000000",
+			User:      "U001",
+			Text:      "found",
+			Permalink: "https://example.com/p1",
+			Channel:   slackapi.CtxChannel{ID: "C001", Name: "general"},
+		}},
+	})
+
+	if got, want := jsonKeys(t, result), []string{"matches", "page", "total"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("keys = %v, want %v", got, want)
+	}
+	if got, want := jsonKeys(t, result.Matches[0]), []string{"channel", "channel_id", "permalink", "text", "time", "ts", "user"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("match keys = %v, want %v", got, want)
+	}
+	if result.Page != 2 || result.Total != 1 {
+		t.Errorf("page/total = %d/%d", result.Page, result.Total)
+	}
+}
+
+func TestNewSearchResult_EmptyMatchesIsArray(t *testing.T) {
+	result := NewSearchResult(&slackapi.SearchMessages{})
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if want := `{"matches":[],"total":0,"page":0}`; string(b) != want {
+		t.Errorf("json = %s, want %s", b, want)
+	}
+}
+
+func TestFormatTS(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want bool // true when the input should be returned unchanged
+	}{
+		{name: "Empty", in: "", want: true},
+		{name: "Invalid", in: "abc", want: true},
+		{name: "Zero", in: "0.000", want: true},
+		{name: "Valid", in: "1675382400.123456", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatTS(tt.in)
+			if (got == tt.in) != tt.want {
+				t.Errorf("FormatTS(%q) = %q", tt.in, got)
+			}
+		})
+	}
+}
+
+func TestCompareTS(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1675382400.000200", "1675382400.000100", 1},
+		{"1675382400.000100", "1675382400.000100", 0},
+		{"999999999.000000", "1000000000.000000", -1},
+		{"1675382400.5", "1675382400.400000", 1},
+		{"1675382400", "1675382400.000000", 0},
+		{"", "0000000000.000000", 0},
+		{"abc", "1.0", -1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareTS(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareTS(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -4,17 +4,36 @@ import slackapi "github.com/slack-go/slack"
 
 // MockSlackAPI is a test mock implementing SlackAPI.
 type MockSlackAPI struct {
-	AuthTestFunc                func() (*slackapi.AuthTestResponse, error)
-	GetConversationsForUserFunc func(params *slackapi.GetConversationsForUserParameters) ([]slackapi.Channel, string, error)
-	GetConversationsFunc        func(params *slackapi.GetConversationsParameters) ([]slackapi.Channel, string, error)
-	GetConversationInfoFunc     func(input *slackapi.GetConversationInfoInput) (*slackapi.Channel, error)
-	GetConversationHistoryFunc  func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error)
-	GetConversationRepliesFunc  func(params *slackapi.GetConversationRepliesParameters) ([]slackapi.Message, bool, string, error)
-	PostMessageFunc             func(channelID string, options ...slackapi.MsgOption) (string, string, error)
-	AddReactionFunc             func(name string, ref slackapi.ItemRef) error
-	GetUsersFunc                func(options ...slackapi.GetUsersOption) ([]slackapi.User, error)
-	GetUserInfoFunc             func(userID string) (*slackapi.User, error)
-	SearchMessagesFunc          func(query string, params slackapi.SearchParameters) (*slackapi.SearchMessages, error)
+	AuthTestFunc                  func() (*slackapi.AuthTestResponse, error)
+	GetConversationsForUserFunc   func(params *slackapi.GetConversationsForUserParameters) ([]slackapi.Channel, string, error)
+	GetConversationsFunc          func(params *slackapi.GetConversationsParameters) ([]slackapi.Channel, string, error)
+	GetConversationInfoFunc       func(input *slackapi.GetConversationInfoInput) (*slackapi.Channel, error)
+	GetConversationHistoryFunc    func(params *slackapi.GetConversationHistoryParameters) (*slackapi.GetConversationHistoryResponse, error)
+	GetConversationRepliesFunc    func(params *slackapi.GetConversationRepliesParameters) ([]slackapi.Message, bool, string, error)
+	GetUsersInConversationFunc    func(params *slackapi.GetUsersInConversationParameters) ([]string, string, error)
+	CreateConversationFunc        func(params slackapi.CreateConversationParams) (*slackapi.Channel, error)
+	ArchiveConversationFunc       func(channelID string) error
+	UnArchiveConversationFunc     func(channelID string) error
+	RenameConversationFunc        func(channelID, channelName string) (*slackapi.Channel, error)
+	SetTopicOfConversationFunc    func(channelID, topic string) (*slackapi.Channel, error)
+	SetPurposeOfConversationFunc  func(channelID, purpose string) (*slackapi.Channel, error)
+	InviteUsersToConversationFunc func(channelID string, users ...string) (*slackapi.Channel, error)
+	KickUserFromConversationFunc  func(channelID string, user string) error
+	JoinConversationFunc          func(channelID string) (*slackapi.Channel, string, []string, error)
+	LeaveConversationFunc         func(channelID string) (bool, error)
+	MarkConversationFunc          func(channel, ts string) error
+	OpenConversationFunc          func(params *slackapi.OpenConversationParameters) (*slackapi.Channel, bool, bool, error)
+	PostMessageFunc               func(channelID string, options ...slackapi.MsgOption) (string, string, error)
+	DeleteMessageFunc             func(channelID, timestamp string) (string, string, error)
+	UploadFileV2Func              func(params slackapi.UploadFileV2Parameters) (*slackapi.FileSummary, error)
+	DeleteFileFunc                func(fileID string) error
+	AddReactionFunc               func(name string, ref slackapi.ItemRef) error
+	RemoveReactionFunc            func(name string, ref slackapi.ItemRef) error
+	GetUsersFunc                  func(options ...slackapi.GetUsersOption) ([]slackapi.User, error)
+	GetUserInfoFunc               func(userID string) (*slackapi.User, error)
+	GetUserByEmailFunc            func(email string) (*slackapi.User, error)
+	GetUserGroupsFunc             func(options ...slackapi.GetUserGroupsOption) ([]slackapi.UserGroup, error)
+	SearchMessagesFunc            func(query string, params slackapi.SearchParameters) (*slackapi.SearchMessages, error)
 }
 
 func (m *MockSlackAPI) AuthTest() (*slackapi.AuthTestResponse, error) {
@@ -59,6 +78,97 @@ func (m *MockSlackAPI) GetConversationReplies(params *slackapi.GetConversationRe
 	panic("MockSlackAPI.GetConversationRepliesFunc not implemented")
 }
 
+func (m *MockSlackAPI) GetUsersInConversation(params *slackapi.GetUsersInConversationParameters) ([]string, string, error) {
+	if m.GetUsersInConversationFunc != nil {
+		return m.GetUsersInConversationFunc(params)
+	}
+	panic("MockSlackAPI.GetUsersInConversationFunc not implemented")
+}
+
+func (m *MockSlackAPI) CreateConversation(params slackapi.CreateConversationParams) (*slackapi.Channel, error) {
+	if m.CreateConversationFunc != nil {
+		return m.CreateConversationFunc(params)
+	}
+	panic("MockSlackAPI.CreateConversationFunc not implemented")
+}
+
+func (m *MockSlackAPI) ArchiveConversation(channelID string) error {
+	if m.ArchiveConversationFunc != nil {
+		return m.ArchiveConversationFunc(channelID)
+	}
+	panic("MockSlackAPI.ArchiveConversationFunc not implemented")
+}
+
+func (m *MockSlackAPI) UnArchiveConversation(channelID string) error {
+	if m.UnArchiveConversationFunc != nil {
+		return m.UnArchiveConversationFunc(channelID)
+	}
+	panic("MockSlackAPI.UnArchiveConversationFunc not implemented")
+}
+
+func (m *MockSlackAPI) RenameConversation(channelID, channelName string) (*slackapi.Channel, error) {
+	if m.RenameConversationFunc != nil {
+		return m.RenameConversationFunc(channelID, channelName)
+	}
+	panic("MockSlackAPI.RenameConversationFunc not implemented")
+}
+
+func (m *MockSlackAPI) SetTopicOfConversation(channelID, topic string) (*slackapi.Channel, error) {
+	if m.SetTopicOfConversationFunc != nil {
+		return m.SetTopicOfConversationFunc(channelID, topic)
+	}
+	panic("MockSlackAPI.SetTopicOfConversationFunc not implemented")
+}
+
+func (m *MockSlackAPI) SetPurposeOfConversation(channelID, purpose string) (*slackapi.Channel, error) {
+	if m.SetPurposeOfConversationFunc != nil {
+		return m.SetPurposeOfConversationFunc(channelID, purpose)
+	}
+	panic("MockSlackAPI.SetPurposeOfConversationFunc not implemented")
+}
+
+func (m *MockSlackAPI) InviteUsersToConversation(channelID string, users ...string) (*slackapi.Channel, error) {
+	if m.InviteUsersToConversationFunc != nil {
+		return m.InviteUsersToConversationFunc(channelID, users...)
+	}
+	panic("MockSlackAPI.InviteUsersToConversationFunc not implemented")
+}
+
+func (m *MockSlackAPI) KickUserFromConversation(channelID string, user string) error {
+	if m.KickUserFromConversationFunc != nil {
+		return m.KickUserFromConversationFunc(channelID, user)
+	}
+	panic("MockSlackAPI.KickUserFromConversationFunc not implemented")
+}
+
+func (m *MockSlackAPI) JoinConversation(channelID string) (*slackapi.Channel, string, []string, error) {
+	if m.JoinConversationFunc != nil {
+		return m.JoinConversationFunc(channelID)
+	}
+	panic("MockSlackAPI.JoinConversationFunc not implemented")
+}
+
+func (m *MockSlackAPI) LeaveConversation(channelID string) (bool, error) {
+	if m.LeaveConversationFunc != nil {
+		return m.LeaveConversationFunc(channelID)
+	}
+	panic("MockSlackAPI.LeaveConversationFunc not implemented")
+}
+
+func (m *MockSlackAPI) MarkConversation(channel, ts string) error {
+	if m.MarkConversationFunc != nil {
+		return m.MarkConversationFunc(channel, ts)
+	}
+	panic("MockSlackAPI.MarkConversationFunc not implemented")
+}
+
+func (m *MockSlackAPI) OpenConversation(params *slackapi.OpenConversationParameters) (*slackapi.Channel, bool, bool, error) {
+	if m.OpenConversationFunc != nil {
+		return m.OpenConversationFunc(params)
+	}
+	panic("MockSlackAPI.OpenConversationFunc not implemented")
+}
+
 func (m *MockSlackAPI) PostMessage(channelID string, options ...slackapi.MsgOption) (string, string, error) {
 	if m.PostMessageFunc != nil {
 		return m.PostMessageFunc(channelID, options...)
@@ -66,6 +176,27 @@ func (m *MockSlackAPI) PostMessage(channelID string, options ...slackapi.MsgOpti
 	panic("MockSlackAPI.PostMessageFunc not implemented")
 }
 
+func (m *MockSlackAPI) DeleteMessage(channelID, timestamp string) (string, string, error) {
+	if m.DeleteMessageFunc != nil {
+		return m.DeleteMessageFunc(channelID, timestamp)
+	}
+	panic("MockSlackAPI.DeleteMessageFunc not implemented")
+}
+
+func (m *MockSlackAPI) UploadFileV2(params slackapi.UploadFileV2Parameters) (*slackapi.FileSummary, error) {
+	if m.UploadFileV2Func != nil {
+		return m.UploadFileV2Func(params)
+	}
+	panic("MockSlackAPI.UploadFileV2Func not implemented")
+}
+
+func (m *MockSlackAPI) DeleteFile(fileID string) error {
+	if m.DeleteFileFunc != nil {
+		return m.DeleteFileFunc(fileID)
+	}
+	panic("MockSlackAPI.DeleteFileFunc not implemented")
+}
+
 func (m *MockSlackAPI) AddReaction(name string, ref slackapi.ItemRef) error {
 	if m.AddReactionFunc != nil {
 		return m.AddReactionFunc(name, ref)
@@ -73,6 +204,13 @@ func (m *MockSlackAPI) AddReaction(name string, ref slackapi.ItemRef) error {
 	panic("MockSlackAPI.AddReactionFunc not implemented")
 }
 
+func (m *MockSlackAPI) RemoveReaction(name string, ref slackapi.ItemRef) error {
+	if m.RemoveReactionFunc != nil {
+		return m.RemoveReactionFunc(name, ref)
+	}
+	panic("MockSlackAPI.RemoveReactionFunc not implemented")
+}
+
 func (m *MockSlackAPI) GetUsers(options ...slackapi.GetUsersOption) ([]slackapi.User, error) {
 	if m.GetUsersFunc != nil {
 		return m.GetUsersFunc(options...)
@@ -87,6 +225,20 @@ func (m *MockSlackAPI) GetUserInfo(userID string) (*slackapi.User, error) {
 	panic("MockSlackAPI.GetUserInfoFunc not implemented")
 }
 
+func (m *MockSlackAPI) GetUserByEmail(email string) (*slackapi.User, error) {
+	if m.GetUserByEmailFunc != nil {
+		return m.GetUserByEmailFunc(email)
+	}
+	panic("MockSlackAPI.GetUserByEmailFunc not implemented")
+}
+
+func (m *MockSlackAPI) GetUserGroups(options ...slackapi.GetUserGroupsOption) ([]slackapi.UserGroup, error) {
+	if m.GetUserGroupsFunc != nil {
+		return m.GetUserGroupsFunc(options...)
+	}
+	panic("MockSlackAPI.GetUserGroupsFunc not implemented")
+}
+
 func (m *MockSlackAPI) SearchMessages(query string, params slackapi.SearchParameters) (*slackapi.SearchMessages, error) {
 	if m.SearchMessagesFunc != nil {
 		return m.SearchMessagesFunc(query, params)
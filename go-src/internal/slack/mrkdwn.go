@@ -1,10 +1,27 @@
 package slack
 
-import "regexp"
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
-// Pre-compiled regexes for FixSlackMrkdwn transformations.
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Pre-compiled regexes for the CJK bold-spacing fixes applied after conversion.
 var (
 	// Step 1: Convert Markdown **bold** to Slack *bold*, normalizing trailing fullwidth colon.
+	// The parser handles most **bold**; this catches runs CommonMark's flanking
+	// rules reject, e.g. テスト**（注）**です.
 	reDoubleBold = regexp.MustCompile(`\*\*([^*]+)\*\*(\x{ff1a})?`)
 
 	// Step 2: Normalize fullwidth colon after single bold: *text*： → *text*: (with space)
@@ -12,35 +29,576 @@ var (
 
 	// Step 3: Insert space between bold close and non-ASCII char: *text*（ → *text* （
 	reNonASCIIAfterBold = regexp.MustCompile(`(\*[^*\n]+\*)([^\x00-\x7f])`)
+
+	// Slack's own angle-bracket syntax: <@U123>, <#C123|name>, <!here>, <https://x|label>.
+	reSlackToken = regexp.MustCompile(`^<(?:[@#!]|[a-zA-Z][a-zA-Z0-9+.\-]*:)[^<>\n]*>`)
 )
 
-// FixSlackMrkdwn fixes common Slack mrkdwn formatting issues.
+// Bullet glyphs used for each list nesting level (cycled for deeper levels).
+var listBullets = []string{"•", "◦", "▪"}
+
+// markdown is the shared Markdown parser. Indented code blocks and HTML
+// blocks are disabled: agent text rarely means them, and Slack renders
+// neither, so indented lines and lines starting with <!here> stay text.
+var markdown = goldmark.New(
+	goldmark.WithParser(parser.NewParser(
+		parser.WithBlockParsers(
+			util.Prioritized(parser.NewSetextHeadingParser(), 100),
+			util.Prioritized(parser.NewThematicBreakParser(), 200),
+			util.Prioritized(parser.NewListParser(), 300),
+			util.Prioritized(parser.NewListItemParser(), 400),
+			util.Prioritized(parser.NewATXHeadingParser(), 600),
+			util.Prioritized(parser.NewFencedCodeBlockParser(), 700),
+			util.Prioritized(parser.NewBlockquoteParser(), 800),
+			util.Prioritized(indentedParagraphParser{parser.NewParagraphParser()}, 1000),
+		),
+		parser.WithInlineParsers(append(parser.DefaultInlineParsers(),
+			util.Prioritized(slackTokenParser{}, 150))...),
+		parser.WithParagraphTransformers(parser.DefaultParagraphTransformers()...),
+	)),
+	goldmark.WithExtensions(extension.Table, extension.Strikethrough, extension.TaskList, extension.Linkify),
+)
+
+// FixSlackMrkdwn converts agent-written Markdown into Slack mrkdwn.
+//
+// The text is parsed as GitHub-flavored Markdown and re-emitted as mrkdwn:
+//   - **bold** and __bold__ become *bold*; Slack-style *bold* stays bold and
+//     _italic_ stays _italic_
+//   - ~~strike~~ becomes ~strike~
+//   - [text](url) and ![alt](url) become <url|text>
+//   - # headings become *bold* lines
+//   - lists become •/◦/▪ bullets or numbered lines; - [ ] tasks become ☐/☑
+//   - tables become aligned monospace blocks
+//   - fenced code drops its language tag (Slack cannot render it)
+//   - indented lines outside lists and quotes keep their indentation, and
+//     runs of blank lines are kept
 //
-// Slack's mrkdwn renderer fails to apply bold when a fullwidth character
-// immediately follows the closing asterisk. This function applies three
-// regex-based transformations:
-//  1. Convert Markdown **bold** to Slack *bold* (with optional fullwidth colon normalization)
+// Code spans, code blocks, URLs and Slack tokens such as <@U123> are
+// emitted untouched. Afterwards the CJK bold-spacing fixes are applied
+// outside of code, because Slack fails to apply bold when a fullwidth
+// character immediately follows the closing asterisk:
+//  1. Convert leftover Markdown **bold** to Slack *bold* (with optional fullwidth colon normalization)
 //  2. Normalize fullwidth colon (：) after bold to halfwidth colon with space
 //  3. Insert space between bold close marker and any non-ASCII character
 func FixSlackMrkdwn(text string) string {
-	// Step 1: **bold**（optional ：） → *bold*: or *bold*
-	text = reDoubleBold.ReplaceAllStringFunc(text, func(match string) string {
-		sub := reDoubleBold.FindStringSubmatch(match)
-		if len(sub) < 2 {
-			return match
+	if text == "" {
+		return ""
+	}
+	return fixBoldSpacing(markdownToMrkdwn(text))
+}
+
+// markdownToMrkdwn converts Markdown into Slack mrkdwn without applying the
+// CJK bold-spacing fixes. Most callers want FixSlackMrkdwn.
+func markdownToMrkdwn(src string) string {
+	source := []byte(src)
+	doc := markdown.Parser().Parse(text.NewReader(source))
+	c := &mrkdwnConverter{source: source}
+	return c.blocks(doc, 0)
+}
+
+// fixBoldSpacing applies the three regex-based CJK fixes outside code.
+func fixBoldSpacing(text string) string {
+	return mapOutsideCode(text, func(s string) string {
+		// Step 1: **bold**（optional ：） → *bold*: or *bold*
+		s = reDoubleBold.ReplaceAllStringFunc(s, func(match string) string {
+			sub := reDoubleBold.FindStringSubmatch(match)
+			if len(sub) < 2 {
+				return match
+			}
+			inner := sub[1]
+			if len(sub) >= 3 && sub[2] == "：" {
+				return "*" + inner + "*: "
+			}
+			return "*" + inner + "*"
+		})
+
+		// Step 2: *bold*： → *bold*: (space after halfwidth colon)
+		s = reFullwidthColon.ReplaceAllString(s, "${1}: ")
+
+		// Step 3: *bold*（non-ASCII） → *bold* （non-ASCII）
+		return reNonASCIIAfterBold.ReplaceAllString(s, "${1} ${2}")
+	})
+}
+
+// mapOutsideCode applies f to the parts of mrkdwn text that are not inside
+// ``` fences or `code spans`.
+func mapOutsideCode(s string, f func(string) string) string {
+	var sb strings.Builder
+	for s != "" {
+		start := strings.IndexByte(s, '`')
+		if start < 0 {
+			sb.WriteString(f(s))
+			break
+		}
+		sb.WriteString(f(s[:start]))
+		s = s[start:]
+
+		delim := "`"
+		if strings.HasPrefix(s, "```") {
+			delim = "```"
+		}
+		end := strings.Index(s[len(delim):], delim)
+		if end < 0 || (delim == "`" && strings.Contains(s[1:1+end], "\n")) {
+			// Unbalanced backtick: treat it as text.
+			sb.WriteString(f(s[:1]))
+			s = s[1:]
+			continue
+		}
+		end += 2 * len(delim)
+		sb.WriteString(s[:end])
+		s = s[end:]
+	}
+	return sb.String()
+}
+
+// mrkdwnConverter renders a goldmark AST as Slack mrkdwn.
+type mrkdwnConverter struct {
+	source []byte
+	// plain suppresses formatting markers, for headings (already bold)
+	// and table cells (rendered inside a code block).
+	plain bool
+}
+
+// blocks renders the block children of n, separated by as many blank lines
+// as the source had and by a single newline otherwise.
+func (c *mrkdwnConverter) blocks(n ast.Node, depth int) string {
+	var sb strings.Builder
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if child != n.FirstChild() {
+			sb.WriteString("\n")
+			if child.HasBlankPreviousLines() {
+				sb.WriteString(strings.Repeat("\n", c.blankLinesBefore(child)))
+			}
+		}
+		block := c.block(child, depth)
+		if _, ok := child.(*ast.Paragraph); ok && n.Kind() == ast.KindDocument {
+			block = c.indent(child, block)
 		}
-		inner := sub[1]
-		if len(sub) >= 3 && sub[2] == "\uff1a" {
-			return "*" + inner + "*: "
+		sb.WriteString(block)
+	}
+	return sb.String()
+}
+
+// blankLinesBefore returns the number of blank lines before block n in the
+// source, and at least 1.
+func (c *mrkdwnConverter) blankLinesBefore(n ast.Node) int {
+	start, ok := c.blockStart(n)
+	if !ok {
+		return 1
+	}
+	blanks := 0
+	for {
+		prev := bytes.LastIndexByte(c.source[:start], '\n')
+		if prev < 0 {
+			break
+		}
+		lineStart := bytes.LastIndexByte(c.source[:prev], '\n') + 1
+		if len(bytes.TrimSpace(c.source[lineStart:prev])) > 0 {
+			break
+		}
+		blanks++
+		start = lineStart
+	}
+	return max(blanks, 1)
+}
+
+// blockStart returns the offset of the start of the first source line of
+// block n.
+func (c *mrkdwnConverter) blockStart(n ast.Node) (int, bool) {
+	for ; n != nil; n = n.FirstChild() {
+		if code, ok := n.(*ast.FencedCodeBlock); ok {
+			// The opening fence is not among the lines: use the info
+			// string, or the line before the code.
+			if code.Info != nil {
+				return lineStart(c.source, code.Info.Segment.Start), true
+			}
+			if code.Lines().Len() > 0 {
+				start := lineStart(c.source, code.Lines().At(0).Start)
+				return lineStart(c.source, max(start-1, 0)), true
+			}
+			return 0, false
+		}
+		if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+			return lineStart(c.source, n.Lines().At(0).Start), true
 		}
+	}
+	return 0, false
+}
+
+// lineStart returns the offset of the start of the line containing offset.
+func lineStart(source []byte, offset int) int {
+	return bytes.LastIndexByte(source[:offset], '\n') + 1
+}
+
+// indent restores the indentation the source lines of paragraph n had,
+// which the parser strips, to its rendered lines.
+func (c *mrkdwnConverter) indent(n ast.Node, rendered string) string {
+	lines := strings.Split(rendered, "\n")
+	if len(lines) != n.Lines().Len() {
+		return rendered
+	}
+	for i := range lines {
+		start := n.Lines().At(i).Start
+		ls := lineStart(c.source, start)
+		if ws := c.source[ls:start]; len(bytes.TrimLeft(ws, " \t")) == 0 {
+			lines[i] = string(ws) + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c *mrkdwnConverter) block(n ast.Node, depth int) string {
+	switch node := n.(type) {
+	case *ast.Paragraph, *ast.TextBlock:
+		return c.inlines(node)
+	case *ast.Heading:
+		prev := c.plain
+		c.plain = true
+		inner := c.inlines(node)
+		c.plain = prev
 		return "*" + inner + "*"
-	})
+	case *ast.ThematicBreak:
+		return "────────"
+	case *ast.FencedCodeBlock:
+		return "```\n" + c.lines(node) + "```"
+	case *ast.CodeBlock:
+		return "```\n" + c.lines(node) + "```"
+	case *ast.HTMLBlock:
+		return strings.TrimSuffix(c.lines(node), "\n")
+	case *ast.Blockquote:
+		inner := c.blocks(node, depth)
+		lines := strings.Split(inner, "\n")
+		for i, line := range lines {
+			lines[i] = "> " + line
+		}
+		return strings.Join(lines, "\n")
+	case *ast.List:
+		return c.list(node, depth)
+	case *extast.Table:
+		return c.table(node)
+	default:
+		if n.Type() == ast.TypeInline {
+			return c.inline(n)
+		}
+		return c.blocks(n, depth)
+	}
+}
+
+// lines returns the raw source lines of a leaf block.
+func (c *mrkdwnConverter) lines(n ast.Node) string {
+	var sb strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		sb.Write(seg.Value(c.source))
+	}
+	return sb.String()
+}
+
+func (c *mrkdwnConverter) list(list *ast.List, depth int) string {
+	if isEmptyList(list) {
+		return listMarkers(list)
+	}
+	sep := "\n"
+	if !list.IsTight {
+		sep = "\n\n"
+	}
+	number := list.Start
+	var items []string
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		marker := listBullets[depth%len(listBullets)]
+		if list.IsOrdered() {
+			marker = strconv.Itoa(number) + "."
+			number++
+		}
+		content := c.blocks(item, depth+1)
+		lines := strings.Split(content, "\n")
+		for i := 1; i < len(lines); i++ {
+			if lines[i] != "" {
+				lines[i] = "    " + lines[i]
+			}
+		}
+		items = append(items, marker+" "+strings.Join(lines, "\n"))
+	}
+	return strings.Join(items, sep)
+}
+
+// isEmptyList reports whether every item of list is empty, as for a lone
+// "*" or "-" line, which is literal text rather than a list.
+func isEmptyList(list *ast.List) bool {
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		if item.HasChildren() {
+			return false
+		}
+	}
+	return true
+}
+
+// listMarkers renders the items of an empty list as their source markers.
+func listMarkers(list *ast.List) string {
+	number := list.Start
+	var lines []string
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		marker := string(list.Marker)
+		if list.IsOrdered() {
+			marker = strconv.Itoa(number) + marker
+			number++
+		}
+		lines = append(lines, marker)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// table renders a GFM table as an aligned monospace block, since Slack has
+// no table syntax.
+func (c *mrkdwnConverter) table(table *extast.Table) string {
+	prev := c.plain
+	c.plain = true
+	defer func() { c.plain = prev }()
+
+	var rows [][]string
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		var cells []string
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			cells = append(cells, strings.TrimSpace(c.inlines(cell)))
+		}
+		rows = append(rows, cells)
+	}
+
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if w := displayWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("```\n")
+	for r, row := range rows {
+		sb.WriteString(formatTableRow(row, widths, table.Alignments))
+		if r == 0 {
+			rule := make([]string, len(widths))
+			for i, w := range widths {
+				rule[i] = strings.Repeat("-", w)
+			}
+			sb.WriteString(strings.Join(rule, "-+-") + "\n")
+		}
+	}
+	sb.WriteString("```")
+	return sb.String()
+}
+
+func formatTableRow(row []string, widths []int, aligns []extast.Alignment) string {
+	cells := make([]string, len(widths))
+	for i, w := range widths {
+		cell := ""
+		if i < len(row) {
+			cell = row[i]
+		}
+		pad := w - displayWidth(cell)
+		align := extast.AlignNone
+		if i < len(aligns) {
+			align = aligns[i]
+		}
+		switch align {
+		case extast.AlignRight:
+			cell = strings.Repeat(" ", pad) + cell
+		case extast.AlignCenter:
+			cell = strings.Repeat(" ", pad/2) + cell + strings.Repeat(" ", pad-pad/2)
+		default:
+			cell += strings.Repeat(" ", pad)
+		}
+		cells[i] = cell
+	}
+	return strings.TrimRight(strings.Join(cells, " | "), " ") + "\n"
+}
+
+// displayWidth approximates the monospace column width of s, counting East
+// Asian wide characters and emoji as two columns.
+func displayWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		switch {
+		case r >= 0x1100 && r <= 0x115F, // Hangul Jamo
+			r >= 0x2E80 && r <= 0xA4CF && r != 0x303F, // CJK ... Yi
+			r >= 0xAC00 && r <= 0xD7A3,                // Hangul syllables
+			r >= 0xF900 && r <= 0xFAFF,                // CJK compatibility ideographs
+			r >= 0xFE30 && r <= 0xFE4F,                // CJK compatibility forms
+			r >= 0xFF00 && r <= 0xFF60,                // fullwidth forms
+			r >= 0xFFE0 && r <= 0xFFE6,
+			r >= 0x1F300 && r <= 0x1FAFF, // emoji
+			r >= 0x20000 && r <= 0x3FFFD:
+			w += 2
+		default:
+			w++
+		}
+	}
+	return w
+}
+
+// inlines renders the inline children of n.
+func (c *mrkdwnConverter) inlines(n ast.Node) string {
+	var sb strings.Builder
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		sb.WriteString(c.inline(child))
+	}
+	return sb.String()
+}
+
+func (c *mrkdwnConverter) inline(n ast.Node) string {
+	switch node := n.(type) {
+	case *ast.Text:
+		s := unescapePunctuation(string(node.Segment.Value(c.source)))
+		if node.SoftLineBreak() || node.HardLineBreak() {
+			s += "\n"
+		}
+		return s
+	case *ast.String:
+		return string(node.Value)
+	case *ast.CodeSpan:
+		var sb strings.Builder
+		for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+			if t, ok := child.(*ast.Text); ok {
+				sb.Write(t.Segment.Value(c.source))
+			}
+		}
+		if c.plain {
+			return sb.String()
+		}
+		return "`" + sb.String() + "`"
+	case *ast.Emphasis:
+		inner := c.inlines(node)
+		if c.plain {
+			return inner
+		}
+		if node.Level >= 2 || c.emphasisMarker(node) == '*' {
+			return "*" + inner + "*"
+		}
+		return "_" + inner + "_"
+	case *extast.Strikethrough:
+		inner := c.inlines(node)
+		if c.plain {
+			return inner
+		}
+		return "~" + inner + "~"
+	case *ast.Link:
+		return c.link(string(node.Destination), c.inlines(node))
+	case *ast.Image:
+		return c.link(string(node.Destination), c.inlines(node))
+	case *ast.AutoLink:
+		return string(node.Label(c.source))
+	case *ast.RawHTML:
+		var sb strings.Builder
+		for i := 0; i < node.Segments.Len(); i++ {
+			seg := node.Segments.At(i)
+			sb.Write(seg.Value(c.source))
+		}
+		return sb.String()
+	case *extast.TaskCheckBox:
+		if node.IsChecked {
+			return "☑ "
+		}
+		return "☐ "
+	default:
+		return c.inlines(n)
+	}
+}
+
+func (c *mrkdwnConverter) link(url, label string) string {
+	if c.plain {
+		if label == "" || label == url {
+			return url
+		}
+		return label + " (" + url + ")"
+	}
+	if label == "" || label == url {
+		return "<" + url + ">"
+	}
+	return "<" + url + "|" + label + ">"
+}
 
-	// Step 2: *bold*： → *bold*: (space after halfwidth colon)
-	text = reFullwidthColon.ReplaceAllString(text, "${1}: ")
+// emphasisMarker returns the delimiter character ('*' or '_') used in the
+// source for an emphasis node, found just before its first text segment.
+func (c *mrkdwnConverter) emphasisMarker(n *ast.Emphasis) byte {
+	for child := n.FirstChild(); child != nil; child = child.FirstChild() {
+		if t, ok := child.(*ast.Text); ok {
+			if start := t.Segment.Start - 1; start >= 0 && start < len(c.source) {
+				return c.source[start]
+			}
+			break
+		}
+	}
+	return '_'
+}
 
-	// Step 3: *bold*（non-ASCII） → *bold* （non-ASCII）
-	text = reNonASCIIAfterBold.ReplaceAllString(text, "${1} ${2}")
+// intraword reports whether n is a single-asterisk emphasis opened right
+// after a letter or digit, as in 2*3=6 and 4*5=20. Slack does not format
+// those, so the asterisks are literal text.
+func (c *mrkdwnConverter) intraword(n *ast.Emphasis) bool {
+	if n.Level != 1 || c.emphasisMarker(n) != '*' {
+		return false
+	}
+	for child := n.FirstChild(); child != nil; child = child.FirstChild() {
+		if t, ok := child.(*ast.Text); ok {
+			if open := t.Segment.Start - 1; open >= 1 {
+				r, _ := utf8.DecodeLastRune(c.source[:open])
+				return unicode.IsLetter(r) || unicode.IsDigit(r)
+			}
+			break
+		}
+	}
+	return false
+}
+
+// unescapePunctuation removes Markdown backslash escapes before ASCII punctuation.
+func unescapePunctuation(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && s[i+1] < utf8.RuneSelf && unicode.IsPunct(rune(s[i+1])) {
+			// Keep the escaped character, which may itself be a backslash.
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// slackTokenParser keeps Slack's own <...> syntax (mentions, channel links,
+// special commands and labelled URLs) as raw inline text so the Markdown
+// parser neither treats it as an autolink nor parses emphasis inside it.
+type slackTokenParser struct{}
+
+func (slackTokenParser) Trigger() []byte {
+	return []byte{'<'}
+}
+
+func (slackTokenParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	m := reSlackToken.Find(line)
+	if m == nil {
+		return nil
+	}
+	node := ast.NewRawHTML()
+	node.Segments.Append(text.NewSegment(segment.Start, segment.Start+len(m)))
+	block.Advance(len(m))
+	return node
+}
+
+// indentedParagraphParser lets paragraphs start on lines indented four or
+// more spaces, which would otherwise be dropped without the indented code
+// block parser.
+type indentedParagraphParser struct {
+	parser.BlockParser
+}
 
-	return text
+func (indentedParagraphParser) CanAcceptIndentedLine() bool {
+	return true
 }
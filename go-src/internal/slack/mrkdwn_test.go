@@ -78,6 +78,146 @@ func TestFixSlackMrkdwn(t *testing.T) {
 			in:   "Hello *world* test",
 			want: "Hello *world* test",
 		},
+		{
+			name: "Link",
+			in:   "See [the docs](https://example.com/docs) now",
+			want: "See <https://example.com/docs|the docs> now",
+		},
+		{
+			name: "LinkTextEqualsURL",
+			in:   "[https://example.com](https://example.com)",
+			want: "<https://example.com>",
+		},
+		{
+			name: "BareURLUntouched",
+			in:   "Open https://example.com/a_b_c?x=*y* please",
+			want: "Open https://example.com/a_b_c?x=*y* please",
+		},
+		{
+			name: "SlackTokensUntouched",
+			in:   "<@U123> see <#C456|general> and <https://x.io/a_b|link> <!here>",
+			want: "<@U123> see <#C456|general> and <https://x.io/a_b|link> <!here>",
+		},
+		{
+			name: "Headings",
+			in:   "# Title\n\n## Sub **bold** section\ntext",
+			want: "*Title*\n\n*Sub bold section*\ntext",
+		},
+		{
+			name: "HeadingWithCJKFollowingBold",
+			in:   "## 概要\n本文",
+			want: "*概要*\n本文",
+		},
+		{
+			name: "Italic",
+			in:   "an _italic_ word",
+			want: "an _italic_ word",
+		},
+		{
+			name: "DoubleUnderscoreBold",
+			in:   "__bold__ text",
+			want: "*bold* text",
+		},
+		{
+			name: "Strikethrough",
+			in:   "~~old~~ new",
+			want: "~old~ new",
+		},
+		{
+			name: "TaskList",
+			in:   "- [ ] todo\n- [x] done",
+			want: "• ☐ todo\n• ☑ done",
+		},
+		{
+			name: "NestedLists",
+			in:   "- one\n  - two\n    - three\n- four",
+			want: "• one\n    ◦ two\n        ▪ three\n• four",
+		},
+		{
+			name: "OrderedList",
+			in:   "3. c\n4. d",
+			want: "3. c\n4. d",
+		},
+		{
+			name: "FencedCodeDropsLanguage",
+			in:   "```go\nfmt.Println(\"**x**\")\n```",
+			want: "```\nfmt.Println(\"**x**\")\n```",
+		},
+		{
+			name: "CodeSpanUntouched",
+			in:   "run `a **b** _c_ [d](e)` now",
+			want: "run `a **b** _c_ [d](e)` now",
+		},
+		{
+			name: "CodeSpanCJKNotSpaced",
+			in:   "`*太字*（注）`",
+			want: "`*太字*（注）`",
+		},
+		{
+			name: "Blockquote",
+			in:   "> quoted **text**\n> more",
+			want: "> quoted *text*\n> more",
+		},
+		{
+			name: "ThematicBreak",
+			in:   "above\n\n---\n\nbelow",
+			want: "above\n\n────────\n\nbelow",
+		},
+		{
+			name: "Table",
+			in:   "| Name | Count |\n|------|------:|\n| 東京 | 5 |\n| **x** | 10 |",
+			want: "```\nName | Count\n-----+------\n東京 |     5\nx    |    10\n```",
+		},
+		{
+			name: "Image",
+			in:   "![diagram](https://example.com/d.png)",
+			want: "<https://example.com/d.png|diagram>",
+		},
+		{
+			name: "BackslashEscape",
+			in:   "not \\*bold\\*",
+			want: "not *bold*",
+		},
+		{
+			name: "IndentedLinesPassThrough",
+			in:   "list:\n\n    indented\n      deeper\n  back",
+			want: "list:\n\n    indented\n      deeper\n  back",
+		},
+		{
+			name: "IndentationInsideListIsStructural",
+			in:   "- item\n  continued",
+			want: "• item\n    continued",
+		},
+		{
+			name: "BlankLineRunsKept",
+			in:   "one\n\n\n\ntwo\n\n\n```\ncode\n```\n\n\n- three",
+			want: "one\n\n\n\ntwo\n\n\n```\ncode\n```\n\n\n• three",
+		},
+		{
+			name: "EscapedBackslashKept",
+			in:   `a\\_b`,
+			want: `a\_b`,
+		},
+		{
+			name: "LoneAsteriskLine",
+			in:   "above\n\n*\n\nbelow",
+			want: "above\n\n*\n\nbelow",
+		},
+		{
+			name: "LoneDashLine",
+			in:   "- ",
+			want: "-",
+		},
+		{
+			name: "IntrawordAsterisks",
+			in:   "2*3=6 and 4*5=20",
+			want: "2*3=6 and 4*5=20",
+		},
+		{
+			name: "SlackBoldStaysBold",
+			in:   "a *bold* word and **bold**",
+			want: "a *bold* word and *bold*",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,93 @@
+package slack
+
+// PostPlan describes what PostText would send, without sending it.
+type PostPlan struct {
+	Channel   string `json:"channel"`
+	ThreadTS  string `json:"thread_ts,omitempty"`
+	Broadcast bool   `json:"broadcast,omitempty"`
+	Format    string `json:"format"`
+	Overflow  string `json:"overflow"`
+	// Mrkdwn is the whole text after FixSlackMrkdwn, before splitting.
+	Mrkdwn string `json:"mrkdwn"`
+	// Messages lists every message that would be posted, in order.
+	Messages []PlannedMessage `json:"messages"`
+	// Upload is set when the text would be uploaded as a snippet; Messages
+	// then holds the preview posted with it.
+	Upload    *PlannedUpload `json:"upload,omitempty"`
+	Truncated bool           `json:"truncated,omitempty"`
+	// Warnings reports what the outbound safeguards found or redacted.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// PlannedMessage is one message of a PostPlan.
+type PlannedMessage struct {
+	Text  string `json:"text"`
+	Runes int    `json:"runes"`
+	// Blocks is the number of Block Kit blocks; Text is then the
+	// notification fallback.
+	Blocks int `json:"blocks,omitempty"`
+	// Placement is "channel", "thread <ts>" or "thread of message 1".
+	Placement string `json:"placement"`
+	Broadcast bool   `json:"broadcast,omitempty"`
+}
+
+// PlannedUpload is the snippet a PostPlan would upload.
+type PlannedUpload struct {
+	Filename string `json:"filename"`
+	Bytes    int    `json:"bytes"`
+}
+
+// firstMessagePlacement is the placement of chunks threaded under the
+// first message, whose timestamp is not known before posting.
+const firstMessagePlacement = "thread of message 1"
+
+// PlanPost returns what PostText would post for text, without calling Slack.
+func PlanPost(channelID, text string, opts PostOptions) (*PostPlan, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	chunks, truncated, err := planChunks(text, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &PostPlan{
+		Channel:   channelID,
+		ThreadTS:  opts.ThreadTS,
+		Broadcast: opts.Broadcast,
+		Format:    opts.Format,
+		Overflow:  opts.Overflow,
+		Mrkdwn:    FixSlackMrkdwn(text),
+		Truncated: truncated,
+	}
+	if plan.Format == "" {
+		plan.Format = FormatMrkdwn
+	}
+	if plan.Overflow == "" {
+		plan.Overflow = OverflowThread
+	}
+
+	if len(chunks) > 1 && opts.Overflow == OverflowFile {
+		plan.Upload = &PlannedUpload{Filename: snippetFilename, Bytes: len(text)}
+		chunks = []postChunk{truncateChunk(text, FormatMrkdwn, postChunk{})}
+	}
+
+	for i, chunk := range chunks {
+		placement := "channel"
+		switch threadTS := chunkThreadTS(i, opts, firstMessagePlacement); threadTS {
+		case "":
+		case firstMessagePlacement:
+			placement = firstMessagePlacement
+		default:
+			placement = "thread " + threadTS
+		}
+		plan.Messages = append(plan.Messages, PlannedMessage{
+			Text:      chunk.text,
+			Runes:     runeLen(chunk.text),
+			Blocks:    len(chunk.blocks),
+			Placement: placement,
+			Broadcast: chunkBroadcast(i, opts) && plan.Upload == nil,
+		})
+	}
+	return plan, nil
+}
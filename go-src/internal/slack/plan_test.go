@@ -0,0 +1,107 @@
+package slack
+
+import (
+	"strings"
+	"testing"
+)
+
+func placements(plan *PostPlan) string {
+	var s []string
+	for _, m := range plan.Messages {
+		s = append(s, m.Placement)
+	}
+	return strings.Join(s, ",")
+}
+
+func TestPlanPost_ShortMessage(t *testing.T) {
+	plan, err := PlanPost("C001", "**hi**", PostOptions{})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Mrkdwn != "*hi*" || plan.Format != FormatMrkdwn || plan.Overflow != OverflowThread {
+		t.Errorf("plan = %+v", plan)
+	}
+	if len(plan.Messages) != 1 || plan.Messages[0].Text != "*hi*" || plan.Messages[0].Runes != 4 || plan.Messages[0].Placement != "channel" {
+		t.Errorf("messages = %+v", plan.Messages)
+	}
+}
+
+func TestPlanPost_Placement(t *testing.T) {
+	tests := []struct {
+		name string
+		opts PostOptions
+		want string
+	}{
+		{"Thread", PostOptions{}, "channel,thread of message 1,thread of message 1"},
+		{"Sequential", PostOptions{Overflow: OverflowSequential}, "channel,channel,channel"},
+		{"Reply", PostOptions{ThreadTS: "1.0"}, "thread 1.0,thread 1.0,thread 1.0"},
+		{"Truncate", PostOptions{Overflow: OverflowTruncate}, "channel"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := PlanPost("C001", longText(), tt.opts)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := placements(plan); got != tt.want {
+				t.Errorf("placements = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanPost_MatchesPostText(t *testing.T) {
+	opts := PostOptions{Markers: true, ThreadTS: "1.0", Broadcast: true}
+	var posted []postedMessage
+	if _, err := PostText(recordingAPI(t, &posted), "C001", longText(), opts); err != nil {
+		t.Fatalf("PostText: %v", err)
+	}
+
+	plan, err := PlanPost("C001", longText(), opts)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Messages) != len(posted) {
+		t.Fatalf("planned %d messages, posted %d", len(plan.Messages), len(posted))
+	}
+	for i, m := range plan.Messages {
+		if m.Text != posted[i].text || m.Broadcast != posted[i].broadcast || m.Runes != len([]rune(m.Text)) {
+			t.Errorf("message %d: planned %+v, posted %+v", i, m, posted[i])
+		}
+	}
+}
+
+func TestPlanPost_Blocks(t *testing.T) {
+	plan, err := PlanPost("C001", "# Title\n\n- a\n- b", PostOptions{Format: FormatBlocks})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Messages) != 1 || plan.Messages[0].Blocks != 2 {
+		t.Errorf("messages = %+v", plan.Messages)
+	}
+}
+
+func TestPlanPost_FileUpload(t *testing.T) {
+	text := longText()
+	plan, err := PlanPost("C001", text, PostOptions{Overflow: OverflowFile})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Upload == nil || plan.Upload.Filename != snippetFilename || plan.Upload.Bytes != len(text) {
+		t.Errorf("upload = %+v", plan.Upload)
+	}
+	if len(plan.Messages) != 1 || !strings.HasSuffix(plan.Messages[0].Text, TruncatedMarker) {
+		t.Errorf("messages = %+v", plan.Messages)
+	}
+}
+
+func TestPlanPost_InvalidOptions(t *testing.T) {
+	if _, err := PlanPost("C001", "hi", PostOptions{Overflow: "bogus"}); err == nil {
+		t.Error("expected error for unknown overflow strategy")
+	}
+}
@@ -0,0 +1,275 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+
+	slackapi "github.com/slack-go/slack"
+)
+
+// Message formats accepted by PostOptions.Format.
+const (
+	// FormatMrkdwn converts Markdown into mrkdwn text (the default).
+	FormatMrkdwn = "mrkdwn"
+	// FormatBlocks converts Markdown into Block Kit blocks.
+	FormatBlocks = "blocks"
+)
+
+// Overflow strategies for text that does not fit in a single message.
+const (
+	// OverflowThread posts the first chunk and threads the rest under it (the default).
+	OverflowThread = "thread"
+	// OverflowSequential posts every chunk as its own message.
+	OverflowSequential = "sequential"
+	// OverflowTruncate posts only the first chunk, ending with TruncatedMarker.
+	OverflowTruncate = "truncate"
+	// OverflowFile uploads the full text as a snippet with a truncated preview.
+	OverflowFile = "file"
+)
+
+// OverflowStrategies lists the accepted PostOptions.Overflow values.
+var OverflowStrategies = []string{OverflowThread, OverflowSequential, OverflowTruncate, OverflowFile}
+
+// TruncatedMarker ends text cut short by OverflowTruncate and OverflowFile.
+const TruncatedMarker = "…(truncated)"
+
+// markerReserve is the room kept in each chunk for a "\n(12/34)" marker.
+const markerReserve = 10
+
+// snippetFilename is the name given to text uploaded by OverflowFile.
+const snippetFilename = "message.md"
+
+// PostOptions controls how PostText delivers a message.
+type PostOptions struct {
+	// Format is FormatMrkdwn or FormatBlocks; empty means FormatMrkdwn.
+	Format string
+	// Overflow is one of OverflowStrategies; empty means OverflowThread.
+	Overflow string
+	// Markers appends (1/3)-style continuation markers when the text is
+	// posted as several messages.
+	Markers bool
+	// ThreadTS posts into an existing thread instead of the channel.
+	ThreadTS string
+	// Broadcast also sends a thread reply to the channel. Only the first
+	// message of a split reply is broadcast.
+	Broadcast bool
+	// Posted lists the messages an earlier, interrupted attempt already
+	// posted for this text. PostText continues with the chunks after them.
+	Posted []string
+}
+
+// Validate checks the format and overflow strategy.
+func (o PostOptions) Validate() error {
+	switch o.Format {
+	case "", FormatMrkdwn, FormatBlocks:
+	default:
+		return fmt.Errorf("unknown message format %q (valid: %s, %s)", o.Format, FormatMrkdwn, FormatBlocks)
+	}
+	switch o.Overflow {
+	case "", OverflowThread, OverflowSequential, OverflowTruncate, OverflowFile:
+	default:
+		return fmt.Errorf("unknown overflow strategy %q (valid: %s)", o.Overflow, strings.Join(OverflowStrategies, ", "))
+	}
+	return nil
+}
+
+// PostResult describes what PostText posted.
+type PostResult struct {
+	Channel  string `json:"channel"`
+	TS       string `json:"ts,omitempty"`
+	ThreadTS string `json:"thread_ts,omitempty"`
+	// Timestamps lists every posted message, in order, when the text was
+	// split; TS is always the first of them.
+	Timestamps []string `json:"timestamps,omitempty"`
+	// FileID is set when the text was uploaded as a snippet.
+	FileID string `json:"file_id,omitempty"`
+	// Truncated is set when part of the text was not posted.
+	Truncated bool `json:"truncated,omitempty"`
+	// Duplicate is set when an earlier identical post was returned instead
+	// of posting again.
+	Duplicate bool `json:"duplicate,omitempty"`
+	// Warnings reports what the outbound safeguards found or redacted.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// postChunk is the content of a single message.
+type postChunk struct {
+	text   string
+	blocks []slackapi.Block
+}
+
+func (c postChunk) options() []slackapi.MsgOption {
+	opts := []slackapi.MsgOption{slackapi.MsgOptionText(c.text, false)}
+	if len(c.blocks) > 0 {
+		opts = append(opts, slackapi.MsgOptionBlocks(c.blocks...))
+	}
+	return opts
+}
+
+// PostText converts Markdown text and posts it to channelID, splitting text
+// that exceeds MaxMessageLength (or the Block Kit limits) according to
+// opts.Overflow.
+func PostText(api SlackAPI, channelID, text string, opts PostOptions) (*PostResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	chunks, truncated, err := planChunks(text, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PostResult{Channel: channelID, ThreadTS: opts.ThreadTS, Truncated: truncated}
+	if len(chunks) > 1 && opts.Overflow == OverflowFile {
+		return uploadSnippet(api, channelID, text, opts, result)
+	}
+
+	for i, chunk := range chunks {
+		if i < len(opts.Posted) {
+			if i == 0 {
+				result.TS = opts.Posted[0]
+			}
+			result.Timestamps = append(result.Timestamps, opts.Posted[i])
+			continue
+		}
+		msgOpts := chunk.options()
+		if threadTS := chunkThreadTS(i, opts, result.TS); threadTS != "" {
+			msgOpts = append(msgOpts, slackapi.MsgOptionTS(threadTS))
+		}
+		if chunkBroadcast(i, opts) {
+			msgOpts = append(msgOpts, slackapi.MsgOptionBroadcast())
+		}
+
+		_, ts, err := api.PostMessage(channelID, msgOpts...)
+		if err != nil {
+			switch {
+			case i > 0:
+				return result, fmt.Errorf("failed to post continuation %d/%d: %w", i+1, len(chunks), err)
+			case opts.ThreadTS != "":
+				return nil, fmt.Errorf("failed to reply: %w", err)
+			default:
+				return nil, fmt.Errorf("failed to post message: %w", err)
+			}
+		}
+		if i == 0 {
+			result.TS = ts
+		}
+		if len(chunks) > 1 {
+			result.Timestamps = append(result.Timestamps, ts)
+		}
+	}
+
+	return result, nil
+}
+
+// chunkThreadTS returns the thread the i-th chunk of a message is posted
+// in, given the timestamp of the first chunk, or "" for the channel.
+func chunkThreadTS(i int, opts PostOptions, firstTS string) string {
+	if opts.ThreadTS != "" {
+		return opts.ThreadTS
+	}
+	if i > 0 && opts.Overflow != OverflowSequential {
+		return firstTS
+	}
+	return ""
+}
+
+// chunkBroadcast reports whether the i-th chunk of a reply is also sent to
+// the channel.
+func chunkBroadcast(i int, opts PostOptions) bool {
+	return i == 0 && opts.Broadcast && opts.ThreadTS != ""
+}
+
+// planChunks returns the messages PostText posts for text, applying the
+// truncate strategy and continuation markers. It reports whether the text
+// was truncated. For OverflowFile the untouched chunks are returned.
+func planChunks(text string, opts PostOptions) ([]postChunk, bool, error) {
+	chunks, err := buildChunks(text, opts.Format, MaxMessageLength)
+	if err != nil || len(chunks) == 1 {
+		return chunks, false, err
+	}
+
+	switch {
+	case opts.Overflow == OverflowTruncate:
+		return []postChunk{truncateChunk(text, opts.Format, chunks[0])}, true, nil
+	case opts.Overflow == OverflowFile:
+		return chunks, false, nil
+	case opts.Markers:
+		chunks, err = buildChunks(text, opts.Format, MaxMessageLength-markerReserve)
+		if err != nil {
+			return nil, false, err
+		}
+		addMarkers(chunks)
+	}
+	return chunks, false, nil
+}
+
+// buildChunks converts text in the given format and splits it into
+// messages of at most maxLen runes.
+func buildChunks(text, format string, maxLen int) ([]postChunk, error) {
+	var chunks []postChunk
+	if format == FormatBlocks {
+		for _, msg := range MarkdownToBlocks(text) {
+			chunks = append(chunks, postChunk{text: truncateRunes(msg.Text, maxLen), blocks: msg.Blocks})
+		}
+		if len(chunks) == 0 {
+			return nil, fmt.Errorf("text produced no blocks")
+		}
+		return chunks, nil
+	}
+
+	for _, chunk := range SplitMessage(FixSlackMrkdwn(text), maxLen) {
+		chunks = append(chunks, postChunk{text: chunk})
+	}
+	return chunks, nil
+}
+
+// truncateChunk returns the first message of text, cut to leave room for
+// TruncatedMarker.
+func truncateChunk(text, format string, first postChunk) postChunk {
+	marker := "\n" + TruncatedMarker
+	if format == FormatBlocks {
+		blocks := first.blocks
+		if len(blocks) >= MaxBlocksPerMessage {
+			blocks = blocks[:MaxBlocksPerMessage-1]
+		}
+		blocks = append(blocks[:len(blocks):len(blocks)], markerBlock(TruncatedMarker))
+		return postChunk{text: truncateRunes(first.text, MaxMessageLength-runeLen(marker)) + marker, blocks: blocks}
+	}
+	return postChunk{text: SplitMessage(FixSlackMrkdwn(text), MaxMessageLength-runeLen(marker))[0] + marker}
+}
+
+// addMarkers appends "(i/n)" to every chunk.
+func addMarkers(chunks []postChunk) {
+	for i := range chunks {
+		marker := fmt.Sprintf("(%d/%d)", i+1, len(chunks))
+		chunks[i].text += "\n" + marker
+		if len(chunks[i].blocks) > 0 && len(chunks[i].blocks) < MaxBlocksPerMessage {
+			chunks[i].blocks = append(chunks[i].blocks, markerBlock(marker))
+		}
+	}
+}
+
+func markerBlock(marker string) slackapi.Block {
+	return slackapi.NewContextBlock("", slackapi.NewTextBlockObject(slackapi.MarkdownType, marker, false, false))
+}
+
+// uploadSnippet uploads the full Markdown text as a snippet, with a
+// truncated mrkdwn preview as its comment.
+func uploadSnippet(api SlackAPI, channelID, text string, opts PostOptions, result *PostResult) (*PostResult, error) {
+	preview := truncateChunk(text, FormatMrkdwn, postChunk{})
+	file, err := api.UploadFileV2(slackapi.UploadFileV2Parameters{
+		Channel:         channelID,
+		Content:         text,
+		FileSize:        len(text),
+		Filename:        snippetFilename,
+		Title:           snippetFilename,
+		InitialComment:  preview.text,
+		ThreadTimestamp: opts.ThreadTS,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload message as file: %w", err)
+	}
+	result.FileID = file.ID
+	return result, nil
+}
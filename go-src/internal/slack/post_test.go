@@ -0,0 +1,393 @@
+package slack
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	slackapi "github.com/slack-go/slack"
+)
+
+// postedMessage is a message captured by recordingAPI.
+type postedMessage struct {
+	text      string
+	blocks    string
+	threadTS  string
+	broadcast bool
+}
+
+// recordingAPI returns a mock that records posted messages, answering with
+// timestamps 1.0, 2.0, ...
+func recordingAPI(t *testing.T, posted *[]postedMessage) *MockSlackAPI {
+	return &MockSlackAPI{
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			_, values, err := slackapi.UnsafeApplyMsgOptions("", channelID, "", options...)
+			if err != nil {
+				t.Fatalf("apply options: %v", err)
+			}
+			*posted = append(*posted, postedMessage{
+				text:      values.Get("text"),
+				blocks:    values.Get("blocks"),
+				threadTS:  values.Get("thread_ts"),
+				broadcast: values.Get("reply_broadcast") == "true",
+			})
+			return channelID, fmt.Sprintf("%d.0", len(*posted)), nil
+		},
+	}
+}
+
+// longText returns Markdown that converts to three messages.
+func longText() string {
+	return strings.Repeat("a", 3000) + "\n\n" + strings.Repeat("b", 3000) + "\n\n" + strings.Repeat("c", 3000)
+}
+
+func TestPostText_ShortMessage(t *testing.T) {
+	var posted []postedMessage
+
+	result, err := PostText(recordingAPI(t, &posted), "C001", "**hi**", PostOptions{})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(posted) != 1 || posted[0].text != "*hi*" || posted[0].threadTS != "" {
+		t.Errorf("posted = %+v", posted)
+	}
+	if result.Channel != "C001" || result.TS != "1.0" || result.ThreadTS != "" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestPostText_OverflowThread(t *testing.T) {
+	var posted []postedMessage
+
+	result, err := PostText(recordingAPI(t, &posted), "C001", longText(), PostOptions{Overflow: OverflowThread})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(posted) != 3 {
+		t.Fatalf("expected 3 posts, got %d", len(posted))
+	}
+	if posted[0].threadTS != "" || posted[1].threadTS != "1.0" || posted[2].threadTS != "1.0" {
+		t.Errorf("thread_ts = %q, %q, %q", posted[0].threadTS, posted[1].threadTS, posted[2].threadTS)
+	}
+	if result.TS != "1.0" {
+		t.Errorf("ts = %q, want first message", result.TS)
+	}
+}
+
+func TestPostText_OverflowSequential(t *testing.T) {
+	var posted []postedMessage
+
+	_, err := PostText(recordingAPI(t, &posted), "C001", longText(), PostOptions{Overflow: OverflowSequential})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(posted) != 3 {
+		t.Fatalf("expected 3 posts, got %d", len(posted))
+	}
+	for i, p := range posted {
+		if p.threadTS != "" {
+			t.Errorf("post %d threaded under %q", i, p.threadTS)
+		}
+	}
+}
+
+func TestPostText_OverflowTruncate(t *testing.T) {
+	var posted []postedMessage
+
+	result, err := PostText(recordingAPI(t, &posted), "C001", longText(), PostOptions{Overflow: OverflowTruncate})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(posted) != 1 {
+		t.Fatalf("expected 1 post, got %d", len(posted))
+	}
+	if !strings.HasSuffix(posted[0].text, "\n"+TruncatedMarker) {
+		t.Errorf("text does not end with marker: %q", posted[0].text[len(posted[0].text)-30:])
+	}
+	if n := utf8.RuneCountInString(posted[0].text); n > MaxMessageLength {
+		t.Errorf("text runes = %d, exceeds %d", n, MaxMessageLength)
+	}
+	if !result.Truncated {
+		t.Error("expected truncated result")
+	}
+}
+
+func TestPostText_OverflowTruncateShortText(t *testing.T) {
+	var posted []postedMessage
+
+	result, err := PostText(recordingAPI(t, &posted), "C001", "short", PostOptions{Overflow: OverflowTruncate})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if posted[0].text != "short" || result.Truncated {
+		t.Errorf("short text was truncated: %+v", posted[0])
+	}
+}
+
+func TestPostText_OverflowTruncateBlocks(t *testing.T) {
+	var posted []postedMessage
+	text := strings.Repeat("# h\n", MaxBlocksPerMessage+5)
+
+	_, err := PostText(recordingAPI(t, &posted), "C001", text, PostOptions{Format: FormatBlocks, Overflow: OverflowTruncate})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(posted) != 1 {
+		t.Fatalf("expected 1 post, got %d", len(posted))
+	}
+	if strings.Count(posted[0].blocks, `"type":"header"`) != MaxBlocksPerMessage-1 {
+		t.Errorf("expected %d headers", MaxBlocksPerMessage-1)
+	}
+	if !strings.Contains(posted[0].blocks, `"type":"context"`) || !strings.Contains(posted[0].blocks, TruncatedMarker) {
+		t.Errorf("missing truncation marker block: %s", posted[0].blocks)
+	}
+}
+
+func TestPostText_OverflowFile(t *testing.T) {
+	var uploaded slackapi.UploadFileV2Parameters
+	api := &MockSlackAPI{
+		UploadFileV2Func: func(params slackapi.UploadFileV2Parameters) (*slackapi.FileSummary, error) {
+			uploaded = params
+			return &slackapi.FileSummary{ID: "F001"}, nil
+		},
+	}
+	text := longText()
+
+	result, err := PostText(api, "C001", text, PostOptions{Overflow: OverflowFile, ThreadTS: "9.0"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if uploaded.Content != text || uploaded.FileSize != len(text) {
+		t.Error("full text was not uploaded")
+	}
+	if uploaded.Channel != "C001" || uploaded.ThreadTimestamp != "9.0" || uploaded.Filename != "message.md" {
+		t.Errorf("upload params = %+v", uploaded)
+	}
+	if !strings.HasSuffix(uploaded.InitialComment, TruncatedMarker) {
+		t.Error("initial comment should be a truncated preview")
+	}
+	if result.FileID != "F001" {
+		t.Errorf("file_id = %q", result.FileID)
+	}
+}
+
+func TestPostText_OverflowFileShortTextPostsMessage(t *testing.T) {
+	var posted []postedMessage
+
+	result, err := PostText(recordingAPI(t, &posted), "C001", "short", PostOptions{Overflow: OverflowFile})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(posted) != 1 || result.FileID != "" {
+		t.Errorf("expected a plain message, got %+v", result)
+	}
+}
+
+func TestPostText_ContinuationMarkers(t *testing.T) {
+	var posted []postedMessage
+
+	_, err := PostText(recordingAPI(t, &posted), "C001", longText(), PostOptions{Markers: true})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, p := range posted {
+		want := fmt.Sprintf("\n(%d/3)", i+1)
+		if !strings.HasSuffix(p.text, want) {
+			t.Errorf("post %d does not end with %q", i, want)
+		}
+		if n := utf8.RuneCountInString(p.text); n > MaxMessageLength {
+			t.Errorf("post %d runes = %d, exceeds %d", i, n, MaxMessageLength)
+		}
+	}
+}
+
+func TestPostText_ContinuationMarkersSingleMessage(t *testing.T) {
+	var posted []postedMessage
+
+	_, err := PostText(recordingAPI(t, &posted), "C001", "short", PostOptions{Markers: true})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if posted[0].text != "short" {
+		t.Errorf("text = %q, want no marker", posted[0].text)
+	}
+}
+
+func TestPostText_ReplySplitsIntoThread(t *testing.T) {
+	var posted []postedMessage
+
+	result, err := PostText(recordingAPI(t, &posted), "C001", longText(), PostOptions{ThreadTS: "9.0", Overflow: OverflowSequential})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(posted) != 3 {
+		t.Fatalf("expected 3 posts, got %d", len(posted))
+	}
+	for i, p := range posted {
+		if p.threadTS != "9.0" {
+			t.Errorf("post %d thread_ts = %q, want 9.0", i, p.threadTS)
+		}
+	}
+	if result.ThreadTS != "9.0" || result.TS != "1.0" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestPostText_Errors(t *testing.T) {
+	failing := &MockSlackAPI{
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			return "", "", errors.New("channel_not_found")
+		},
+	}
+
+	tests := []struct {
+		name    string
+		api     SlackAPI
+		text    string
+		opts    PostOptions
+		wantErr string
+	}{
+		{name: "UnknownFormat", api: &MockSlackAPI{}, text: "x", opts: PostOptions{Format: "html"}, wantErr: `unknown message format "html"`},
+		{name: "UnknownOverflow", api: &MockSlackAPI{}, text: "x", opts: PostOptions{Overflow: "drop"}, wantErr: `unknown overflow strategy "drop"`},
+		{name: "PostFailed", api: failing, text: "x", wantErr: "failed to post message: channel_not_found"},
+		{name: "ReplyFailed", api: failing, text: "x", opts: PostOptions{ThreadTS: "1.0"}, wantErr: "failed to reply: channel_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := PostText(tt.api, "C001", tt.text, tt.opts)
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPostText_ContinuationFailureKeepsResult(t *testing.T) {
+	calls := 0
+	api := &MockSlackAPI{
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			calls++
+			if calls == 2 {
+				return "", "", errors.New("rate_limited")
+			}
+			return channelID, "1.0", nil
+		},
+	}
+
+	result, err := PostText(api, "C001", longText(), PostOptions{})
+
+	if err == nil || !strings.Contains(err.Error(), "failed to post continuation 2/3") {
+		t.Errorf("err = %v", err)
+	}
+	if result == nil || result.TS != "1.0" {
+		t.Errorf("result = %+v, want first message reported", result)
+	}
+}
+
+func TestPostText_ReplyBroadcastsFirstChunkOnly(t *testing.T) {
+	var posted []postedMessage
+
+	result, err := PostText(recordingAPI(t, &posted), "C001", longText(), PostOptions{ThreadTS: "9.0", Broadcast: true})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(posted) != 3 {
+		t.Fatalf("expected 3 posts, got %d", len(posted))
+	}
+	for i, p := range posted {
+		if p.threadTS != "9.0" {
+			t.Errorf("post %d thread_ts = %q, want 9.0", i, p.threadTS)
+		}
+		if p.broadcast != (i == 0) {
+			t.Errorf("post %d broadcast = %v", i, p.broadcast)
+		}
+	}
+	if strings.Join(result.Timestamps, ",") != "1.0,2.0,3.0" {
+		t.Errorf("timestamps = %v", result.Timestamps)
+	}
+}
+
+func TestPostText_BroadcastIgnoredOutsideThread(t *testing.T) {
+	var posted []postedMessage
+
+	_, err := PostText(recordingAPI(t, &posted), "C001", "hi", PostOptions{Broadcast: true})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if posted[0].broadcast {
+		t.Error("top-level message should not be broadcast")
+	}
+}
+
+func TestPostText_SingleMessageOmitsTimestamps(t *testing.T) {
+	var posted []postedMessage
+
+	result, err := PostText(recordingAPI(t, &posted), "C001", "hi", PostOptions{ThreadTS: "9.0"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Timestamps != nil {
+		t.Errorf("timestamps = %v, want nil", result.Timestamps)
+	}
+}
+
+func TestPostText_ResumesAfterPostedChunks(t *testing.T) {
+	var posted []postedMessage
+
+	result, err := PostText(recordingAPI(t, &posted), "C001", longText(), PostOptions{Posted: []string{"7.0"}})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(posted) != 2 {
+		t.Fatalf("expected 2 posts, got %d", len(posted))
+	}
+	for i, p := range posted {
+		if p.threadTS != "7.0" {
+			t.Errorf("post %d thread_ts = %q, want the first message", i, p.threadTS)
+		}
+	}
+	if result.TS != "7.0" || strings.Join(result.Timestamps, ",") != "7.0,1.0,2.0" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestPostText_PartialFailureReportsPostedTimestamps(t *testing.T) {
+	calls := 0
+	api := &MockSlackAPI{
+		PostMessageFunc: func(channelID string, options ...slackapi.MsgOption) (string, string, error) {
+			calls++
+			if calls == 3 {
+				return "", "", errors.New("rate_limited")
+			}
+			return channelID, fmt.Sprintf("%d.0", calls), nil
+		},
+	}
+
+	result, err := PostText(api, "C001", longText(), PostOptions{ThreadTS: "9.0"})
+
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if result == nil || strings.Join(result.Timestamps, ",") != "1.0,2.0" {
+		t.Errorf("result = %+v, want the two posted timestamps", result)
+	}
+}
@@ -2,107 +2,274 @@ package slack
 
 import (
 	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
 // MaxMessageLength is the maximum character (rune) count for a single Slack message.
 const MaxMessageLength = 4000
 
+// fenceClose is appended to a chunk that ends inside a fenced code block.
+const fenceClose = "\n```"
+
 // SplitMessage splits text into chunks of at most maxLen runes.
 // It splits at paragraph boundaries (\n\n) first, then at line boundaries (\n),
 // and finally by rune count if a single line exceeds maxLen.
+//
+// Fenced code blocks are kept balanced: a chunk that ends inside a fence is
+// closed with ``` and the next chunk reopens it with the same language.
+// Forced splits prefer whitespace and never cut through Slack <...> tokens
+// (links, mentions) or `code spans` when a better break point exists.
 func SplitMessage(text string, maxLen int) []string {
 	if utf8.RuneCountInString(text) <= maxLen {
 		return []string{text}
 	}
 
-	paragraphs := strings.Split(text, "\n\n")
-	var chunks []string
-	var current strings.Builder
-	currentRunes := 0
+	s := &splitter{maxLen: maxLen}
+	for _, para := range splitParagraphs(text) {
+		s.addParagraph(para)
+	}
+	s.flush()
 
-	for _, para := range paragraphs {
-		paraRunes := utf8.RuneCountInString(para)
-		sepRunes := 0
-		if currentRunes > 0 {
-			sepRunes = 2 // "\n\n"
-		}
+	return s.chunks
+}
 
-		if currentRunes+sepRunes+paraRunes <= maxLen {
-			if sepRunes > 0 {
-				current.WriteString("\n\n")
-			}
-			current.WriteString(para)
-			currentRunes += sepRunes + paraRunes
-			continue
-		}
+// splitter accumulates chunks for SplitMessage.
+type splitter struct {
+	maxLen       int
+	chunks       []string
+	current      strings.Builder
+	currentRunes int
+	// fence is the opening line of the fenced code block the current chunk
+	// is inside, or "" outside of code.
+	fence string
+}
+
+func (s *splitter) addParagraph(para string) {
+	paraRunes := utf8.RuneCountInString(para)
+	sepRunes := 0
+	if s.currentRunes > 0 {
+		sepRunes = 2 // "\n\n"
+	}
 
-		// Flush current chunk if non-empty
-		if currentRunes > 0 {
-			chunks = append(chunks, current.String())
-			current.Reset()
-			currentRunes = 0
+	if s.currentRunes+sepRunes+paraRunes <= s.maxLen {
+		if sepRunes > 0 {
+			s.current.WriteString("\n\n")
 		}
+		s.current.WriteString(para)
+		s.currentRunes += sepRunes + paraRunes
+		return
+	}
+
+	// Flush current chunk if non-empty
+	s.flush()
+
+	// If the paragraph itself fits in maxLen, start a new chunk with it
+	if paraRunes <= s.maxLen {
+		s.current.WriteString(para)
+		s.currentRunes = paraRunes
+		return
+	}
+
+	// Paragraph exceeds maxLen: split by lines
+	for _, line := range strings.Split(para, "\n") {
+		s.addLine(line)
+	}
+}
 
-		// If the paragraph itself fits in maxLen, start a new chunk with it
-		if paraRunes <= maxLen {
-			current.WriteString(para)
-			currentRunes = paraRunes
-			continue
+func (s *splitter) addLine(line string) {
+	toggles, opener := fenceMarker(line)
+	fenceAfter := s.fence
+	if toggles {
+		if s.fence == "" {
+			fenceAfter = opener
+		} else {
+			fenceAfter = ""
 		}
+	}
 
-		// Paragraph exceeds maxLen: split by lines
-		lines := strings.Split(para, "\n")
-		for _, line := range lines {
-			lineRunes := utf8.RuneCountInString(line)
-			sepRunes := 0
-			if currentRunes > 0 {
-				sepRunes = 1 // "\n"
-			}
+	// Room for closing the fence must remain if the chunk may end inside it.
+	reserve := 0
+	if fenceAfter != "" {
+		reserve = len(fenceClose)
+	}
+
+	lineRunes := utf8.RuneCountInString(line)
+	if s.currentRunes+s.sep()+lineRunes+reserve > s.maxLen {
+		s.breakChunk()
+	}
+	if s.currentRunes+s.sep()+lineRunes+reserve <= s.maxLen {
+		s.write(line, lineRunes)
+		s.fence = fenceAfter
+		return
+	}
 
-			if currentRunes+sepRunes+lineRunes <= maxLen {
-				if sepRunes > 0 {
-					current.WriteString("\n")
-				}
-				current.WriteString(line)
-				currentRunes += sepRunes + lineRunes
+	// Single line exceeds maxLen: force split by rune count. Chunks broken
+	// within the line are still inside the fence the line started in, even
+	// if the line closes it.
+	if s.fence != "" {
+		reserve = len(fenceClose)
+	}
+	runes := []rune(line)
+	for len(runes) > 0 {
+		avail := s.maxLen - s.currentRunes - s.sep() - reserve
+		if avail <= 0 {
+			if s.currentRunes > 0 && s.currentRunes > utf8.RuneCountInString(s.fence) {
+				s.breakChunk()
 				continue
 			}
-
-			if currentRunes > 0 {
-				chunks = append(chunks, current.String())
-				current.Reset()
-				currentRunes = 0
+			// maxLen is too small for fence markers: split without them.
+			reserve = 0
+			avail = s.maxLen - s.currentRunes - s.sep()
+			if avail <= 0 {
+				avail = 1
+			}
+		}
+		cut := len(runes)
+		if cut > avail {
+			cut = avail
+			if s.fence == "" {
+				cut = splitPoint(runes, avail)
 			}
+		}
+		s.write(string(runes[:cut]), cut)
+		runes = runes[cut:]
+		if len(runes) > 0 {
+			s.breakChunk()
+		}
+	}
+	s.fence = fenceAfter
+}
 
-			if lineRunes <= maxLen {
-				current.WriteString(line)
-				currentRunes = lineRunes
-				continue
+// sep returns the rune count of the separator needed before the next line.
+func (s *splitter) sep() int {
+	if s.currentRunes > 0 {
+		return 1 // "\n"
+	}
+	return 0
+}
+
+func (s *splitter) write(str string, runes int) {
+	if s.currentRunes > 0 {
+		s.current.WriteString("\n")
+		s.currentRunes++
+	}
+	s.current.WriteString(str)
+	s.currentRunes += runes
+}
+
+// breakChunk ends the current chunk mid-paragraph, closing an open fence
+// and reopening it at the start of the next chunk.
+func (s *splitter) breakChunk() {
+	if s.currentRunes == 0 {
+		return
+	}
+	if s.fence != "" {
+		s.current.WriteString(fenceClose)
+	}
+	s.chunks = append(s.chunks, s.current.String())
+	s.current.Reset()
+	s.currentRunes = 0
+	if s.fence != "" {
+		s.current.WriteString(s.fence)
+		s.currentRunes = utf8.RuneCountInString(s.fence)
+	}
+}
+
+func (s *splitter) flush() {
+	if s.currentRunes > 0 {
+		s.chunks = append(s.chunks, s.current.String())
+	}
+	s.current.Reset()
+	s.currentRunes = 0
+	s.fence = ""
+}
+
+// splitParagraphs splits text at blank lines, keeping fenced code blocks
+// that contain blank lines in a single paragraph.
+func splitParagraphs(text string) []string {
+	var paras []string
+	open := false
+	for _, para := range strings.Split(text, "\n\n") {
+		if open {
+			paras[len(paras)-1] += "\n\n" + para
+		} else {
+			paras = append(paras, para)
+		}
+		for _, line := range strings.Split(para, "\n") {
+			if toggles, _ := fenceMarker(line); toggles {
+				open = !open
 			}
+		}
+	}
+	return paras
+}
+
+// fenceMarker reports whether line opens or closes a ``` fence and, for an
+// opening line, the marker (with language) used to reopen it.
+func fenceMarker(line string) (toggles bool, opener string) {
+	count := strings.Count(line, "```")
+	if count%2 == 0 {
+		return false, ""
+	}
+	trimmed := strings.TrimSpace(line)
+	lang := strings.TrimPrefix(trimmed, "```")
+	if count == 1 && lang != trimmed && !strings.ContainsAny(lang, " \t`") {
+		return true, trimmed
+	}
+	return true, "```"
+}
 
-			// Single line exceeds maxLen: force split by rune count
-			splitByRunes(line, maxLen, &chunks)
+// splitPoint returns where to cut runes so the first part has at most
+// maxLen runes. It prefers the last whitespace in the second half of the
+// window and moves the cut before a <...> token or `code span` that would
+// otherwise be cut in two.
+func splitPoint(runes []rune, maxLen int) int {
+	cut := maxLen
+	for i := cut - 1; i >= cut/2; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i + 1
+			break
 		}
 	}
 
-	if currentRunes > 0 {
-		chunks = append(chunks, current.String())
+	// Inside <...>: the last '<' before the cut is not yet closed.
+	for i := cut - 1; i > 0; i-- {
+		if runes[i] == '>' {
+			break
+		}
+		if runes[i] == '<' {
+			if closes(runes[cut:], '>') {
+				cut = i
+			}
+			break
+		}
 	}
 
-	return chunks
+	// Inside `code`: an odd number of backticks precede the cut.
+	last, count := -1, 0
+	for i := 0; i < cut; i++ {
+		if runes[i] == '`' {
+			last = i
+			count++
+		}
+	}
+	if count%2 == 1 && last > 0 && closes(runes[cut:], '`') {
+		cut = last
+	}
+
+	return cut
 }
 
-// splitByRunes splits a string into chunks of at most maxLen runes,
-// ensuring multi-byte characters are never cut in the middle.
-func splitByRunes(s string, maxLen int, chunks *[]string) {
-	runes := []rune(s)
-	for len(runes) > 0 {
-		end := maxLen
-		if end > len(runes) {
-			end = len(runes)
+// closes reports whether r is found in rest before the next newline.
+func closes(rest []rune, r rune) bool {
+	for _, c := range rest {
+		if c == r {
+			return true
+		}
+		if c == '\n' {
+			return false
 		}
-		*chunks = append(*chunks, string(runes[:end]))
-		runes = runes[end:]
 	}
+	return false
 }
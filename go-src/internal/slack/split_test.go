@@ -324,3 +324,228 @@ func TestSplitMessage_MaxLenPlusOne(t *testing.T) {
 		t.Errorf("chunk[1] = %q, want %q", chunks[1], "b")
 	}
 }
+
+// --- Fenced code and inline formatting ---
+
+// fenceCount returns the number of ``` markers in s.
+func fenceCount(s string) int {
+	return strings.Count(s, "```")
+}
+
+func TestSplitMessage_FencedCodeReopenedWithLanguage(t *testing.T) {
+	var code strings.Builder
+	for i := 0; i < 200; i++ {
+		code.WriteString("fmt.Println(\"line\")\n")
+	}
+	text := "intro\n\n```go\n" + code.String() + "```\n\noutro"
+
+	chunks := SplitMessage(text, 1000)
+
+	if len(chunks) < 3 {
+		t.Fatalf("expected at least 3 chunks, got %d", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if n := utf8.RuneCountInString(chunk); n > 1000 {
+			t.Errorf("chunk[%d] runes = %d, exceeds 1000", i, n)
+		}
+		if fenceCount(chunk)%2 != 0 {
+			t.Errorf("chunk[%d] has unbalanced fences:\n%s", i, chunk)
+		}
+	}
+	for i, chunk := range chunks[1 : len(chunks)-1] {
+		if !strings.HasPrefix(chunk, "```go\n") {
+			t.Errorf("chunk[%d] does not reopen the fence: %q", i+1, chunk[:20])
+		}
+		if !strings.HasSuffix(chunk, "\n```") {
+			t.Errorf("chunk[%d] does not close the fence", i+1)
+		}
+	}
+	if !strings.HasSuffix(chunks[len(chunks)-1], "outro") {
+		t.Errorf("last chunk = %q", chunks[len(chunks)-1])
+	}
+}
+
+func TestSplitMessage_FencedCodePreservesLines(t *testing.T) {
+	var lines []string
+	for i := 0; i < 100; i++ {
+		lines = append(lines, strings.Repeat(string(rune('a'+i%26)), 30))
+	}
+	text := "```\n" + strings.Join(lines, "\n") + "\n```"
+
+	chunks := SplitMessage(text, 500)
+
+	var got []string
+	for _, chunk := range chunks {
+		chunk = strings.TrimSuffix(strings.TrimPrefix(chunk, "```\n"), "\n```")
+		got = append(got, strings.Split(chunk, "\n")...)
+	}
+	if strings.Join(got, "\n") != strings.Join(lines, "\n") {
+		t.Error("code lines were not preserved across chunks")
+	}
+}
+
+func TestSplitMessage_FenceWithBlankLinesStaysTogether(t *testing.T) {
+	code := "```\nfirst\n\nsecond\n```"
+	text := strings.Repeat("a", 3000) + "\n\n" + code
+
+	chunks := SplitMessage(text, MaxMessageLength)
+
+	if len(chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(chunks))
+	}
+
+	chunks = SplitMessage(strings.Repeat("a", 3990)+"\n\n"+code, MaxMessageLength)
+
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(chunks))
+	}
+	if chunks[1] != code {
+		t.Errorf("chunk[1] = %q, want the whole code block", chunks[1])
+	}
+}
+
+func TestSplitMessage_LongLineInsideFence(t *testing.T) {
+	text := "```\n" + strings.Repeat("x", 250) + "\n```"
+
+	chunks := SplitMessage(text, 100)
+
+	for i, chunk := range chunks {
+		if n := utf8.RuneCountInString(chunk); n > 100 {
+			t.Errorf("chunk[%d] runes = %d, exceeds 100", i, n)
+		}
+		if !strings.HasPrefix(chunk, "```\n") || !strings.HasSuffix(chunk, "\n```") {
+			t.Errorf("chunk[%d] is not a complete code block: %q", i, chunk)
+		}
+	}
+	if total := strings.Count(strings.Join(chunks, ""), "x"); total != 250 {
+		t.Errorf("x count = %d, want 250", total)
+	}
+}
+
+func TestSplitMessage_LongFenceClosingLine(t *testing.T) {
+	text := "```\n" + strings.Repeat("x", 250) + "```\nafter"
+
+	chunks := SplitMessage(text, 100)
+
+	for i, chunk := range chunks {
+		if n := utf8.RuneCountInString(chunk); n > 100 {
+			t.Errorf("chunk[%d] runes = %d, exceeds 100", i, n)
+		}
+		if fenceCount(chunk)%2 != 0 {
+			t.Errorf("chunk[%d] has unbalanced fences: %q", i, chunk)
+		}
+	}
+	if total := strings.Count(strings.Join(chunks, ""), "x"); total != 250 {
+		t.Errorf("x count = %d, want 250", total)
+	}
+}
+
+func TestSplitMessage_UnclosedFence(t *testing.T) {
+	text := "```\n" + strings.Repeat("line\n", 100)
+
+	chunks := SplitMessage(text, 100)
+
+	for i, chunk := range chunks[:len(chunks)-1] {
+		if fenceCount(chunk)%2 != 0 {
+			t.Errorf("chunk[%d] has unbalanced fences", i)
+		}
+	}
+}
+
+func TestSplitMessage_InlineTripleBackticks(t *testing.T) {
+	line := "run ```make build``` first"
+	text := strings.Repeat(line+"\n", 200)
+
+	chunks := SplitMessage(text, 500)
+
+	for i, chunk := range chunks {
+		if strings.HasPrefix(chunk, "```") {
+			t.Errorf("chunk[%d] unexpectedly reopens a fence", i)
+		}
+		if fenceCount(chunk)%2 != 0 {
+			t.Errorf("chunk[%d] has unbalanced fences", i)
+		}
+	}
+}
+
+func TestSplitMessage_DoesNotSplitTokens(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "Mention", token: "<@U12345678>"},
+		{name: "Channel", token: "<#C12345678|general>"},
+		{name: "LinkWithSpaces", token: "<https://example.com/a/b|the release notes>"},
+		{name: "CodeSpan", token: "`go test ./...`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := strings.Repeat("a", 95) + tt.token + strings.Repeat("b", 50)
+
+			chunks := SplitMessage(text, 100)
+
+			if len(chunks) != 2 {
+				t.Fatalf("expected 2 chunks, got %d", len(chunks))
+			}
+			if chunks[0] != strings.Repeat("a", 95) {
+				t.Errorf("chunk[0] = %q", chunks[0])
+			}
+			if !strings.HasPrefix(chunks[1], tt.token) {
+				t.Errorf("chunk[1] = %q, want prefix %q", chunks[1], tt.token)
+			}
+		})
+	}
+}
+
+func TestSplitMessage_PrefersWhitespace(t *testing.T) {
+	text := strings.Repeat("word ", 30)
+
+	chunks := SplitMessage(text, 52)
+
+	for i, chunk := range chunks[:len(chunks)-1] {
+		if !strings.HasSuffix(chunk, " ") {
+			t.Errorf("chunk[%d] = %q, want split after a space", i, chunk)
+		}
+	}
+	if strings.Join(chunks, "") != text {
+		t.Error("content was not preserved")
+	}
+}
+
+func TestSplitMessage_UnclosedTokenStillSplits(t *testing.T) {
+	text := strings.Repeat("a", 50) + "<" + strings.Repeat("b", 200)
+
+	chunks := SplitMessage(text, 100)
+
+	for i, chunk := range chunks {
+		if n := utf8.RuneCountInString(chunk); n > 100 {
+			t.Errorf("chunk[%d] runes = %d, exceeds 100", i, n)
+		}
+	}
+	if strings.Join(chunks, "") != text {
+		t.Error("content was not preserved")
+	}
+}
+
+func TestSplitMessage_FenceInvariants(t *testing.T) {
+	text := "# Report\n\n" +
+		"```python\n" + strings.Repeat("print('hello world')\n", 40) + "```\n\n" +
+		strings.Repeat("Some prose with <@U123> and `code` here.\n", 20) + "\n" +
+		"```\n" + strings.Repeat("日本語のコード行\n", 30) + "```"
+
+	for maxLen := 40; maxLen <= 400; maxLen += 7 {
+		chunks := SplitMessage(text, maxLen)
+		for i, chunk := range chunks {
+			if n := utf8.RuneCountInString(chunk); n > maxLen {
+				t.Errorf("maxLen %d: chunk[%d] runes = %d", maxLen, i, n)
+			}
+			if fenceCount(chunk)%2 != 0 {
+				t.Errorf("maxLen %d: chunk[%d] has unbalanced fences", maxLen, i)
+			}
+			if !utf8.ValidString(chunk) {
+				t.Errorf("maxLen %d: chunk[%d] contains invalid UTF-8", maxLen, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package slack
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	slackapi "github.com/slack-go/slack"
+)
+
+var (
+	reUserID    = regexp.MustCompile(`^[UW][A-Z0-9]{2,}$`)
+	reChannelID = regexp.MustCompile(`^[CGD][A-Z0-9]{2,}$`)
+)
+
+// RenderTemplate executes the Go text/template src with vars as its data
+// and returns the resulting message text. Besides the standard functions,
+// templates can use:
+//
+//	now                  the current time
+//	date LAYOUT VALUE    format a time, RFC 3339 or YYYY-MM-DD string, Slack
+//	                     timestamp or Unix seconds with a Go time layout
+//	mention USER         a mention for a user ID or email address
+//	channel CHANNEL      a link to a channel ID or #name
+//
+// Referencing a variable missing from vars is an error.
+func RenderTemplate(api SlackAPI, name, src string, vars map[string]any) (string, error) {
+	r := &templateResolver{api: api}
+	tmpl, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{
+			"now":     time.Now,
+			"date":    formatDate,
+			"mention": r.mention,
+			"channel": r.channel,
+		}).
+		Parse(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// templateResolver looks up users and channels for template functions,
+// listing channels at most once per template.
+type templateResolver struct {
+	api      SlackAPI
+	channels map[string]string
+}
+
+// mention returns "<@ID>" for a user ID or email address.
+func (r *templateResolver) mention(user string) (string, error) {
+	user = strings.TrimPrefix(strings.TrimSpace(user), "@")
+	if reUserID.MatchString(user) {
+		return "<@" + user + ">", nil
+	}
+	if !strings.Contains(user, "@") {
+		return "", fmt.Errorf("mention: %q is not a user ID or email address", user)
+	}
+	u, err := r.api.GetUserByEmail(user)
+	if err != nil {
+		return "", fmt.Errorf("mention: failed to look up %s: %w", user, err)
+	}
+	return "<@" + u.ID + ">", nil
+}
+
+// channel returns "<#ID>" for a channel ID or name.
+func (r *templateResolver) channel(channel string) (string, error) {
+	channel = strings.TrimSpace(channel)
+	if reChannelID.MatchString(channel) {
+		return "<#" + channel + ">", nil
+	}
+	name := strings.TrimPrefix(channel, "#")
+
+	if r.channels == nil {
+		r.channels = map[string]string{}
+		params := &slackapi.GetConversationsParameters{
+			Types:           []string{"public_channel", "private_channel"},
+			Limit:           1000,
+			ExcludeArchived: true,
+		}
+		for {
+			channels, cursor, err := r.api.GetConversations(params)
+			if err != nil {
+				r.channels = nil
+				return "", fmt.Errorf("channel: failed to list channels: %w", err)
+			}
+			for _, ch := range channels {
+				r.channels[ch.Name] = ch.ID
+			}
+			if cursor == "" {
+				break
+			}
+			params.Cursor = cursor
+		}
+	}
+
+	id, ok := r.channels[name]
+	if !ok {
+		return "", fmt.Errorf("channel: no channel named #%s", name)
+	}
+	return "<#" + id + ">", nil
+}
+
+// formatDate formats v with layout. v may be a time.Time, an RFC 3339 or
+// YYYY-MM-DD string, a Slack timestamp or Unix seconds.
+func formatDate(layout string, v any) (string, error) {
+	t, err := toTime(v)
+	if err != nil {
+		return "", fmt.Errorf("date: %w", err)
+	}
+	return t.Format(layout), nil
+}
+
+func toTime(v any) (time.Time, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, nil
+	case int:
+		return time.Unix(int64(v), 0), nil
+	case int64:
+		return time.Unix(v, 0), nil
+	case float64:
+		return unixFloat(v), nil
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t, nil
+		}
+		if t, err := time.ParseInLocation(time.DateOnly, v, time.Local); err == nil {
+			return t, nil
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return unixFloat(f), nil
+		}
+		return time.Time{}, fmt.Errorf("cannot parse %q as a time", v)
+	default:
+		return time.Time{}, fmt.Errorf("cannot use %T as a time", v)
+	}
+}
+
+func unixFloat(f float64) time.Time {
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*1e9))
+}
@@ -0,0 +1,100 @@
+package slack
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	slackapi "github.com/slack-go/slack"
+)
+
+func templateAPI(listCalls *int) *MockSlackAPI {
+	return &MockSlackAPI{
+		GetUserByEmailFunc: func(email string) (*slackapi.User, error) {
+			if email == "alice@example.com" {
+				return &slackapi.User{ID: "U0ALICE"}, nil
+			}
+			return nil, errors.New("users_not_found")
+		},
+		GetConversationsFunc: func(params *slackapi.GetConversationsParameters) ([]slackapi.Channel, string, error) {
+			*listCalls++
+			if params.Cursor == "" {
+				return []slackapi.Channel{{GroupConversation: slackapi.GroupConversation{Name: "general", Conversation: slackapi.Conversation{ID: "C0GENERAL"}}}}, "next", nil
+			}
+			return []slackapi.Channel{{GroupConversation: slackapi.GroupConversation{Name: "deploys", Conversation: slackapi.Conversation{ID: "C0DEPLOYS"}}}}, "", nil
+		},
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		vars map[string]any
+		want string
+	}{
+		{"Vars", "Release {{.version}} is out", map[string]any{"version": "v1.2"}, "Release v1.2 is out"},
+		{"MentionByEmail", "cc {{mention \"alice@example.com\"}}", nil, "cc <@U0ALICE>"},
+		{"MentionByID", "cc {{mention .owner}}", map[string]any{"owner": "U0BOB"}, "cc <@U0BOB>"},
+		{"ChannelByID", "see {{channel \"C0123\"}}", nil, "see <#C0123>"},
+		{"ChannelByName", "see {{channel \"#deploys\"}} and {{channel \"general\"}}", nil, "see <#C0DEPLOYS> and <#C0GENERAL>"},
+		{"DateFromString", "{{date \"Jan 2\" .day}}", map[string]any{"day": "2026-03-04"}, "Mar 4"},
+		{"DateFromRFC3339", "{{date \"15:04\" .at}}", map[string]any{"at": "2026-03-04T05:06:07Z"}, "05:06"},
+		{"DateFromTime", "{{date \"2006\" .at}}", map[string]any{"at": time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}, "2030"},
+		{"Range", "{{range .items}}- {{.}}\n{{end}}", map[string]any{"items": []any{"a", "b"}}, "- a\n- b\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var listCalls int
+			got, err := RenderTemplate(templateAPI(&listCalls), "test", tt.src, tt.vars)
+
+			if err != nil {
+				t.Fatalf("RenderTemplate: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if listCalls > 2 {
+				t.Errorf("channels listed %d times", listCalls)
+			}
+		})
+	}
+}
+
+func TestRenderTemplate_Now(t *testing.T) {
+	got, err := RenderTemplate(&MockSlackAPI{}, "test", `{{date "2006" now}}`, nil)
+
+	if err != nil {
+		t.Fatalf("RenderTemplate: %v", err)
+	}
+	if got != time.Now().Format("2006") {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestRenderTemplate_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		vars    map[string]any
+		wantErr string
+	}{
+		{"MissingVar", "{{.missing}}", map[string]any{}, "missing"},
+		{"ParseError", "{{.x", nil, "failed to parse template"},
+		{"UnknownEmail", `{{mention "bob@example.com"}}`, nil, "failed to look up bob@example.com"},
+		{"NotAUser", `{{mention "bob"}}`, nil, "not a user ID or email address"},
+		{"UnknownChannel", `{{channel "#random"}}`, nil, "no channel named #random"},
+		{"BadDate", `{{date "2006" "tomorrow"}}`, nil, `cannot parse "tomorrow"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var listCalls int
+			_, err := RenderTemplate(templateAPI(&listCalls), "test", tt.src, tt.vars)
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
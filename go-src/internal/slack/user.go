@@ -0,0 +1,133 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+
+	slackapi "github.com/slack-go/slack"
+)
+
+// User is the shared view model for a workspace member in listings.
+type User struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	RealName    string `json:"real_name"`
+	DisplayName string `json:"display_name"`
+	Email       string `json:"email,omitempty"`
+	IsBot       bool   `json:"is_bot"`
+	Deleted     bool   `json:"deleted"`
+}
+
+// UserProfile is the shared view model for a single user's profile.
+type UserProfile struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	RealName    string `json:"real_name"`
+	DisplayName string `json:"display_name"`
+	Email       string `json:"email"`
+	Title       string `json:"title"`
+	Phone       string `json:"phone"`
+	StatusText  string `json:"status_text"`
+	StatusEmoji string `json:"status_emoji"`
+	TZ          string `json:"tz"`
+	IsAdmin     bool   `json:"is_admin"`
+	IsBot       bool   `json:"is_bot"`
+	Deleted     bool   `json:"deleted"`
+}
+
+// NewUser converts a Slack API user into the listing view model.
+func NewUser(u slackapi.User) User {
+	return User{
+		ID:          u.ID,
+		Name:        u.Name,
+		RealName:    u.RealName,
+		DisplayName: u.Profile.DisplayName,
+		Email:       u.Profile.Email,
+		IsBot:       u.IsBot,
+		Deleted:     u.Deleted,
+	}
+}
+
+// NewUserProfile converts a Slack API user into the profile view model.
+func NewUserProfile(u *slackapi.User) UserProfile {
+	return UserProfile{
+		ID:          u.ID,
+		Name:        u.Name,
+		RealName:    u.RealName,
+		DisplayName: u.Profile.DisplayName,
+		Email:       u.Profile.Email,
+		Title:       u.Profile.Title,
+		Phone:       u.Profile.Phone,
+		StatusText:  u.Profile.StatusText,
+		StatusEmoji: u.Profile.StatusEmoji,
+		TZ:          u.TZ,
+		IsAdmin:     u.IsAdmin,
+		IsBot:       u.IsBot,
+		Deleted:     u.Deleted,
+	}
+}
+
+// DisplayNameOrReal returns the display name, falling back to the real name.
+func (u User) DisplayNameOrReal() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return u.RealName
+}
+
+// UserResolver turns user references into user IDs, listing workspace users
+// at most once.
+type UserResolver struct {
+	api   SlackAPI
+	users []slackapi.User
+}
+
+// NewUserResolver returns a resolver that looks users up through api.
+func NewUserResolver(api SlackAPI) *UserResolver {
+	return &UserResolver{api: api}
+}
+
+// Resolve returns the ID of the user ref refers to: a user ID, an email
+// address, or a user name or display name, optionally prefixed with "@" or
+// written as a <@ID> mention.
+func (r *UserResolver) Resolve(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if strings.HasPrefix(ref, "<@") && strings.HasSuffix(ref, ">") {
+		ref, _, _ = strings.Cut(ref[2:len(ref)-1], "|")
+	}
+	ref = strings.TrimPrefix(ref, "@")
+	if reUserID.MatchString(ref) {
+		return ref, nil
+	}
+	if strings.Contains(ref, "@") {
+		u, err := r.api.GetUserByEmail(ref)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up %s: %w", ref, err)
+		}
+		return u.ID, nil
+	}
+
+	if r.users == nil {
+		users, err := r.api.GetUsers()
+		if err != nil {
+			return "", fmt.Errorf("failed to list users: %w", err)
+		}
+		r.users = users
+	}
+	var matches []string
+	for _, u := range r.users {
+		if u.Deleted {
+			continue
+		}
+		if strings.EqualFold(u.Name, ref) || strings.EqualFold(u.Profile.DisplayName, ref) {
+			matches = append(matches, u.ID)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no user named @%s", ref)
+	case 1:
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("@%s matches several users (%s); use a user ID", ref, strings.Join(matches, ", "))
+}
@@ -0,0 +1,59 @@
+package slack
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	slackapi "github.com/slack-go/slack"
+)
+
+func TestUserResolver_Resolve(t *testing.T) {
+	listed := 0
+	api := &MockSlackAPI{
+		GetUserByEmailFunc: func(email string) (*slackapi.User, error) {
+			if email == "alice@example.com" {
+				return &slackapi.User{ID: "U0ALICE"}, nil
+			}
+			return nil, errors.New("users_not_found")
+		},
+		GetUsersFunc: func(options ...slackapi.GetUsersOption) ([]slackapi.User, error) {
+			listed++
+			return []slackapi.User{
+				{ID: "U0BOB", Name: "bob", Profile: slackapi.UserProfile{DisplayName: "Bobby"}},
+				{ID: "U0OLD", Name: "carol", Deleted: true},
+				{ID: "U0SAM1", Name: "sam"},
+				{ID: "U0SAM2", Name: "sam.k", Profile: slackapi.UserProfile{DisplayName: "sam"}},
+			}, nil
+		},
+	}
+	r := NewUserResolver(api)
+
+	tests := []struct {
+		ref, want, err string
+	}{
+		{ref: "U0BOB", want: "U0BOB"},
+		{ref: "<@U0BOB|bob>", want: "U0BOB"},
+		{ref: "alice@example.com", want: "U0ALICE"},
+		{ref: "@bob", want: "U0BOB"},
+		{ref: "bobby", want: "U0BOB"},
+		{ref: "carol", err: "no user named @carol"},
+		{ref: "sam", err: "matches several users"},
+		{ref: "dave@example.com", err: "failed to look up dave@example.com"},
+	}
+	for _, tt := range tests {
+		got, err := r.Resolve(tt.ref)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Resolve(%q) err = %v, want %q", tt.ref, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Resolve(%q) = %q, %v, want %q", tt.ref, got, err, tt.want)
+		}
+	}
+	if listed != 1 {
+		t.Errorf("users listed %d times, want 1", listed)
+	}
+}
@@ -0,0 +1,242 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	auditFile     = "audit.jsonl"
+	auditLockFile = "audit.lock"
+)
+
+// auditLockWait is how long Append waits for another process to finish
+// writing.
+const auditLockWait = 5 * time.Second
+
+// Audit actions.
+const (
+	AuditPost        = "post"
+	AuditReply       = "reply"
+	AuditReactionAdd = "reaction_add"
+	// AuditUndo records that the entry numbered Undoes was reverted.
+	AuditUndo = "undo"
+
+	AuditChannelCreate    = "channel_create"
+	AuditChannelArchive   = "channel_archive"
+	AuditChannelUnarchive = "channel_unarchive"
+	AuditChannelRename    = "channel_rename"
+	AuditChannelTopic     = "channel_topic"
+	AuditChannelPurpose   = "channel_purpose"
+	AuditChannelInvite    = "channel_invite"
+	AuditChannelKick      = "channel_kick"
+	AuditChannelJoin      = "channel_join"
+	AuditChannelLeave     = "channel_leave"
+	// AuditChannelMark records a move of the read marker to TS.
+	AuditChannelMark = "channel_mark"
+)
+
+// Audit origins.
+const (
+	OriginCLI = "cli"
+	OriginMCP = "mcp"
+)
+
+// AuditEntry records one write slamy performed in Slack. Entries form a
+// hash chain: Hash covers the entry and PrevHash, so editing, removing or
+// reordering earlier entries is detected by AuditLog.Verify.
+type AuditEntry struct {
+	Seq    int       `json:"seq"`
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	// Origin is OriginCLI or OriginMCP; Client names the MCP client.
+	Origin     string   `json:"origin"`
+	Client     string   `json:"client,omitempty"`
+	Channel    string   `json:"channel"`
+	TS         string   `json:"ts,omitempty"`
+	ThreadTS   string   `json:"thread_ts,omitempty"`
+	Timestamps []string `json:"timestamps,omitempty"`
+	FileID     string   `json:"file_id,omitempty"`
+	Reaction   string   `json:"reaction,omitempty"`
+	Undoes     int      `json:"undoes,omitempty"`
+	// Name is the new name of a created or renamed channel; Users lists
+	// the users invited or removed.
+	Name  string   `json:"name,omitempty"`
+	Users []string `json:"users,omitempty"`
+	// TextSHA256 is the hash of the text as given to slamy; Text holds the
+	// text itself when full-text auditing is enabled.
+	TextSHA256 string `json:"text_sha256,omitempty"`
+	Text       string `json:"text,omitempty"`
+	PrevHash   string `json:"prev_hash,omitempty"`
+	Hash       string `json:"hash"`
+}
+
+// SetText records the hash of text, and text itself when full is set.
+func (e *AuditEntry) SetText(text string, full bool) {
+	sum := sha256.Sum256([]byte(text))
+	e.TextSHA256 = hex.EncodeToString(sum[:])
+	if full {
+		e.Text = text
+	}
+}
+
+// computeHash returns the chained hash of e, ignoring e.Hash.
+func (e AuditEntry) computeHash() (string, error) {
+	e.Hash = ""
+	b, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// AuditLog is an append-only JSONL log of slamy's writes.
+type AuditLog struct {
+	path     string
+	lockPath string
+	now      func() time.Time
+}
+
+// OpenAuditLog opens the audit log in the data directory.
+func OpenAuditLog() (*AuditLog, error) {
+	path, err := Path(auditFile)
+	if err != nil {
+		return nil, err
+	}
+	lockPath, err := Path(auditLockFile)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLog{path: path, lockPath: lockPath, now: time.Now}, nil
+}
+
+// Append assigns e its sequence number, time and hashes and adds it to the
+// log.
+func (l *AuditLog) Append(e AuditEntry) (AuditEntry, error) {
+	unlock, err := l.lock()
+	if err != nil {
+		return e, err
+	}
+	defer unlock()
+
+	entries, err := l.Entries()
+	if err != nil {
+		return e, err
+	}
+	e.Seq = 1
+	e.PrevHash = ""
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		e.Seq = last.Seq + 1
+		e.PrevHash = last.Hash
+	}
+	e.Time = l.now().UTC()
+	if e.Hash, err = e.computeHash(); err != nil {
+		return e, fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return e, fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return e, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return e, fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return e, fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return e, nil
+}
+
+// Entries returns every entry in the log, oldest first.
+func (l *AuditLog) Entries() ([]AuditEntry, error) {
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// Get returns the entry with sequence number seq.
+func (l *AuditLog) Get(seq int) (*AuditEntry, error) {
+	entries, err := l.Entries()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.Seq == seq {
+			return &e, nil
+		}
+	}
+	return nil, fmt.Errorf("audit entry %d not found", seq)
+}
+
+// Verify checks the hash chain and returns the number of entries checked.
+// The error names the first entry that was altered, removed or reordered.
+func (l *AuditLog) Verify() (int, error) {
+	entries, err := l.Entries()
+	if err != nil {
+		return 0, err
+	}
+	prev := ""
+	for i, e := range entries {
+		if e.Seq != i+1 {
+			return i, fmt.Errorf("audit entry %d: expected sequence number %d", e.Seq, i+1)
+		}
+		if e.PrevHash != prev {
+			return i, fmt.Errorf("audit entry %d: chain broken (previous entry missing or altered)", e.Seq)
+		}
+		hash, err := e.computeHash()
+		if err != nil {
+			return i, fmt.Errorf("audit entry %d: %w", e.Seq, err)
+		}
+		if hash != e.Hash {
+			return i, fmt.Errorf("audit entry %d: hash mismatch (entry altered)", e.Seq)
+		}
+		prev = e.Hash
+	}
+	return len(entries), nil
+}
+
+// lock takes the audit lock, waiting up to auditLockWait for another writer.
+func (l *AuditLog) lock() (func(), error) {
+	unlock, err := waitLock(l.lockPath, auditLockWait, l.now)
+	if errors.Is(err, errLocked) {
+		return nil, fmt.Errorf("audit log is locked by another process")
+	}
+	return unlock, err
+}
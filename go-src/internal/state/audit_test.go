@@ -0,0 +1,131 @@
+package state
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func openTestAuditLog(t *testing.T) *AuditLog {
+	t.Helper()
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	l, err := OpenAuditLog()
+	if err != nil {
+		t.Fatalf("OpenAuditLog: %v", err)
+	}
+	return l
+}
+
+func appendEntries(t *testing.T, l *AuditLog, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		e := AuditEntry{Action: AuditPost, Origin: OriginCLI, Channel: "C001", TS: "1.0"}
+		e.SetText("hello", false)
+		if _, err := l.Append(e); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+}
+
+func TestAuditLog_AppendChainsEntries(t *testing.T) {
+	l := openTestAuditLog(t)
+	appendEntries(t, l, 3)
+
+	entries, err := l.Entries()
+
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	for i, e := range entries {
+		if e.Seq != i+1 || e.Hash == "" || e.Time.IsZero() {
+			t.Errorf("entry %d = %+v", i, e)
+		}
+		if i > 0 && e.PrevHash != entries[i-1].Hash {
+			t.Errorf("entry %d does not chain to the previous entry", i)
+		}
+	}
+	if n, err := l.Verify(); n != 3 || err != nil {
+		t.Errorf("Verify = %d, %v", n, err)
+	}
+}
+
+func TestAuditEntry_SetText(t *testing.T) {
+	var hashed, full AuditEntry
+	hashed.SetText("secret", false)
+	full.SetText("secret", true)
+
+	if hashed.Text != "" || len(hashed.TextSHA256) != 64 {
+		t.Errorf("hashed = %+v", hashed)
+	}
+	if full.Text != "secret" || full.TextSHA256 != hashed.TextSHA256 {
+		t.Errorf("full = %+v", full)
+	}
+}
+
+func TestAuditLog_VerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func(lines []string) []string
+		wantErr string
+	}{
+		{"Edited", func(lines []string) []string {
+			lines[1] = strings.Replace(lines[1], `"C001"`, `"C999"`, 1)
+			return lines
+		}, "audit entry 2: hash mismatch"},
+		{"Removed", func(lines []string) []string {
+			return append(lines[:1:1], lines[2:]...)
+		}, "audit entry 3: expected sequence number 2"},
+		{"Reordered", func(lines []string) []string {
+			lines[0], lines[1] = lines[1], lines[0]
+			return lines
+		}, "audit entry 2: expected sequence number 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := openTestAuditLog(t)
+			appendEntries(t, l, 3)
+			b, err := os.ReadFile(l.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := tt.tamper(strings.Split(strings.TrimSpace(string(b)), "\n"))
+			if err := os.WriteFile(l.path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = l.Verify()
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuditLog_Get(t *testing.T) {
+	l := openTestAuditLog(t)
+	appendEntries(t, l, 2)
+
+	e, err := l.Get(2)
+	if err != nil || e.Seq != 2 {
+		t.Errorf("Get(2) = %+v, %v", e, err)
+	}
+	if _, err := l.Get(5); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Get(5) err = %v", err)
+	}
+}
+
+func TestAuditLog_EmptyLog(t *testing.T) {
+	l := openTestAuditLog(t)
+
+	entries, err := l.Entries()
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Entries = %v, %v", entries, err)
+	}
+	if n, err := l.Verify(); n != 0 || err != nil {
+		t.Errorf("Verify = %d, %v", n, err)
+	}
+}
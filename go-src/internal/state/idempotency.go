@@ -0,0 +1,174 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// IdempotencyTTL is how long an idempotency key is remembered.
+const IdempotencyTTL = 24 * time.Hour
+
+const (
+	idempotencyFile     = "idempotency.json"
+	idempotencyLockFile = "idempotency.lock"
+)
+
+// idempotencyLockWait is how long the store waits for another process to
+// finish writing.
+const idempotencyLockWait = 5 * time.Second
+
+// idempotencyPendingTTL is how long a reserved key stays pending before it
+// is treated as abandoned by a process that died while posting.
+const idempotencyPendingTTL = 10 * time.Minute
+
+// ErrIdempotencyPending is returned by Reserve when another request holds
+// the key and has not finished posting yet.
+var ErrIdempotencyPending = errors.New("idempotency key is being posted by another request")
+
+// IdempotencyRecord is what was posted for an idempotency key.
+type IdempotencyRecord struct {
+	Channel    string   `json:"channel"`
+	TS         string   `json:"ts"`
+	ThreadTS   string   `json:"thread_ts,omitempty"`
+	Timestamps []string `json:"timestamps,omitempty"`
+	// Pending is set while the message for the key is being posted.
+	Pending   bool      `json:"pending,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IdempotencyStore maps client-supplied idempotency keys to the messages
+// posted for them, so retried posts return the original message.
+type IdempotencyStore struct {
+	path     string
+	lockPath string
+	now      func() time.Time
+}
+
+// OpenIdempotencyStore opens the store in the data directory.
+func OpenIdempotencyStore() (*IdempotencyStore, error) {
+	path, err := Path(idempotencyFile)
+	if err != nil {
+		return nil, err
+	}
+	lockPath, err := Path(idempotencyLockFile)
+	if err != nil {
+		return nil, err
+	}
+	return &IdempotencyStore{path: path, lockPath: lockPath, now: time.Now}, nil
+}
+
+// Get returns the record for key, or nil if the key is unknown or expired.
+// Using a key for a different channel than it was recorded for is an error.
+func (s *IdempotencyStore) Get(key, channel string) (*IdempotencyRecord, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	records, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	rec, ok := records[key]
+	if !ok {
+		return nil, nil
+	}
+	if rec.Channel != channel {
+		return nil, fmt.Errorf("idempotency key %q was already used for channel %s", key, rec.Channel)
+	}
+	return &rec, nil
+}
+
+// Reserve claims key for channel before posting. It returns the record if
+// the key was already posted, or nil once the key is reserved; the caller
+// then calls Put with what it posted, or Release if posting failed. A key
+// that another request reserved and has not finished with returns
+// ErrIdempotencyPending.
+func (s *IdempotencyStore) Reserve(key, channel string) (*IdempotencyRecord, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	records, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	if rec, ok := records[key]; ok {
+		if rec.Channel != channel {
+			return nil, fmt.Errorf("idempotency key %q was already used for channel %s", key, rec.Channel)
+		}
+		if !rec.Pending {
+			return &rec, nil
+		}
+		if s.now().Sub(rec.CreatedAt) < idempotencyPendingTTL {
+			return nil, ErrIdempotencyPending
+		}
+	}
+	records[key] = IdempotencyRecord{Channel: channel, Pending: true, CreatedAt: s.now()}
+	return nil, writeJSON(s.path, records)
+}
+
+// Release drops a reservation made by Reserve, so the key can be retried.
+// Keys that were already posted are kept.
+func (s *IdempotencyStore) Release(key string) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	records, err := s.load()
+	if err != nil {
+		return err
+	}
+	if rec, ok := records[key]; !ok || !rec.Pending {
+		return nil
+	}
+	delete(records, key)
+	return writeJSON(s.path, records)
+}
+
+// Put records what was posted for key, dropping expired keys.
+func (s *IdempotencyStore) Put(key string, rec IdempotencyRecord) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	records, err := s.load()
+	if err != nil {
+		return err
+	}
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = s.now()
+	}
+	rec.Pending = false
+	records[key] = rec
+	return writeJSON(s.path, records)
+}
+
+// lock takes the store lock, waiting up to idempotencyLockWait for another
+// writer.
+func (s *IdempotencyStore) lock() (func(), error) {
+	unlock, err := waitLock(s.lockPath, idempotencyLockWait, s.now)
+	if errors.Is(err, errLocked) {
+		return nil, fmt.Errorf("idempotency store is locked by another process")
+	}
+	return unlock, err
+}
+
+// load reads the unexpired records.
+func (s *IdempotencyStore) load() (map[string]IdempotencyRecord, error) {
+	records := map[string]IdempotencyRecord{}
+	if err := readJSON(s.path, &records); err != nil {
+		return nil, err
+	}
+	cutoff := s.now().Add(-IdempotencyTTL)
+	for key, rec := range records {
+		if rec.CreatedAt.Before(cutoff) {
+			delete(records, key)
+		}
+	}
+	return records, nil
+}
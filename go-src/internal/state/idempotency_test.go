@@ -0,0 +1,235 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T) *IdempotencyStore {
+	t.Helper()
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	s, err := OpenIdempotencyStore()
+	if err != nil {
+		t.Fatalf("OpenIdempotencyStore: %v", err)
+	}
+	return s
+}
+
+func TestIdempotencyStore_PutGet(t *testing.T) {
+	s := openTestStore(t)
+
+	if err := s.Put("key-1", IdempotencyRecord{Channel: "C001", TS: "1.0", Timestamps: []string{"1.0", "2.0"}}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	rec, err := s.Get("key-1", "C001")
+
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if rec == nil || rec.TS != "1.0" || len(rec.Timestamps) != 2 || rec.CreatedAt.IsZero() {
+		t.Errorf("rec = %+v", rec)
+	}
+}
+
+func TestIdempotencyStore_UnknownKey(t *testing.T) {
+	s := openTestStore(t)
+
+	rec, err := s.Get("missing", "C001")
+
+	if err != nil || rec != nil {
+		t.Errorf("rec = %+v, err = %v", rec, err)
+	}
+}
+
+func TestIdempotencyStore_KeyUsedForOtherChannel(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.Put("key-1", IdempotencyRecord{Channel: "C001", TS: "1.0"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	_, err := s.Get("key-1", "C002")
+
+	if err == nil || !strings.Contains(err.Error(), "already used for channel C001") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestIdempotencyStore_ExpiredKeysDropped(t *testing.T) {
+	s := openTestStore(t)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	if err := s.Put("old", IdempotencyRecord{Channel: "C001", TS: "1.0"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	now = now.Add(IdempotencyTTL + time.Minute)
+	if err := s.Put("new", IdempotencyRecord{Channel: "C001", TS: "2.0"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	rec, err := s.Get("old", "C001")
+
+	if err != nil || rec != nil {
+		t.Errorf("expired key returned: %+v, %v", rec, err)
+	}
+	b, _ := os.ReadFile(s.path)
+	if strings.Contains(string(b), `"old"`) {
+		t.Error("expired key was not pruned from the file")
+	}
+}
+
+func TestIdempotencyStore_Reserve(t *testing.T) {
+	s := openTestStore(t)
+
+	rec, err := s.Reserve("key-1", "C001")
+	if err != nil || rec != nil {
+		t.Fatalf("first Reserve = %+v, %v", rec, err)
+	}
+	if _, err := s.Reserve("key-1", "C001"); !errors.Is(err, ErrIdempotencyPending) {
+		t.Errorf("Reserve while pending err = %v", err)
+	}
+	if err := s.Put("key-1", IdempotencyRecord{Channel: "C001", TS: "1.0"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	rec, err = s.Reserve("key-1", "C001")
+
+	if err != nil || rec == nil || rec.TS != "1.0" || rec.Pending {
+		t.Errorf("Reserve after Put = %+v, %v", rec, err)
+	}
+}
+
+func TestIdempotencyStore_Release(t *testing.T) {
+	s := openTestStore(t)
+	if _, err := s.Reserve("key-1", "C001"); err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+
+	if err := s.Release("key-1"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	rec, err := s.Reserve("key-1", "C001")
+
+	if err != nil || rec != nil {
+		t.Errorf("Reserve after Release = %+v, %v", rec, err)
+	}
+}
+
+func TestIdempotencyStore_ReleaseKeepsPostedKey(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.Put("key-1", IdempotencyRecord{Channel: "C001", TS: "1.0"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if err := s.Release("key-1"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	rec, err := s.Get("key-1", "C001")
+
+	if err != nil || rec == nil || rec.TS != "1.0" {
+		t.Errorf("Get = %+v, %v", rec, err)
+	}
+}
+
+func TestIdempotencyStore_AbandonedReservation(t *testing.T) {
+	s := openTestStore(t)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	if _, err := s.Reserve("key-1", "C001"); err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+
+	now = now.Add(idempotencyPendingTTL + time.Minute)
+	rec, err := s.Reserve("key-1", "C001")
+
+	if err != nil || rec != nil {
+		t.Errorf("Reserve of abandoned key = %+v, %v", rec, err)
+	}
+}
+
+func TestIdempotencyStore_ConcurrentReserve(t *testing.T) {
+	s := openTestStore(t)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reserved := 0
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec, err := s.Reserve("key-1", "C001")
+			switch {
+			case err == nil && rec == nil:
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			case !errors.Is(err, ErrIdempotencyPending):
+				t.Errorf("Reserve = %+v, %v", rec, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reserved != 1 {
+		t.Errorf("key reserved %d times, want once", reserved)
+	}
+}
+
+func TestIdempotencyStore_ConcurrentPuts(t *testing.T) {
+	s := openTestStore(t)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := fmt.Sprintf("key-%d", i)
+			if err := s.Put(key, IdempotencyRecord{Channel: "C001", TS: "1.0"}); err != nil {
+				t.Errorf("Put %s: %v", key, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := range 20 {
+		key := fmt.Sprintf("key-%d", i)
+		if rec, err := s.Get(key, "C001"); err != nil || rec == nil {
+			t.Errorf("%s lost: %+v, %v", key, rec, err)
+		}
+	}
+}
+
+func TestIdempotencyStore_StaleLockIgnored(t *testing.T) {
+	s := openTestStore(t)
+	if _, err := lockFile(s.lockPath, time.Now()); err != nil {
+		t.Fatalf("lockFile: %v", err)
+	}
+
+	s.now = func() time.Time { return time.Now().Add(lockStale + time.Minute) }
+	err := s.Put("key-1", IdempotencyRecord{Channel: "C001", TS: "1.0"})
+
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+}
+
+func TestDir_UsesSlamyHome(t *testing.T) {
+	home := filepath.Join(t.TempDir(), "nested")
+	t.Setenv("SLAMY_HOME", home)
+
+	dir, err := Dir()
+
+	if err != nil {
+		t.Fatalf("Dir: %v", err)
+	}
+	if dir != home {
+		t.Errorf("dir = %q, want %q", dir, home)
+	}
+	if info, err := os.Stat(home); err != nil || !info.IsDir() {
+		t.Error("data directory was not created")
+	}
+}
@@ -0,0 +1,149 @@
+package state
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const outboxDir = "outbox"
+
+// outboxLockFile guards against two processes flushing at once.
+const outboxLockFile = ".lock"
+
+// ErrOutboxLocked is returned by Outbox.Lock while another process holds the lock.
+var ErrOutboxLocked = errors.New("outbox is being flushed by another process")
+
+// OutboxEntry is a queued message waiting to be posted.
+type OutboxEntry struct {
+	ID             string `json:"id"`
+	Channel        string `json:"channel"`
+	Text           string `json:"text"`
+	Format         string `json:"format,omitempty"`
+	Overflow       string `json:"overflow,omitempty"`
+	Markers        bool   `json:"markers,omitempty"`
+	ThreadTS       string `json:"thread_ts,omitempty"`
+	Broadcast      bool   `json:"broadcast,omitempty"`
+	IdempotencyKey string `json:"idempotency_key"`
+	// DedupWindow is how far back delivery looks for an identical message
+	// already posted. It is 0 for messages queued without an idempotency
+	// key, which are always posted.
+	DedupWindow   time.Duration `json:"dedup_window,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Attempts      int           `json:"attempts"`
+	LastAttemptAt *time.Time    `json:"last_attempt_at,omitempty"`
+	NextAttemptAt *time.Time    `json:"next_attempt_at,omitempty"`
+	LastError     string        `json:"last_error,omitempty"`
+	// Posted lists the messages already posted when a split message was
+	// only partly delivered; delivery continues after them.
+	Posted []string `json:"posted,omitempty"`
+	// Dead is set once delivery failed permanently or ran out of attempts.
+	// Dead entries are kept for inspection but no longer delivered.
+	Dead bool `json:"dead,omitempty"`
+}
+
+// Due reports whether the entry may be attempted at now.
+func (e OutboxEntry) Due(now time.Time) bool {
+	return e.NextAttemptAt == nil || !e.NextAttemptAt.After(now)
+}
+
+// Outbox is a directory of queued messages, one JSON file per entry.
+// Entry IDs sort in the order the entries were added.
+type Outbox struct {
+	dir string
+	now func() time.Time
+}
+
+// OpenOutbox opens the outbox in the data directory.
+func OpenOutbox() (*Outbox, error) {
+	dir, err := Path(outboxDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create outbox: %w", err)
+	}
+	return &Outbox{dir: dir, now: time.Now}, nil
+}
+
+// Add queues e, assigning its ID and creation time. Entries without an
+// idempotency key get one derived from the ID, so a delivery that was cut
+// short is not posted twice. The derived key only covers retries of this
+// entry: it does not turn on content dedup, which follows DedupWindow.
+func (o *Outbox) Add(e OutboxEntry) (OutboxEntry, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return e, fmt.Errorf("failed to generate outbox ID: %w", err)
+	}
+	e.CreatedAt = o.now().UTC()
+	e.ID = e.CreatedAt.Format("20060102T150405.000000000Z") + "-" + hex.EncodeToString(suffix)
+	if e.IdempotencyKey == "" {
+		e.IdempotencyKey = "outbox-" + e.ID
+	}
+	if err := o.Update(e); err != nil {
+		return e, err
+	}
+	return e, nil
+}
+
+// List returns the queued entries, oldest first.
+func (o *Outbox) List() ([]OutboxEntry, error) {
+	names, err := filepath.Glob(filepath.Join(o.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox: %w", err)
+	}
+	sort.Strings(names)
+
+	entries := make([]OutboxEntry, 0, len(names))
+	for _, name := range names {
+		var e OutboxEntry
+		if err := readJSON(name, &e); err != nil {
+			return nil, err
+		}
+		if e.ID == "" {
+			// Removed between Glob and read.
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// Update writes e back to the outbox.
+func (o *Outbox) Update(e OutboxEntry) error {
+	return writeJSON(o.entryPath(e.ID), e)
+}
+
+// Remove deletes the entry with the given ID.
+func (o *Outbox) Remove(id string) error {
+	err := os.Remove(o.entryPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("outbox entry %s not found", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove outbox entry %s: %w", id, err)
+	}
+	return nil
+}
+
+// Lock takes the flush lock, returning ErrOutboxLocked if another process
+// holds it.
+func (o *Outbox) Lock() (unlock func(), err error) {
+	unlock, err = lockFile(filepath.Join(o.dir, outboxLockFile), o.now())
+	if errors.Is(err, errLocked) {
+		return nil, ErrOutboxLocked
+	}
+	return unlock, err
+}
+
+// entryPath returns the file for id, refusing IDs that would escape the
+// outbox directory.
+func (o *Outbox) entryPath(id string) string {
+	return filepath.Join(o.dir, strings.NewReplacer("/", "_", `\`, "_", "..", "_").Replace(id)+".json")
+}
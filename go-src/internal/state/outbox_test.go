@@ -0,0 +1,156 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openTestOutbox(t *testing.T) *Outbox {
+	t.Helper()
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	o, err := OpenOutbox()
+	if err != nil {
+		t.Fatalf("OpenOutbox: %v", err)
+	}
+	return o
+}
+
+func TestOutbox_AddList(t *testing.T) {
+	o := openTestOutbox(t)
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, text := range []string{"first", "second", "third"} {
+		o.now = func() time.Time { return base.Add(time.Duration(i) * time.Millisecond) }
+		if _, err := o.Add(OutboxEntry{Channel: "C001", Text: text}); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	entries, err := o.List()
+
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var texts []string
+	for _, e := range entries {
+		texts = append(texts, e.Text)
+	}
+	if strings.Join(texts, ",") != "first,second,third" {
+		t.Errorf("texts = %v", texts)
+	}
+}
+
+func TestOutbox_AddAssignsIdempotencyKey(t *testing.T) {
+	o := openTestOutbox(t)
+
+	e, err := o.Add(OutboxEntry{Channel: "C001", Text: "hi"})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	keyed, err := o.Add(OutboxEntry{Channel: "C001", Text: "hi", IdempotencyKey: "mine"})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	if e.ID == "" || e.IdempotencyKey != "outbox-"+e.ID || e.CreatedAt.IsZero() {
+		t.Errorf("entry = %+v", e)
+	}
+	if keyed.IdempotencyKey != "mine" {
+		t.Errorf("IdempotencyKey = %q", keyed.IdempotencyKey)
+	}
+}
+
+func TestOutbox_UpdateRemove(t *testing.T) {
+	o := openTestOutbox(t)
+	e, err := o.Add(OutboxEntry{Channel: "C001", Text: "hi"})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	e.Attempts = 2
+	e.LastError = "boom"
+	if err := o.Update(e); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	entries, _ := o.List()
+	if len(entries) != 1 || entries[0].Attempts != 2 || entries[0].LastError != "boom" {
+		t.Errorf("entries = %+v", entries)
+	}
+
+	if err := o.Remove(e.ID); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	entries, _ = o.List()
+	if len(entries) != 0 {
+		t.Errorf("entries = %+v", entries)
+	}
+	if err := o.Remove(e.ID); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestOutbox_RemoveStaysInsideDir(t *testing.T) {
+	o := openTestOutbox(t)
+	outside := filepath.Join(filepath.Dir(o.dir), "idempotency.json")
+	if err := os.WriteFile(outside, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = o.Remove("../idempotency")
+
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("file outside the outbox was removed: %v", err)
+	}
+}
+
+func TestOutboxEntry_Due(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Minute)
+
+	if !(OutboxEntry{}).Due(now) {
+		t.Error("entry without NextAttemptAt should be due")
+	}
+	if (OutboxEntry{NextAttemptAt: &later}).Due(now) {
+		t.Error("entry should not be due before NextAttemptAt")
+	}
+	if !(OutboxEntry{NextAttemptAt: &now}).Due(now) {
+		t.Error("entry should be due at NextAttemptAt")
+	}
+}
+
+func TestOutbox_Lock(t *testing.T) {
+	o := openTestOutbox(t)
+
+	unlock, err := o.Lock()
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if _, err := o.Lock(); !errors.Is(err, ErrOutboxLocked) {
+		t.Errorf("second Lock err = %v", err)
+	}
+	unlock()
+
+	unlock, err = o.Lock()
+	if err != nil {
+		t.Fatalf("Lock after unlock: %v", err)
+	}
+	unlock()
+}
+
+func TestOutbox_StaleLockIgnored(t *testing.T) {
+	o := openTestOutbox(t)
+	if _, err := o.Lock(); err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+
+	o.now = func() time.Time { return time.Now().Add(lockStale + time.Minute) }
+	unlock, err := o.Lock()
+
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	unlock()
+}
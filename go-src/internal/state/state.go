@@ -0,0 +1,120 @@
+// Package state manages slamy's local data directory, which holds the
+// idempotency store and other data that must survive between runs.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockStale is the age after which a leftover lock file is assumed to be
+// abandoned by a crashed process.
+const lockStale = 10 * time.Minute
+
+// errLocked is returned by lockFile while another process holds the lock.
+var errLocked = errors.New("locked")
+
+// Dir returns slamy's data directory, creating it if needed. It is
+// $SLAMY_HOME when set and "slamy" under the user config directory otherwise.
+func Dir() (string, error) {
+	dir := os.Getenv("SLAMY_HOME")
+	if dir == "" {
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate config directory: %w", err)
+		}
+		dir = filepath.Join(base, "slamy")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create data directory: %w", err)
+	}
+	return dir, nil
+}
+
+// Path returns the path of name inside the data directory.
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// readJSON decodes the JSON file at path into v. A missing file leaves v
+// unchanged.
+func readJSON(path string, v any) error {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// writeJSON atomically replaces the file at path with the JSON form of v.
+func writeJSON(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// lockFile creates the lock file at path, returning errLocked if it already
+// exists and is younger than lockStale at now.
+func lockFile(path string, now time.Time) (unlock func(), err error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock %s: %w", filepath.Base(path), err)
+		}
+		info, statErr := os.Stat(path)
+		if statErr != nil || now.Sub(info.ModTime()) < lockStale {
+			return nil, errLocked
+		}
+		os.Remove(path)
+	}
+	return nil, errLocked
+}
+
+// waitLock takes the lock file at path like lockFile, retrying for up to
+// wait while another process holds it.
+func waitLock(path string, wait time.Duration, now func() time.Time) (unlock func(), err error) {
+	deadline := now().Add(wait)
+	for {
+		unlock, err := lockFile(path, now())
+		if !errors.Is(err, errLocked) || now().After(deadline) {
+			return unlock, err
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
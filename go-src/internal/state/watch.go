@@ -0,0 +1,118 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	watchFile     = "watch.json"
+	watchLockFile = "watch.lock"
+)
+
+// watchLockWait is how long Put waits for another watch to finish writing.
+const watchLockWait = 5 * time.Second
+
+// WatchThreadTTL is how long a watched thread is followed after its last
+// reply.
+const WatchThreadTTL = 24 * time.Hour
+
+// WatchCheckpoint is how far `slamy watch` has read a channel.
+type WatchCheckpoint struct {
+	// TS is the newest top-level message printed.
+	TS string `json:"ts"`
+	// Threads maps the thread_ts of each followed thread to the newest
+	// reply printed (or the root when none has been).
+	Threads   map[string]string `json:"threads,omitempty"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// WatchCheckpoints stores a WatchCheckpoint per channel, so a restarted
+// watch resumes where the previous one stopped.
+type WatchCheckpoints struct {
+	path     string
+	lockPath string
+	now      func() time.Time
+}
+
+// OpenWatchCheckpoints opens the checkpoints in the data directory.
+func OpenWatchCheckpoints() (*WatchCheckpoints, error) {
+	path, err := Path(watchFile)
+	if err != nil {
+		return nil, err
+	}
+	lockPath, err := Path(watchLockFile)
+	if err != nil {
+		return nil, err
+	}
+	return &WatchCheckpoints{path: path, lockPath: lockPath, now: time.Now}, nil
+}
+
+// Get returns the checkpoint for channel, or nil if it has never been
+// watched.
+func (w *WatchCheckpoints) Get(channel string) (*WatchCheckpoint, error) {
+	checkpoints, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+	cp, ok := checkpoints[channel]
+	if !ok {
+		return nil, nil
+	}
+	return &cp, nil
+}
+
+// Put records the checkpoint for channel, dropping threads whose last reply
+// is older than WatchThreadTTL. Watches of other channels running at the
+// same time keep their checkpoints.
+func (w *WatchCheckpoints) Put(channel string, cp WatchCheckpoint) error {
+	unlock, err := w.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	checkpoints, err := w.load()
+	if err != nil {
+		return err
+	}
+	now := w.now()
+	cutoff := now.Add(-WatchThreadTTL)
+	for thread, ts := range cp.Threads {
+		if tsTime(ts).Before(cutoff) {
+			delete(cp.Threads, thread)
+		}
+	}
+	cp.UpdatedAt = now.UTC()
+	checkpoints[channel] = cp
+	return writeJSON(w.path, checkpoints)
+}
+
+// lock takes the checkpoint lock, waiting up to watchLockWait for another
+// writer.
+func (w *WatchCheckpoints) lock() (func(), error) {
+	unlock, err := waitLock(w.lockPath, watchLockWait, w.now)
+	if errors.Is(err, errLocked) {
+		return nil, fmt.Errorf("watch checkpoints are locked by another process")
+	}
+	return unlock, err
+}
+
+func (w *WatchCheckpoints) load() (map[string]WatchCheckpoint, error) {
+	checkpoints := map[string]WatchCheckpoint{}
+	if err := readJSON(w.path, &checkpoints); err != nil {
+		return nil, err
+	}
+	return checkpoints, nil
+}
+
+// tsTime converts a Slack timestamp to a time, treating an invalid one as
+// the zero time.
+func tsTime(ts string) time.Time {
+	f, err := strconv.ParseFloat(ts, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(int64(f), 0)
+}
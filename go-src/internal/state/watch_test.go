@@ -0,0 +1,68 @@
+package state
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatchCheckpoints_PutGet(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	w, err := OpenWatchCheckpoints()
+	if err != nil {
+		t.Fatalf("OpenWatchCheckpoints: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	w.now = func() time.Time { return now }
+
+	if cp, err := w.Get("C001"); err != nil || cp != nil {
+		t.Fatalf("Get before Put = %+v, %v", cp, err)
+	}
+	recent := fmt.Sprintf("%d.000100", now.Add(-time.Hour).Unix())
+	stale := fmt.Sprintf("%d.000100", now.Add(-WatchThreadTTL-time.Hour).Unix())
+	err = w.Put("C001", WatchCheckpoint{TS: "1700000000.000100", Threads: map[string]string{"1.0": recent, "2.0": stale}})
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	cp, err := w.Get("C001")
+
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if cp == nil || cp.TS != "1700000000.000100" || !cp.UpdatedAt.Equal(now) {
+		t.Fatalf("cp = %+v", cp)
+	}
+	if len(cp.Threads) != 1 || cp.Threads["1.0"] != recent {
+		t.Errorf("Threads = %v", cp.Threads)
+	}
+}
+
+func TestWatchCheckpoints_ConcurrentPuts(t *testing.T) {
+	t.Setenv("SLAMY_HOME", t.TempDir())
+	w, err := OpenWatchCheckpoints()
+	if err != nil {
+		t.Fatalf("OpenWatchCheckpoints: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			channel := fmt.Sprintf("C%03d", i)
+			if err := w.Put(channel, WatchCheckpoint{TS: "1700000000.000100"}); err != nil {
+				t.Errorf("Put %s: %v", channel, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	checkpoints, err := w.load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(checkpoints) != 20 {
+		t.Errorf("kept %d checkpoints, want 20", len(checkpoints))
+	}
+}